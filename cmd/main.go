@@ -1,20 +1,38 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/paulwrubel/moneybags-server/config"
+	"github.com/paulwrubel/moneybags-server/currency"
 	"github.com/paulwrubel/moneybags-server/injection"
+	"github.com/paulwrubel/moneybags-server/migrations"
+	"github.com/paulwrubel/moneybags-server/repositories"
 	"github.com/paulwrubel/moneybags-server/routing"
+	"github.com/paulwrubel/moneybags-server/services"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
 	initLogger()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "exchange-rates" {
+		runExchangeRates(os.Args[2:])
+		return
+	}
+
 	log.Info("starting moneybags server")
 	log.Debugf("number of CPUs: %d", runtime.NumCPU())
 
@@ -27,15 +45,163 @@ func main() {
 	}
 
 	log.Info("starting API server")
-	routing.RunServer(injector)
+	server := routing.NewServer(injector)
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Fatal("error serving API")
+		}
+	}()
+
+	var metricsServer *http.Server
+	if appInfo.MetricsAddress != "" {
+		log.Info("starting metrics server")
+		metricsServer = routing.NewMetricsServer(injector)
+		go func() {
+			err := metricsServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				log.WithError(err).Fatal("error serving metrics")
+			}
+		}()
+	}
+
+	log.Info("starting scheduler")
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+	go func() {
+		injector.InjectScheduler().Run(workerCtx)
+		close(workersDone)
+	}()
 
 	log.Info("blocking until signalled to shutdown")
 	shutdownChan := make(chan os.Signal, 1)
-	signal.Notify(shutdownChan, os.Interrupt)
+	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
 	<-shutdownChan
 
 	log.Info("shutting down")
-	os.Exit(0)
+	shutdown(appInfo, server, metricsServer, stopWorkers, workersDone)
+}
+
+// shutdown fails readiness checks, then waits up to the shutdown timeout for
+// in-flight requests and the background workers to finish before closing
+// the database pool. metricsServer is nil when the metrics listener is off
+func shutdown(appInfo *config.AppInfo, server, metricsServer *http.Server, stopWorkers context.CancelFunc, workersDone <-chan struct{}) {
+	appInfo.Lifecycle.StartDraining()
+	ctx, cancel := context.WithTimeout(context.Background(), appInfo.ShutdownTimeout)
+	defer cancel()
+
+	log.Info("draining API server")
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.WithError(err).Error("error draining API server")
+	}
+
+	if metricsServer != nil {
+		log.Info("stopping metrics server")
+		err = metricsServer.Shutdown(ctx)
+		if err != nil {
+			log.WithError(err).Error("error stopping metrics server")
+		}
+	}
+
+	log.Info("stopping scheduler")
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		log.Error("timed out waiting for scheduler to stop")
+	}
+
+	log.Info("closing database pool")
+	appInfo.DB.Close()
+	log.Info("shut down")
+}
+
+// runMigrate implements the "migrate" subcommand:
+//
+//	moneybags migrate [up]
+//	moneybags migrate down [steps]
+//	moneybags migrate version
+func runMigrate(args []string) {
+	db, err := config.InitializeDB()
+	if err != nil {
+		log.WithError(err).Fatal("error initializing database")
+	}
+	defer db.Close()
+
+	migrator := &migrations.Migrator{
+		DB: db,
+	}
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps: %s", args[1])
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "version":
+		// noop, version is printed below
+	default:
+		log.Fatalf("unknown migrate command: %s", command)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("error migrating database")
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		log.WithError(err).Fatal("error getting database version")
+	}
+	fmt.Printf("database is at version %d\n", version)
+}
+
+// runExchangeRates implements the "exchange-rates" subcommand, which loads
+// exchange rates from a CSV file with the columns date, base, quote and rate:
+//
+//	moneybags exchange-rates <file>
+func runExchangeRates(args []string) {
+	if len(args) != 1 {
+		log.Fatal("usage: moneybags exchange-rates <file>")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		log.WithError(err).Fatal("error opening exchange rates file")
+	}
+	defer file.Close()
+
+	rates, err := currency.ParseRates(file)
+	if err != nil {
+		log.WithError(err).Fatal("error parsing exchange rates")
+	}
+
+	db, err := config.InitializeDB()
+	if err != nil {
+		log.WithError(err).Fatal("error initializing database")
+	}
+	defer db.Close()
+
+	exchangeRates := &services.ExchangeRates{
+		RExchangeRates: &repositories.ExchangeRates{
+			DB: db,
+		},
+	}
+	err = exchangeRates.Import(context.Background(), rates)
+	if err != nil {
+		log.WithError(err).Fatal("error importing exchange rates")
+	}
+	fmt.Printf("imported %d exchange rates\n", len(rates))
 }
 
 func initLogger() {
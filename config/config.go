@@ -8,17 +8,26 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/mailer"
+	"github.com/paulwrubel/moneybags-server/migrations"
 	log "github.com/sirupsen/logrus"
 )
 
 type AppInfo struct {
-	DB       *pgxpool.Pool
-	AuthInfo *AuthInfo
+	DB                *pgxpool.Pool
+	AuthInfo          *AuthInfo
+	Mailer            mailer.IMailer
+	SchedulerInterval time.Duration
+	RequestTimeout    time.Duration
+	ShutdownTimeout   time.Duration
+	MetricsAddress    string
+	Lifecycle         *Lifecycle
 }
 
 type dBInfo struct {
@@ -38,17 +47,57 @@ func InitializeApp() (*AppInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error initializing db connection: %w", err)
 	}
+	err = migrateDB(db)
+	if err != nil {
+		return nil, fmt.Errorf("error migrating db: %w", err)
+	}
 	authInfo, err := getAuthInfo()
 	if err != nil {
 		return nil, fmt.Errorf("error initializing auth info: %w", err)
 	}
+	mailSender, err := getMailer()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing mailer: %w", err)
+	}
+	schedulerInterval, err := getDuration(constants.SchedulerIntervalEnvironmentKey, constants.DefaultSchedulerInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing scheduler interval: %w", err)
+	}
+	requestTimeout, err := getDuration(constants.RequestTimeoutEnvironmentKey, constants.DefaultRequestTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing request timeout: %w", err)
+	}
+	shutdownTimeout, err := getDuration(constants.ShutdownTimeoutEnvironmentKey, constants.DefaultShutdownTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing shutdown timeout: %w", err)
+	}
+	metricsAddress, isSet := os.LookupEnv(constants.MetricsAddressEnvironmentKey)
+	if !isSet {
+		metricsAddress = constants.DefaultMetricsAddress
+	}
 
 	return &AppInfo{
-		DB:       db,
-		AuthInfo: authInfo,
+		DB:                db,
+		AuthInfo:          authInfo,
+		Mailer:            mailSender,
+		SchedulerInterval: schedulerInterval,
+		RequestTimeout:    requestTimeout,
+		ShutdownTimeout:   shutdownTimeout,
+		MetricsAddress:    metricsAddress,
+		Lifecycle:         &Lifecycle{},
 	}, nil
 }
 
+// InitializeDB connects to the database without initializing the rest of the
+// app, for commands which only need the database
+func InitializeDB() (*pgxpool.Pool, error) {
+	db, err := getDB()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing db connection: %w", err)
+	}
+	return db, nil
+}
+
 func getDB() (*pgxpool.Pool, error) {
 	log.Info("getting DB info")
 	pgHost, isSet := os.LookupEnv(constants.PostgresHostnameEnvironmentKey)
@@ -72,6 +121,13 @@ func getDB() (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, err
 	}
+	// have postgres cancel runaway statements itself, so they give back their
+	// connection even when nothing is waiting on them any more
+	queryTimeout, err := getDuration(constants.QueryTimeoutEnvironmentKey, constants.DefaultQueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(queryTimeout.Milliseconds(), 10)
 
 	// initialize connection pool
 	connectionAttempts := 0
@@ -97,6 +153,28 @@ func getDB() (*pgxpool.Pool, error) {
 	return db, nil
 }
 
+func migrateDB(db *pgxpool.Pool) error {
+	autoMigrate := constants.DefaultAutoMigrate
+	autoMigrateString, isSet := os.LookupEnv(constants.AutoMigrateEnvironmentKey)
+	if isSet {
+		var err error
+		autoMigrate, err = strconv.ParseBool(autoMigrateString)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", constants.AutoMigrateEnvironmentKey, err)
+		}
+	}
+	if !autoMigrate {
+		log.Info("skipping database migrations")
+		return nil
+	}
+
+	log.Info("applying database migrations")
+	migrator := &migrations.Migrator{
+		DB: db,
+	}
+	return migrator.Up(context.Background())
+}
+
 func getAuthInfo() (*AuthInfo, error) {
 	log.Info("getting auth info")
 	// parse locations from environment variables
@@ -129,3 +207,48 @@ func getAuthInfo() (*AuthInfo, error) {
 		PrivateKey:    privateKey,
 	}, nil
 }
+
+func getMailer() (mailer.IMailer, error) {
+	log.Info("getting mailer info")
+	mailerType, isSet := os.LookupEnv(constants.MailerEnvironmentKey)
+	if !isSet {
+		mailerType = constants.DefaultMailer
+	}
+
+	switch mailerType {
+	case "log":
+		return &mailer.Log{}, nil
+	case "file":
+		directory, isSet := os.LookupEnv(constants.MailerFileDirectoryEnvironmentKey)
+		if !isSet {
+			directory = constants.DefaultMailerFileDirectory
+		}
+		from, isSet := os.LookupEnv(constants.MailerFromEnvironmentKey)
+		if !isSet {
+			from = constants.DefaultMailerFrom
+		}
+		return &mailer.File{
+			Directory: directory,
+			From:      from,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer: %s", mailerType)
+	}
+}
+
+// getDuration reads a positive duration, such as "90s", from the environment
+func getDuration(environmentKey string, defaultDuration time.Duration) (time.Duration, error) {
+	durationString, isSet := os.LookupEnv(environmentKey)
+	if !isSet {
+		return defaultDuration, nil
+	}
+
+	duration, err := time.ParseDuration(durationString)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", environmentKey, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid value for %s: must be positive", environmentKey)
+	}
+	return duration, nil
+}
@@ -0,0 +1,18 @@
+package config
+
+import "sync/atomic"
+
+// Lifecycle tracks whether the server is draining, in which case it should
+// report itself unready so that no new traffic is sent its way
+type Lifecycle struct {
+	draining int32
+}
+
+// StartDraining marks the server as shutting down. It cannot be undone.
+func (l *Lifecycle) StartDraining() {
+	atomic.StoreInt32(&l.draining, 1)
+}
+
+func (l *Lifecycle) IsDraining() bool {
+	return atomic.LoadInt32(&l.draining) == 1
+}
@@ -1,6 +1,9 @@
 package constants
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 const (
 	PostgresHostnameEnvironmentKey = "MONEYBAGS_PG_HOST"
@@ -8,6 +11,13 @@ const (
 	PostgresPasswordEnvironmentKey = "MONEYBAGS_PG_PASS"
 )
 
+const (
+	// apply pending migrations on startup unless told otherwise
+	DefaultAutoMigrate = true
+
+	AutoMigrateEnvironmentKey = "MONEYBAGS_AUTO_MIGRATE"
+)
+
 const (
 	// sensible default for jwt info
 	DefaultJWTIssuer           = "moneybags"
@@ -18,10 +28,126 @@ const (
 	JWTRSAPrivateKeyFileEnvironmentKey = "MONEYBAGS_JWT_RSA_PRIVATE_KEY_FILE"
 )
 
+const (
+	// sensible defaults for outgoing mail
+	DefaultMailer              = "log"
+	DefaultMailerFileDirectory = "./mail"
+	DefaultMailerFrom          = "moneybags@localhost"
+
+	MailerEnvironmentKey              = "MONEYBAGS_MAILER"
+	MailerFileDirectoryEnvironmentKey = "MONEYBAGS_MAILER_FILE_DIRECTORY"
+	MailerFromEnvironmentKey          = "MONEYBAGS_MAILER_FROM"
+)
+
+const (
+	// how often due scheduled transactions are materialized
+	DefaultSchedulerInterval = 15 * time.Minute
+
+	SchedulerIntervalEnvironmentKey = "MONEYBAGS_SCHEDULER_INTERVAL"
+)
+
+const (
+	// how long a request may take before its queries are cancelled, and how
+	// long postgres lets any one statement run
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultQueryTimeout   = 10 * time.Second
+
+	RequestTimeoutEnvironmentKey = "MONEYBAGS_REQUEST_TIMEOUT"
+	QueryTimeoutEnvironmentKey   = "MONEYBAGS_QUERY_TIMEOUT"
+)
+
+const (
+	// how long in-flight requests and background workers get to finish when
+	// the server is shutting down
+	DefaultShutdownTimeout = 30 * time.Second
+
+	ShutdownTimeoutEnvironmentKey = "MONEYBAGS_SHUTDOWN_TIMEOUT"
+)
+
+// HealthCheckTimeout bounds how long readiness checks wait on the database
+// before reporting it down
+const HealthCheckTimeout = 2 * time.Second
+
+const (
+	// where the metrics listener serves /metrics. It is kept off the public
+	// API's port so that it can be left unexposed; setting it empty turns
+	// the listener off
+	DefaultMetricsAddress = ":9090"
+
+	MetricsAddressEnvironmentKey = "MONEYBAGS_METRICS_ADDRESS"
+)
+
+// MetricsQueryTimeout bounds how long a metrics scrape waits on the database
+// for the counts it reports
+const MetricsQueryTimeout = 2 * time.Second
+
+const (
+	AccessTokenLifetime        = 60 * time.Minute
+	RefreshTokenLifetime       = 30 * 24 * time.Hour
+	PasswordResetTokenLifetime = 60 * time.Minute
+	ChallengeTokenLifetime     = 5 * time.Minute
+)
+
+const (
+	// APIKeyPrefix starts every API key, telling them apart from access
+	// tokens in the Authorization header
+	APIKeyPrefix = "mbk_"
+	// APIKeyDisplayLength is the number of leading characters of an API key
+	// kept to identify it
+	APIKeyDisplayLength = 12
+)
+
+const (
+	// TOTPIssuer is the issuer shown by authenticator apps
+	TOTPIssuer = "moneybags"
+	// TOTPSkew is the number of time steps either side of the current one
+	// in which a code is still accepted
+	TOTPSkew = 1
+	// RecoveryCodeCount is the number of recovery codes issued at a time
+	RecoveryCodeCount = 10
+	// MaxTOTPAttempts is the number of wrong codes in a row after which a
+	// user's second factor is locked for TOTPLockoutDuration
+	MaxTOTPAttempts     = 5
+	TOTPLockoutDuration = 15 * time.Minute
+)
+
+// DateLayout is the layout used for calendar dates in requests and responses
+const DateLayout = "2006-01-02"
+
+// MonthLayout is the layout used for budget months in requests and responses
+const MonthLayout = "2006-01"
+
+const (
+	// DefaultUpcomingDays and MaxUpcomingDays bound how far ahead upcoming
+	// scheduled transactions are listed
+	DefaultUpcomingDays = 30
+	MaxUpcomingDays     = 366
+)
+
+const (
+	// DefaultPageLimit and MaxPageLimit bound the number of items in a page
+	// of a list
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// MaxTransactionAttempts is the number of times a unit of work is tried
+// before a serialization failure is given up on
+const MaxTransactionAttempts = 3
+
+// DefaultCurrency is the currency of budgets created without one
+const DefaultCurrency = "USD"
+
+// ReconciliationAdjustmentPayee is the payee of the transaction created to
+// make up a difference when reconciling a bank account
+const ReconciliationAdjustmentPayee = "Reconciliation Balance Adjustment"
+
 type ContextKey string
 
 const (
-	UsernameContextKey ContextKey = "username"
+	UsernameContextKey  ContextKey = "username"
+	SessionIDContextKey ContextKey = "session_id"
+	APIKeyIDContextKey  ContextKey = "api_key_id"
 )
 
 var (
@@ -30,4 +156,61 @@ var (
 	ErrUserDoesNotExist = errors.New("user does not exist")
 	ErrUserExists       = errors.New("user already exists")
 	ErrInvalidEmail     = errors.New("invalid email")
+
+	ErrInvalidSession      = errors.New("invalid session")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrInvalidAPIKeyName   = errors.New("api key name must not be empty")
+	ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+
+	ErrIncorrectPassword = errors.New("incorrect password")
+	ErrInvalidResetToken = errors.New("invalid password reset token")
+
+	ErrTOTPAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled       = errors.New("two-factor authentication has not been enrolled")
+	ErrTOTPNotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTOTPCode       = errors.New("invalid two-factor authentication code")
+	ErrTOTPLocked            = errors.New("too many invalid two-factor authentication codes, try again later")
+	ErrInvalidChallengeToken = errors.New("invalid challenge token")
+
+	ErrInvalidPageLimit = errors.New("invalid page limit")
+	ErrInvalidSortKey   = errors.New("invalid sort key")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+
+	ErrInvalidAmount      = errors.New("invalid amount")
+	ErrInvalidDateRange   = errors.New("from date must not be after to date")
+	ErrInvalidAmountRange = errors.New("minimum amount must not exceed maximum amount")
+
+	ErrInvalidDate         = errors.New("invalid date")
+	ErrInvalidMonth        = errors.New("invalid month")
+	ErrInvalidClearedState = errors.New("invalid cleared state")
+	ErrInvalidSplits       = errors.New("split amounts must sum to the transaction amount")
+
+	ErrTransactionReconciled    = errors.New("transaction is reconciled")
+	ErrReconciliationUnbalanced = errors.New("cleared balance does not match statement balance")
+	ErrNoStatementBalance       = errors.New("no statement balance")
+
+	ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+
+	ErrInvalidTransferAmount       = errors.New("transfer amount must be positive")
+	ErrInvalidTransferBankAccounts = errors.New("transfer bank accounts must be two different accounts in the same budget")
+	ErrInvalidTransferCurrencies   = errors.New("transfer bank accounts must hold the same currency")
+
+	ErrInvalidPayeeName          = errors.New("payee name must not be empty")
+	ErrInvalidPayeeMerge         = errors.New("a payee cannot be merged into itself")
+	ErrInvalidCategorizationRule = errors.New("categorization rule must have a condition, and its minimum amount must not exceed its maximum")
+
+	ErrInvalidCurrency = errors.New("invalid currency")
+	ErrNoExchangeRate  = errors.New("no exchange rate between the currencies")
+
+	ErrInvalidBankAccountType = errors.New("invalid bank account type")
+	ErrBudgetHasBankAccounts  = errors.New("budget has bank accounts")
+	ErrBudgetCurrencyInUse    = errors.New("budget currency cannot change once the budget has bank accounts or allocations")
+
+	ErrNotBudgetMember        = errors.New("user is not a member of the budget")
+	ErrInsufficientBudgetRole = errors.New("user's role on the budget does not allow this")
+	ErrInvalidBudgetRole      = errors.New("invalid budget role")
+	ErrBudgetNeedsOwner       = errors.New("budget must keep at least one owner")
+	ErrAlreadyBudgetMember    = errors.New("user is already a member of the budget")
+	ErrBudgetInvitationExists = errors.New("user has already been invited to the budget")
 )
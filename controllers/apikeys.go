@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type APIKeys struct {
+	SAPIKeys      services.IAPIKeys
+	SBudgets      services.IBudgets
+	SUserAccounts services.IUserAccounts
+}
+
+type apiKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	KeyPrefix string     `json:"key_prefix"`
+	ReadOnly  bool       `json:"read_only"`
+	BudgetID  *string    `json:"budget_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type getAllAPIKeysResponse struct {
+	APIKeys []apiKeyResponse `json:"api_keys"`
+}
+
+func (ak *APIKeys) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ak.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		apiKeys, err := ak.SAPIKeys.GetAll(r.Context(), userAccount.ID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all api keys")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getAllAPIKeysResponse{
+			APIKeys: []apiKeyResponse{},
+		}
+		for _, apiKey := range apiKeys {
+			response.APIKeys = append(response.APIKeys, newAPIKeyResponse(apiKey))
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+type postAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	ReadOnly  bool       `json:"read_only"`
+	BudgetID  *string    `json:"budget_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// postAPIKeyResponse is the only response which includes the key itself
+type postAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+func (ak *APIKeys) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ak.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		var requestBody postAPIKeyRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		// a key can only be scoped to a budget its user can already see
+		if requestBody.BudgetID != nil && !validateBudget(r.Context(), rw, ak.SBudgets, userAccount.ID, *requestBody.BudgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		createdAPIKey, key, err := ak.SAPIKeys.Create(r.Context(), userAccount.ID, requestBody.Name, requestBody.ReadOnly, requestBody.BudgetID, requestBody.ExpiresAt)
+		switch err {
+		case constants.ErrInvalidAPIKeyName, constants.ErrInvalidAPIKeyExpiry:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating api key")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, postAPIKeyResponse{
+			apiKeyResponse: newAPIKeyResponse(createdAPIKey),
+			Key:            key,
+		})
+	}
+}
+
+// Delete revokes the API key. It is kept, marked as revoked, so that it still
+// shows up when listing keys.
+func (ak *APIKeys) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ak.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		apiKeyID := mux.Vars(r)["apiKeyID"]
+
+		if !validateAPIKey(r.Context(), rw, ak.SAPIKeys, userAccount.ID, apiKeyID) {
+			return
+		}
+
+		err := ak.SAPIKeys.Revoke(r.Context(), apiKeyID)
+		if err != nil {
+			log.WithError(err).Error("Error revoking api key")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newAPIKeyResponse(apiKey *models.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		KeyPrefix: apiKey.KeyPrefix,
+		ReadOnly:  apiKey.ReadOnly,
+		BudgetID:  apiKey.BudgetID,
+		CreatedAt: apiKey.CreatedAt,
+		ExpiresAt: apiKey.ExpiresAt,
+		RevokedAt: apiKey.RevokedAt,
+	}
+}
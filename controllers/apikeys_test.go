@@ -0,0 +1,213 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeysPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mak *mockservices.MockIAPIKeys, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/user-accounts/api-keys",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mak *mockservices.MockIAPIKeys, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				budgetID := "__bid_1__"
+				mak.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("reports"), gomock.Eq(true), gomock.Eq(&budgetID), gomock.Nil()).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.APIKey{
+						ID:            "__akid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "reports",
+						KeyHash:       "__hash__",
+						KeyPrefix:     "mbk_abcdefgh",
+						ReadOnly:      true,
+						BudgetID:      &budgetID,
+						CreatedAt:     time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC),
+					}, "mbk_abcdefghijklmnop", nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": "reports",
+				"read_only": true,
+				"budget_id": "__bid_1__"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__akid_1__",
+				"name": "reports",
+				"key_prefix": "mbk_abcdefgh",
+				"read_only": true,
+				"budget_id": "__bid_1__",
+				"created_at": "2022-03-01T12:00:00Z",
+				"key": "mbk_abcdefghijklmnop"
+			}`,
+		},
+		{
+			name:     "post - failure - empty name",
+			endpoint: "/api/v1/user-accounts/api-keys",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mak *mockservices.MockIAPIKeys, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				mak.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq(" "), gomock.Eq(false), gomock.Nil(), gomock.Nil()).
+					After(getUserCall).
+					Times(1).
+					Return(nil, "", constants.ErrInvalidAPIKeyName)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": " "
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "api key name must not be empty"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - authenticated with api key",
+			endpoint: "/api/v1/user-accounts/api-keys",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.APIKeyIDContextKey, "__akid_1__"))
+				return r
+			},
+			mockSetupFunc: func(mak *mockservices.MockIAPIKeys, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				mak.EXPECT().
+					Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": "more keys"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPIKeysService := mockservices.NewMockIAPIKeys(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockAPIKeysService, mockBudgetsService, mockUserAccountsService)
+
+			ak := &controllers.APIKeys{
+				SAPIKeys:      mockAPIKeysService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			ak.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
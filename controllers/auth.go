@@ -4,12 +4,14 @@ import (
 	"net/http"
 
 	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/metrics"
 	"github.com/paulwrubel/moneybags-server/services"
 	log "github.com/sirupsen/logrus"
 )
 
 type Auth struct {
 	Service services.IAuth
+	STOTP   services.ITOTP
 }
 
 type postLoginRequest struct {
@@ -18,7 +20,13 @@ type postLoginRequest struct {
 }
 
 type postLoginResponse struct {
-	Token string `json:"access_token"`
+	Token        string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type postLoginChallengeResponse struct {
+	SecondFactorRequired bool   `json:"second_factor_required"`
+	ChallengeToken       string `json:"challenge_token"`
 }
 
 func (a *Auth) PostToken() http.HandlerFunc {
@@ -30,9 +38,10 @@ func (a *Auth) PostToken() http.HandlerFunc {
 			return
 		}
 
-		authenticated, err := a.Service.Authenticate(requestBody.Username, requestBody.Password)
+		authenticated, err := a.Service.Authenticate(r.Context(), requestBody.Username, requestBody.Password)
 		switch err {
 		case constants.ErrUserDoesNotExist:
+			metrics.RecordAuthAttempt(metrics.AuthMethodPassword, false)
 			writeResponse(rw, http.StatusUnauthorized, errorsResponseFromErrors(err))
 			return
 		case nil:
@@ -42,20 +51,200 @@ func (a *Auth) PostToken() http.HandlerFunc {
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
 			return
 		}
+		metrics.RecordAuthAttempt(metrics.AuthMethodPassword, authenticated)
 		if !authenticated {
 			writeResponse(rw, http.StatusUnauthorized, errorsResponseFromMessages("Invalid username or password"))
 			return
 		}
 
-		tokenString, err := a.Service.CreateAuthToken(requestBody.Username)
+		totpEnabled, err := a.STOTP.IsEnabled(r.Context(), requestBody.Username)
 		if err != nil {
-			log.WithError(err).Error("Error creating auth token")
+			log.WithError(err).Error("Error checking if two-factor authentication is enabled")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+		if totpEnabled {
+			challengeToken, err := a.Service.CreateChallengeToken(r.Context(), requestBody.Username)
+			if err != nil {
+				log.WithError(err).Error("Error creating challenge token")
+				writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+				return
+			}
+
+			writeResponse(rw, http.StatusOK, postLoginChallengeResponse{
+				SecondFactorRequired: true,
+				ChallengeToken:       challengeToken,
+			})
+			return
+		}
+
+		tokenString, refreshToken, err := a.Service.CreateSession(r.Context(), requestBody.Username)
+		if err != nil {
+			log.WithError(err).Error("Error creating session")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
 			return
 		}
 
 		writeResponse(rw, http.StatusOK, postLoginResponse{
-			Token: tokenString,
+			Token:        tokenString,
+			RefreshToken: refreshToken,
+		})
+	}
+}
+
+type postTokenChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type postTokenChallengeResponse struct {
+	Token        string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// PostTokenChallenge completes a login for a user with two-factor
+// authentication enabled, exchanging the challenge token from PostToken and
+// a second factor code for a session
+func (a *Auth) PostTokenChallenge() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var requestBody postTokenChallengeRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		username, err := a.Service.ValidateChallengeToken(r.Context(), requestBody.ChallengeToken)
+		switch err {
+		case constants.ErrInvalidChallengeToken:
+			metrics.RecordAuthAttempt(metrics.AuthMethodTOTP, false)
+			writeResponse(rw, http.StatusUnauthorized, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error validating challenge token")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		err = a.STOTP.ValidateCode(r.Context(), username, requestBody.Code)
+		switch err {
+		case constants.ErrInvalidTOTPCode:
+			fallthrough
+		case constants.ErrTOTPNotEnabled:
+			metrics.RecordAuthAttempt(metrics.AuthMethodTOTP, false)
+			writeResponse(rw, http.StatusUnauthorized, errorsResponseFromErrors(err))
+			return
+		case constants.ErrTOTPLocked:
+			metrics.RecordAuthAttempt(metrics.AuthMethodTOTP, false)
+			writeResponse(rw, http.StatusTooManyRequests, errorsResponseFromErrors(err))
+			return
+		case nil:
+			metrics.RecordAuthAttempt(metrics.AuthMethodTOTP, true)
+		default:
+			log.WithError(err).Error("Error validating two-factor authentication code")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		tokenString, refreshToken, err := a.Service.CreateSession(r.Context(), username)
+		if err != nil {
+			log.WithError(err).Error("Error creating session")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, postTokenChallengeResponse{
+			Token:        tokenString,
+			RefreshToken: refreshToken,
+		})
+	}
+}
+
+type postRefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type postRefreshResponse struct {
+	Token        string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (a *Auth) PostRefresh() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var requestBody postRefreshRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		tokenString, refreshToken, err := a.Service.RefreshSession(r.Context(), requestBody.RefreshToken)
+		switch err {
+		case constants.ErrInvalidRefreshToken:
+			metrics.RecordAuthAttempt(metrics.AuthMethodRefresh, false)
+			writeResponse(rw, http.StatusUnauthorized, errorsResponseFromErrors(err))
+			return
+		case nil:
+			metrics.RecordAuthAttempt(metrics.AuthMethodRefresh, true)
+		default:
+			log.WithError(err).Error("Error refreshing session")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, postRefreshResponse{
+			Token:        tokenString,
+			RefreshToken: refreshToken,
 		})
 	}
 }
+
+func (a *Auth) PostLogout() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		sessionID, ok := r.Context().Value(constants.SessionIDContextKey).(string)
+		if !ok {
+			log.Error("Could not retrieve session ID from context")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromMessages("Session not found in provided token. Please contact the site administrator"))
+			return
+		}
+
+		err := a.Service.RevokeSession(r.Context(), sessionID)
+		if err != nil {
+			log.WithError(err).Error("Error revoking session")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *Auth) PostLogoutAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		username, ok := r.Context().Value(constants.UsernameContextKey).(string)
+		if !ok {
+			log.Error("Could not retrieve username from context")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromMessages("User not found in provided token. Please contact the site administrator"))
+			return
+		}
+
+		err := a.Service.RevokeAllSessions(r.Context(), username)
+		if err != nil {
+			log.WithError(err).Error("Error revoking all sessions")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1,6 +1,7 @@
 package controllers_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -21,7 +22,7 @@ func TestAuthLogin(t *testing.T) {
 		name                 string
 		endpoint             string
 		requestSetupFunc     func(r *http.Request) *http.Request
-		mockSetupFunc        func(ma *mockservices.MockIAuth)
+		mockSetupFunc        func(ma *mockservices.MockIAuth, mt *mockservices.MockITOTP)
 		requestMethod        string
 		requestBody          string
 		expectedStatusCode   int
@@ -33,17 +34,23 @@ func TestAuthLogin(t *testing.T) {
 			requestSetupFunc: func(r *http.Request) *http.Request {
 				return r
 			},
-			mockSetupFunc: func(m *mockservices.MockIAuth) {
+			mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
 				authCall := m.EXPECT().
-					Authenticate(gomock.Eq("user_1"), gomock.Eq("pass_1")).
+					Authenticate(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("pass_1")).
 					Times(1).
 					Return(true, nil)
 
-				m.EXPECT().
-					CreateAuthToken(gomock.Eq("user_1")).
+				totpCall := mt.EXPECT().
+					IsEnabled(gomock.Any(), gomock.Eq("user_1")).
 					After(authCall).
 					Times(1).
-					Return("__token_1__", nil)
+					Return(false, nil)
+
+				m.EXPECT().
+					CreateSession(gomock.Any(), gomock.Eq("user_1")).
+					After(totpCall).
+					Times(1).
+					Return("__token_1__", "__refresh_token_1__", nil)
 			},
 			requestMethod: http.MethodPost,
 			requestBody: `{	
@@ -52,19 +59,59 @@ func TestAuthLogin(t *testing.T) {
 			}`,
 			expectedStatusCode: http.StatusOK,
 			expectedResponseBody: `{
-				"access_token": "__token_1__"
+				"access_token": "__token_1__",
+				"refresh_token": "__refresh_token_1__"
+			}`,
+		},
+		{
+			name:     "post - success - second factor required",
+			endpoint: "/api/v1/auth/token",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
+				authCall := m.EXPECT().
+					Authenticate(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("pass_1")).
+					Times(1).
+					Return(true, nil)
+
+				totpCall := mt.EXPECT().
+					IsEnabled(gomock.Any(), gomock.Eq("user_1")).
+					After(authCall).
+					Times(1).
+					Return(true, nil)
+
+				m.EXPECT().
+					CreateChallengeToken(gomock.Any(), gomock.Eq("user_1")).
+					After(totpCall).
+					Times(1).
+					Return("__challenge_token_1__", nil)
+
+				m.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"username": "user_1",
+				"password": "pass_1"
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"second_factor_required": true,
+				"challenge_token": "__challenge_token_1__"
 			}`,
 		},
 		{
 			name:     "post - unauthorized - bad password",
-			endpoint: "/api/v1/auth/token", mockSetupFunc: func(m *mockservices.MockIAuth) {
+			endpoint: "/api/v1/auth/token", mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
 				m.EXPECT().
-					Authenticate(gomock.Eq("user_1"), gomock.Eq("bad_pass_1")).
+					Authenticate(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("bad_pass_1")).
 					Times(1).
 					Return(false, nil)
 
 				m.EXPECT().
-					CreateAuthToken(gomock.Any()).
+					CreateSession(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			requestMethod: http.MethodPost,
@@ -81,14 +128,14 @@ func TestAuthLogin(t *testing.T) {
 		},
 		{
 			name:     "post - unauthorized - bad username",
-			endpoint: "/api/v1/auth/login", mockSetupFunc: func(m *mockservices.MockIAuth) {
+			endpoint: "/api/v1/auth/login", mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
 				m.EXPECT().
-					Authenticate(gomock.Eq("bad_user_1"), gomock.Eq("pass_1")).
+					Authenticate(gomock.Any(), gomock.Eq("bad_user_1"), gomock.Eq("pass_1")).
 					Times(1).
 					Return(false, constants.ErrUserDoesNotExist)
 
 				m.EXPECT().
-					CreateAuthToken(gomock.Any()).
+					CreateSession(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			requestMethod: http.MethodPost,
@@ -105,14 +152,14 @@ func TestAuthLogin(t *testing.T) {
 		},
 		{
 			name:     "post - failure - server error",
-			endpoint: "/api/v1/auth/login", mockSetupFunc: func(m *mockservices.MockIAuth) {
+			endpoint: "/api/v1/auth/login", mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
 				m.EXPECT().
-					Authenticate(gomock.Eq("user_1"), gomock.Eq("pass_1")).
+					Authenticate(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("pass_1")).
 					Times(1).
 					Return(false, errors.New("some internal problem occured"))
 
 				m.EXPECT().
-					CreateAuthToken(gomock.Eq("user_1")).
+					CreateSession(gomock.Any(), gomock.Eq("user_1")).
 					Times(0)
 			},
 			requestMethod: http.MethodPost,
@@ -128,6 +175,85 @@ func TestAuthLogin(t *testing.T) {
 			}`,
 		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAuthService := mockservices.NewMockIAuth(mockCtrl)
+			mockTOTPService := mockservices.NewMockITOTP(mockCtrl)
+
+			tt.mockSetupFunc(mockAuthService, mockTOTPService)
+
+			a := &controllers.Auth{
+				Service: mockAuthService,
+				STOTP:   mockTOTPService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+
+			a.PostToken().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestAuthRefresh(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		mockSetupFunc        func(ma *mockservices.MockIAuth)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/auth/refresh",
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RefreshSession(gomock.Any(), gomock.Eq("__refresh_token_1__")).
+					Times(1).
+					Return("__token_2__", "__refresh_token_2__", nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"refresh_token": "__refresh_token_1__"
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"access_token": "__token_2__",
+				"refresh_token": "__refresh_token_2__"
+			}`,
+		},
+		{
+			name:     "post - unauthorized - invalid refresh token",
+			endpoint: "/api/v1/auth/refresh",
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RefreshSession(gomock.Any(), gomock.Eq("__refresh_token_1__")).
+					Times(1).
+					Return("", "", constants.ErrInvalidRefreshToken)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"refresh_token": "__refresh_token_1__"
+			}`,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid refresh token"
+				}]
+			}`,
+		},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAuthService := mockservices.NewMockIAuth(gomock.NewController(t))
@@ -141,7 +267,372 @@ func TestAuthLogin(t *testing.T) {
 			rw := httptest.NewRecorder()
 			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
 
-			a.PostToken().ServeHTTP(rw, r)
+			a.PostRefresh().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestAuthTokenChallenge(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		mockSetupFunc        func(ma *mockservices.MockIAuth, mt *mockservices.MockITOTP)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/auth/token/challenge",
+			mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
+				challengeCall := m.EXPECT().
+					ValidateChallengeToken(gomock.Any(), gomock.Eq("__challenge_token_1__")).
+					Times(1).
+					Return("user_1", nil)
+
+				codeCall := mt.EXPECT().
+					ValidateCode(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("123456")).
+					After(challengeCall).
+					Times(1).
+					Return(nil)
+
+				m.EXPECT().
+					CreateSession(gomock.Any(), gomock.Eq("user_1")).
+					After(codeCall).
+					Times(1).
+					Return("__token_1__", "__refresh_token_1__", nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"challenge_token": "__challenge_token_1__",
+				"code": "123456"
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"access_token": "__token_1__",
+				"refresh_token": "__refresh_token_1__"
+			}`,
+		},
+		{
+			name:     "post - unauthorized - bad code",
+			endpoint: "/api/v1/auth/token/challenge",
+			mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
+				challengeCall := m.EXPECT().
+					ValidateChallengeToken(gomock.Any(), gomock.Eq("__challenge_token_1__")).
+					Times(1).
+					Return("user_1", nil)
+
+				mt.EXPECT().
+					ValidateCode(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("000000")).
+					After(challengeCall).
+					Times(1).
+					Return(constants.ErrInvalidTOTPCode)
+
+				m.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"challenge_token": "__challenge_token_1__",
+				"code": "000000"
+			}`,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid two-factor authentication code"
+				}]
+			}`,
+		},
+		{
+			name:     "post - too many requests - locked",
+			endpoint: "/api/v1/auth/token/challenge",
+			mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
+				challengeCall := m.EXPECT().
+					ValidateChallengeToken(gomock.Any(), gomock.Eq("__challenge_token_1__")).
+					Times(1).
+					Return("user_1", nil)
+
+				mt.EXPECT().
+					ValidateCode(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("123456")).
+					After(challengeCall).
+					Times(1).
+					Return(constants.ErrTOTPLocked)
+
+				m.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"challenge_token": "__challenge_token_1__",
+				"code": "123456"
+			}`,
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "too many invalid two-factor authentication codes, try again later"
+				}]
+			}`,
+		},
+		{
+			name:     "post - unauthorized - bad challenge token",
+			endpoint: "/api/v1/auth/token/challenge",
+			mockSetupFunc: func(m *mockservices.MockIAuth, mt *mockservices.MockITOTP) {
+				m.EXPECT().
+					ValidateChallengeToken(gomock.Any(), gomock.Eq("__bad_challenge_token__")).
+					Times(1).
+					Return("", constants.ErrInvalidChallengeToken)
+
+				mt.EXPECT().
+					ValidateCode(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"challenge_token": "__bad_challenge_token__",
+				"code": "123456"
+			}`,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid challenge token"
+				}]
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAuthService := mockservices.NewMockIAuth(mockCtrl)
+			mockTOTPService := mockservices.NewMockITOTP(mockCtrl)
+
+			tt.mockSetupFunc(mockAuthService, mockTOTPService)
+
+			a := &controllers.Auth{
+				Service: mockAuthService,
+				STOTP:   mockTOTPService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+
+			a.PostTokenChallenge().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestAuthLogout(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(ma *mockservices.MockIAuth)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/auth/logout",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.SessionIDContextKey, "__sid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Eq("__sid_1__")).
+					Times(1).
+					Return(nil)
+			},
+			requestMethod:        http.MethodPost,
+			requestBody:          ``,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: ``,
+		},
+		{
+			name:     "post - failure - authenticated with api key",
+			endpoint: "/api/v1/auth/logout",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.APIKeyIDContextKey, "__akid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodPost,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - server error",
+			endpoint: "/api/v1/auth/logout",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.SessionIDContextKey, "__sid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Eq("__sid_1__")).
+					Times(1).
+					Return(errors.New("some error"))
+			},
+			requestMethod:      http.MethodPost,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "some error"
+				}]
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthService := mockservices.NewMockIAuth(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockAuthService)
+
+			a := &controllers.Auth{
+				Service: mockAuthService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			a.PostLogout().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestAuthLogoutAll(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(ma *mockservices.MockIAuth)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/auth/logout-all",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.SessionIDContextKey, "__sid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RevokeAllSessions(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(nil)
+			},
+			requestMethod:        http.MethodPost,
+			requestBody:          ``,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: ``,
+		},
+		{
+			name:     "post - failure - authenticated with api key",
+			endpoint: "/api/v1/auth/logout-all",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.APIKeyIDContextKey, "__akid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RevokeAllSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodPost,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - server error",
+			endpoint: "/api/v1/auth/logout-all",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.SessionIDContextKey, "__sid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIAuth) {
+				m.EXPECT().
+					RevokeAllSessions(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(errors.New("some error"))
+			},
+			requestMethod:      http.MethodPost,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "some error"
+				}]
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthService := mockservices.NewMockIAuth(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockAuthService)
+
+			a := &controllers.Auth{
+				Service: mockAuthService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			a.PostLogoutAll().ServeHTTP(rw, r)
 
 			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
 			resBody, _ := io.ReadAll(rw.Result().Body)
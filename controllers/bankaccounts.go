@@ -4,6 +4,10 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/currency"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/paulwrubel/moneybags-server/services"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,11 +20,15 @@ type BankAccounts struct {
 
 type getAllBankAccountsResponse struct {
 	BankAccounts []getAllBankAccountsResponseBankAccount `json:"bank_accounts"`
+	NextCursor   *string                                 `json:"next_cursor,omitempty"`
 }
 
 type getAllBankAccountsResponseBankAccount struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Closed   bool   `json:"closed"`
 }
 
 func (ba *BankAccounts) GetAll() http.HandlerFunc {
@@ -32,45 +40,330 @@ func (ba *BankAccounts) GetAll() http.HandlerFunc {
 
 		budgetID := mux.Vars(r)["budgetID"]
 
-		exists, err := ba.SBudgets.ExistsByID(budgetID)
+		if !validateBudget(r.Context(), rw, ba.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		page, ok := validatePage(rw, r, []pagination.SortKey{{Name: "name", Type: pagination.SortText}}, "name")
+		if !ok {
+			return
+		}
+
+		bankAccounts, next, err := ba.SBankAccounts.GetPage(r.Context(), budgetID, page)
 		if err != nil {
-			log.WithError(err).Error("Error checking if budget exists")
+			log.WithError(err).Error("Error getting all accounts")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
 			return
 		}
-		if !exists {
-			writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Budget does not exist"))
+
+		response := getAllBankAccountsResponse{
+			BankAccounts: []getAllBankAccountsResponseBankAccount{},
+			NextCursor:   encodeCursor(next),
+		}
+		for _, account := range bankAccounts {
+			response.BankAccounts = append(response.BankAccounts, getAllBankAccountsResponseBankAccount{
+				ID:       account.ID,
+				Name:     account.Name,
+				Type:     string(account.Type),
+				Currency: account.Currency,
+				Closed:   account.Closed,
+			})
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+type getBankAccountResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Closed   bool   `json:"closed"`
+}
+
+func (ba *BankAccounts) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ba.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, ba.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, ba.SBankAccounts, budgetID, bankAccountID) {
 			return
 		}
 
-		belongsToRequestor, err := ba.SBudgets.BelongsTo(userAccount.ID, budgetID)
+		bankAccount, err := ba.SBankAccounts.GetByID(r.Context(), bankAccountID)
 		if err != nil {
-			log.WithError(err).Error("Error checking if budget belongs to user")
+			log.WithError(err).Error("Error getting bank account")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
 			return
 		}
-		if !belongsToRequestor {
-			writeResponse(rw, http.StatusForbidden, errorsResponseFromMessages("Budget does not belong to user"))
+
+		writeResponse(rw, http.StatusOK, getBankAccountResponse{
+			ID:       bankAccount.ID,
+			Name:     bankAccount.Name,
+			Type:     string(bankAccount.Type),
+			Currency: bankAccount.Currency,
+			Closed:   bankAccount.Closed,
+		})
+	}
+}
+
+// postBankAccountRequest creates a bank account. Currency defaults to the
+// budget's currency when left out.
+type postBankAccountRequest struct {
+	Name     string  `json:"name"`
+	Type     *string `json:"type"`
+	Currency *string `json:"currency"`
+}
+
+type postBankAccountResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Closed   bool   `json:"closed"`
+}
+
+func (ba *BankAccounts) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ba.SUserAccounts)
+		if !ok {
 			return
 		}
 
-		bankAccounts, err := ba.SBankAccounts.GetAll(budgetID)
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, ba.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+
+		var requestBody postBankAccountRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
 		if err != nil {
-			log.WithError(err).Error("Error getting all accounts")
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		exists, err := ba.SBankAccounts.ExistsByBudgetIDAndName(r.Context(), budgetID, requestBody.Name)
+		if err != nil {
+			log.WithError(err).Error("Error checking if bank account exists")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
 			return
 		}
+		if exists {
+			writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Bank account already exists"))
+			return
+		}
 
-		response := getAllBankAccountsResponse{
-			BankAccounts: []getAllBankAccountsResponseBankAccount{},
+		accountType := models.BankAccountTypeChecking
+		if requestBody.Type != nil {
+			accountType = models.BankAccountType(*requestBody.Type)
 		}
-		for _, account := range bankAccounts {
-			response.BankAccounts = append(response.BankAccounts, getAllBankAccountsResponseBankAccount{
-				ID:   account.ID,
-				Name: account.Name,
-			})
+
+		var currencyCode string
+		if requestBody.Currency != nil {
+			currencyCode = currency.Normalize(*requestBody.Currency)
+		} else {
+			budget, err := ba.SBudgets.GetByID(r.Context(), budgetID)
+			if err != nil {
+				log.WithError(err).Error("Error getting budget")
+				writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+				return
+			}
+			currencyCode = budget.Currency
 		}
 
-		writeResponse(rw, http.StatusOK, response)
+		createdBankAccount, err := ba.SBankAccounts.Create(r.Context(), budgetID, requestBody.Name, accountType, currencyCode)
+		switch err {
+		case constants.ErrInvalidBankAccountType, constants.ErrInvalidCurrency:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating bank account")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, postBankAccountResponse{
+			ID:       createdBankAccount.ID,
+			Name:     createdBankAccount.Name,
+			Type:     string(createdBankAccount.Type),
+			Currency: createdBankAccount.Currency,
+			Closed:   createdBankAccount.Closed,
+		})
+	}
+}
+
+type patchBankAccountRequest struct {
+	Name   *string `json:"name"`
+	Type   *string `json:"type"`
+	Closed *bool   `json:"closed"`
+}
+
+type patchBankAccountResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Closed   bool   `json:"closed"`
+}
+
+func (ba *BankAccounts) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ba.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, ba.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, ba.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody patchBankAccountRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		bankAccount, err := ba.SBankAccounts.GetByID(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		if requestBody.Name != nil && *requestBody.Name != bankAccount.Name {
+			exists, err := ba.SBankAccounts.ExistsByBudgetIDAndName(r.Context(), budgetID, *requestBody.Name)
+			if err != nil {
+				log.WithError(err).Error("Error checking if bank account exists")
+				writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+				return
+			}
+			if exists {
+				writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Bank account already exists"))
+				return
+			}
+			bankAccount.Name = *requestBody.Name
+		}
+		if requestBody.Type != nil {
+			bankAccount.Type = models.BankAccountType(*requestBody.Type)
+		}
+		if requestBody.Closed != nil {
+			bankAccount.Closed = *requestBody.Closed
+		}
+
+		updatedBankAccount, err := ba.SBankAccounts.Update(r.Context(), bankAccount)
+		switch err {
+		case constants.ErrInvalidBankAccountType:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating bank account")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, patchBankAccountResponse{
+			ID:       updatedBankAccount.ID,
+			Name:     updatedBankAccount.Name,
+			Type:     string(updatedBankAccount.Type),
+			Currency: updatedBankAccount.Currency,
+			Closed:   updatedBankAccount.Closed,
+		})
+	}
+}
+
+type getBankAccountBalanceResponse struct {
+	BankAccountID  string `json:"bank_account_id"`
+	Currency       string `json:"currency"`
+	Balance        int64  `json:"balance"`
+	BudgetCurrency string `json:"budget_currency"`
+	BudgetBalance  int64  `json:"budget_balance"`
+}
+
+func (ba *BankAccounts) GetBalance() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ba.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, ba.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, ba.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		balance, err := ba.SBankAccounts.GetBalance(r.Context(), bankAccountID)
+		switch err {
+		case constants.ErrNoExchangeRate:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error getting bank account balance")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, getBankAccountBalanceResponse{
+			BankAccountID:  balance.BankAccountID,
+			Currency:       balance.Currency,
+			Balance:        balance.Balance,
+			BudgetCurrency: balance.BudgetCurrency,
+			BudgetBalance:  balance.BudgetBalance,
+		})
+	}
+}
+
+func (ba *BankAccounts) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ba.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, ba.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, ba.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		err := ba.SBankAccounts.Delete(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error deleting bank account")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/paulwrubel/moneybags-server/controllers"
 	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,12 +40,12 @@ func TestBankAccountsGetAll(t *testing.T) {
 			},
 			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
 				userExistsCall := mua.EXPECT().
-					ExistsByUsername(gomock.Eq("user_1")).
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
 					Times(1).
 					Return(true, nil)
 
 				getUserCall := mua.EXPECT().
-					GetInfo(gomock.Eq("user_1")).
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
 					After(userExistsCall).
 					Times(1).
 					Return(&models.UserAccount{
@@ -55,32 +56,38 @@ func TestBankAccountsGetAll(t *testing.T) {
 					}, nil)
 
 				existsCall := mb.EXPECT().
-					ExistsByID(gomock.Eq("__bid_1__")).
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
 					After(getUserCall).
 					Times(1).
 					Return(true, nil)
 
-				belongsToCall := mb.EXPECT().
-					BelongsTo(gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__")).
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
 					After(existsCall).
 					Times(1).
-					Return(true, nil)
+					Return(nil)
 
 				mba.EXPECT().
-					GetAll(gomock.Eq("__bid_1__")).
-					After(belongsToCall).
+					GetPage(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq(&pagination.Params{Limit: constants.DefaultPageLimit, Sort: "name"})).
+					After(authorizeCall).
 					Times(1).
 					Return([]*models.BankAccount{
 						{
 							ID:       "__baid_1__",
 							BudgetID: "__bid_1__",
 							Name:     "bank_account_1",
+							Type:     models.BankAccountTypeChecking,
+							Closed:   false,
+							Currency: "USD",
 						}, {
 							ID:       "__baid_2__",
 							BudgetID: "__bid_1__",
 							Name:     "bank_account_2",
+							Type:     models.BankAccountTypeSavings,
+							Closed:   true,
+							Currency: "USD",
 						},
-					}, nil)
+					}, nil, nil)
 
 			},
 			requestMethod:      http.MethodGet,
@@ -90,11 +97,17 @@ func TestBankAccountsGetAll(t *testing.T) {
 				"bank_accounts": [
 					{	
 						"id": "__baid_1__",
-						"name": "bank_account_1"
+						"name": "bank_account_1",
+						"type": "checking",
+						"currency": "USD",
+						"closed": false
 					},
 					{
 						"id": "__baid_2__",
-						"name": "bank_account_2"
+						"name": "bank_account_2",
+						"type": "savings",
+						"currency": "USD",
+						"closed": true
 					}
 				]
 			}`,
@@ -131,3 +144,1179 @@ func TestBankAccountsGetAll(t *testing.T) {
 		})
 	}
 }
+
+func TestBankAccountsPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				nameExistsCall := mba.EXPECT().
+					ExistsByBudgetIDAndName(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1")).
+					After(authorizeCall).
+					Times(1).
+					Return(false, nil)
+
+				mba.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1"), gomock.Eq(models.BankAccountTypeSavings), gomock.Eq("EUR")).
+					After(nameExistsCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "bank_account_1",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   false,
+						Currency: "EUR",
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": "bank_account_1",
+				"type": "savings",
+				"currency": "eur"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__baid_1__",
+				"name": "bank_account_1",
+				"type": "savings",
+				"currency": "EUR",
+				"closed": false
+			}`,
+		},
+		{
+			name:     "post - success - currency defaults to the budget's",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				nameExistsCall := mba.EXPECT().
+					ExistsByBudgetIDAndName(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1")).
+					After(authorizeCall).
+					Times(1).
+					Return(false, nil)
+
+				getBudgetCall := mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(nameExistsCall).
+					Times(1).
+					Return(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "GBP",
+					}, nil)
+
+				mba.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1"), gomock.Eq(models.BankAccountTypeChecking), gomock.Eq("GBP")).
+					After(getBudgetCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "bank_account_1",
+						Type:     models.BankAccountTypeChecking,
+						Closed:   false,
+						Currency: "GBP",
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": "bank_account_1"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__baid_1__",
+				"name": "bank_account_1",
+				"type": "checking",
+				"currency": "GBP",
+				"closed": false
+			}`,
+		},
+		{
+			name:     "post - failure - invalid currency",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				nameExistsCall := mba.EXPECT().
+					ExistsByBudgetIDAndName(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1")).
+					After(authorizeCall).
+					Times(1).
+					Return(false, nil)
+
+				mba.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1"), gomock.Eq(models.BankAccountTypeChecking), gomock.Eq("ZZZ")).
+					After(nameExistsCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidCurrency)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": "bank_account_1",
+				"currency": "zzz"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid currency"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - duplicate name",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mba.EXPECT().
+					ExistsByBudgetIDAndName(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_1")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"name": "bank_account_1"
+			}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Bank account already exists"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			ba := &controllers.BankAccounts{
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			ba.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestBankAccountsGet(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "get - success - foreign currency",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "bank_account_1",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   false,
+						Currency: "EUR",
+					}, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"id": "__baid_1__",
+				"name": "bank_account_1",
+				"type": "savings",
+				"currency": "EUR",
+				"closed": false
+			}`,
+		},
+		{
+			name:     "get - failure - not found",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(false, nil)
+
+				mba.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Bank account does not exist"
+				}]
+			}`,
+		},
+		{
+			name:     "get - failure - bank account in another budget",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(false, nil)
+
+				mba.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					After(belongsCall).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Bank account does not exist"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			ba := &controllers.BankAccounts{
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			ba.Get().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestBankAccountsPatch(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "patch - success - keeps currency",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				getCall := mba.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "bank_account_1",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   false,
+						Currency: "EUR",
+					}, nil)
+
+				nameExistsCall := mba.EXPECT().
+					ExistsByBudgetIDAndName(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("euro savings")).
+					After(getCall).
+					Times(1).
+					Return(false, nil)
+
+				mba.EXPECT().
+					Update(gomock.Any(), gomock.Eq(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "euro savings",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   true,
+						Currency: "EUR",
+					})).
+					After(nameExistsCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "euro savings",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   true,
+						Currency: "EUR",
+					}, nil)
+			},
+			requestMethod: http.MethodPatch,
+			requestBody: `{
+				"name": "euro savings",
+				"closed": true,
+				"currency": "USD"
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"id": "__baid_1__",
+				"name": "euro savings",
+				"type": "savings",
+				"currency": "EUR",
+				"closed": true
+			}`,
+		},
+		{
+			name:     "patch - failure - invalid type",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				getCall := mba.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "bank_account_1",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   false,
+						Currency: "EUR",
+					}, nil)
+
+				mba.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					After(getCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidBankAccountType)
+			},
+			requestMethod: http.MethodPatch,
+			requestBody: `{
+				"type": "mattress"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid bank account type"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - invalid request body",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					After(belongsCall).
+					Times(0)
+			},
+			requestMethod: http.MethodPatch,
+			requestBody: `{
+				"closed": "yes"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "error unmarshalling request body: json: cannot unmarshal string into Go struct field patchBankAccountRequest.closed of type bool"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - duplicate name",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				getCall := mba.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(&models.BankAccount{
+						ID:       "__baid_1__",
+						BudgetID: "__bid_1__",
+						Name:     "bank_account_1",
+						Type:     models.BankAccountTypeSavings,
+						Closed:   false,
+						Currency: "EUR",
+					}, nil)
+
+				mba.EXPECT().
+					ExistsByBudgetIDAndName(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("bank_account_2")).
+					After(getCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPatch,
+			requestBody: `{
+				"name": "bank_account_2"
+			}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Bank account already exists"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			ba := &controllers.BankAccounts{
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			ba.Patch().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestBankAccountsGetBalance(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "get balance - success - converted into budget currency",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/balance",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					GetBalance(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(&models.BankAccountBalance{
+						BankAccountID:  "__baid_1__",
+						Currency:       "EUR",
+						Balance:        10000,
+						BudgetCurrency: "USD",
+						BudgetBalance:  10850,
+					}, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"bank_account_id": "__baid_1__",
+				"currency": "EUR",
+				"balance": 10000,
+				"budget_currency": "USD",
+				"budget_balance": 10850
+			}`,
+		},
+		{
+			name:     "get balance - success - same currency",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/balance",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					GetBalance(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(&models.BankAccountBalance{
+						BankAccountID:  "__baid_1__",
+						Currency:       "USD",
+						Balance:        -2500,
+						BudgetCurrency: "USD",
+						BudgetBalance:  -2500,
+					}, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"bank_account_id": "__baid_1__",
+				"currency": "USD",
+				"balance": -2500,
+				"budget_currency": "USD",
+				"budget_balance": -2500
+			}`,
+		},
+		{
+			name:     "get balance - failure - no exchange rate",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/balance",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__", "bankAccountID": "__baid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				belongsCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					GetBalance(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(belongsCall).
+					Times(1).
+					Return(nil, constants.ErrNoExchangeRate)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "no exchange rate between the currencies"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			ba := &controllers.BankAccounts{
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			ba.GetBalance().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
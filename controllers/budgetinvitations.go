@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type BudgetInvitations struct {
+	SBudgetInvitations services.IBudgetInvitations
+	SBudgets           services.IBudgets
+	SUserAccounts      services.IUserAccounts
+}
+
+type budgetInvitationResponse struct {
+	ID         string    `json:"id"`
+	BudgetID   string    `json:"budget_id"`
+	BudgetName string    `json:"budget_name"`
+	Username   string    `json:"username"`
+	Role       string    `json:"role"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type getAllBudgetInvitationsResponse struct {
+	Invitations []budgetInvitationResponse `json:"invitations"`
+}
+
+// GetAll lists the budget's pending invitations
+func (bi *BudgetInvitations) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bi.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, bi.SBudgets, userAccount.ID, budgetID, models.BudgetRoleOwner) {
+			return
+		}
+
+		invitations, err := bi.SBudgetInvitations.GetAll(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all budget invitations")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newGetAllBudgetInvitationsResponse(invitations))
+	}
+}
+
+type postBudgetInvitationRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (bi *BudgetInvitations) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bi.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, bi.SBudgets, userAccount.ID, budgetID, models.BudgetRoleOwner) {
+			return
+		}
+
+		var requestBody postBudgetInvitationRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		createdInvitation, err := bi.SBudgetInvitations.Create(r.Context(), budgetID, userAccount.ID, requestBody.Username, models.BudgetRole(requestBody.Role))
+		switch err {
+		case constants.ErrInvalidBudgetRole:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrUserDoesNotExist:
+			writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("User does not exist"))
+			return
+		case constants.ErrAlreadyBudgetMember, constants.ErrBudgetInvitationExists:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating budget invitation")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, newBudgetInvitationResponse(createdInvitation))
+	}
+}
+
+// Delete revokes one of the budget's pending invitations
+func (bi *BudgetInvitations) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bi.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		invitationID := mux.Vars(r)["invitationID"]
+
+		if !validateBudget(r.Context(), rw, bi.SBudgets, userAccount.ID, budgetID, models.BudgetRoleOwner) {
+			return
+		}
+		if !validateBudgetInvitation(r.Context(), rw, bi.SBudgetInvitations, budgetID, invitationID) {
+			return
+		}
+
+		err := bi.SBudgetInvitations.Delete(r.Context(), invitationID)
+		if err != nil {
+			log.WithError(err).Error("Error deleting budget invitation")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetAllReceived lists the invitations sent to the user
+func (bi *BudgetInvitations) GetAllReceived() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bi.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		invitations, err := bi.SBudgetInvitations.GetAllByUserAccountID(r.Context(), userAccount.ID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all received budget invitations")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newGetAllBudgetInvitationsResponse(invitations))
+	}
+}
+
+// PostAcceptance accepts an invitation sent to the user, making them a
+// member of the budget
+func (bi *BudgetInvitations) PostAcceptance() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bi.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		invitationID := mux.Vars(r)["invitationID"]
+
+		if !validateReceivedBudgetInvitation(r.Context(), rw, bi.SBudgetInvitations, userAccount.ID, invitationID) {
+			return
+		}
+
+		err := bi.SBudgetInvitations.Accept(r.Context(), invitationID)
+		if err != nil {
+			log.WithError(err).Error("Error accepting budget invitation")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// DeleteReceived declines an invitation sent to the user
+func (bi *BudgetInvitations) DeleteReceived() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bi.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		invitationID := mux.Vars(r)["invitationID"]
+
+		if !validateReceivedBudgetInvitation(r.Context(), rw, bi.SBudgetInvitations, userAccount.ID, invitationID) {
+			return
+		}
+
+		err := bi.SBudgetInvitations.Delete(r.Context(), invitationID)
+		if err != nil {
+			log.WithError(err).Error("Error declining budget invitation")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newGetAllBudgetInvitationsResponse(invitations []*models.BudgetInvitation) getAllBudgetInvitationsResponse {
+	response := getAllBudgetInvitationsResponse{
+		Invitations: []budgetInvitationResponse{},
+	}
+	for _, invitation := range invitations {
+		response.Invitations = append(response.Invitations, newBudgetInvitationResponse(invitation))
+	}
+	return response
+}
+
+func newBudgetInvitationResponse(invitation *models.BudgetInvitation) budgetInvitationResponse {
+	return budgetInvitationResponse{
+		ID:         invitation.ID,
+		BudgetID:   invitation.BudgetID,
+		BudgetName: invitation.BudgetName,
+		Username:   invitation.Username,
+		Role:       string(invitation.Role),
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
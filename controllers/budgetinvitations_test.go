@@ -0,0 +1,239 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetInvitationsPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mbi *mockservices.MockIBudgetInvitations, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/budgets/__bid_1__/invitations",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mbi *mockservices.MockIBudgetInvitations, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mbi.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__uaid_1__"), gomock.Eq("user_2"), gomock.Eq(models.BudgetRoleViewer)).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.BudgetInvitation{
+						ID:                     "__biid_1__",
+						BudgetID:               "__bid_1__",
+						BudgetName:             "budget_1",
+						UserAccountID:          "__uaid_2__",
+						Username:               "user_2",
+						InvitedByUserAccountID: "__uaid_1__",
+						Role:                   models.BudgetRoleViewer,
+						CreatedAt:              time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC),
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"username": "user_2",
+				"role": "viewer"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__biid_1__",
+				"budget_id": "__bid_1__",
+				"budget_name": "budget_1",
+				"username": "user_2",
+				"role": "viewer",
+				"created_at": "2022-03-01T12:00:00Z"
+			}`,
+		},
+		{
+			name:     "post - failure - already a member",
+			endpoint: "/api/v1/budgets/__bid_1__/invitations",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mbi *mockservices.MockIBudgetInvitations, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mbi.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__uaid_1__"), gomock.Eq("user_2"), gomock.Eq(models.BudgetRoleEditor)).
+					After(authorizeCall).
+					Times(1).
+					Return(nil, constants.ErrAlreadyBudgetMember)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"username": "user_2",
+				"role": "editor"
+			}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "user is already a member of the budget"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - editor",
+			endpoint: "/api/v1/budgets/__bid_1__/invitations",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mbi *mockservices.MockIBudgetInvitations, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(constants.ErrInsufficientBudgetRole)
+
+				mbi.EXPECT().
+					Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"username": "user_2",
+				"role": "editor"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget role owner is required"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBudgetInvitationsService := mockservices.NewMockIBudgetInvitations(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBudgetInvitationsService, mockBudgetsService, mockUserAccountsService)
+
+			bi := &controllers.BudgetInvitations{
+				SBudgetInvitations: mockBudgetInvitationsService,
+				SBudgets:           mockBudgetsService,
+				SUserAccounts:      mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			bi.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
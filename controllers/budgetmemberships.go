@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type BudgetMemberships struct {
+	SBudgetMemberships services.IBudgetMemberships
+	SBudgets           services.IBudgets
+	SUserAccounts      services.IUserAccounts
+}
+
+type budgetMembershipResponse struct {
+	UserAccountID string `json:"user_account_id"`
+	Username      string `json:"username"`
+	Role          string `json:"role"`
+}
+
+type getAllBudgetMembershipsResponse struct {
+	Members []budgetMembershipResponse `json:"members"`
+}
+
+func (bm *BudgetMemberships) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bm.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, bm.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		memberships, err := bm.SBudgetMemberships.GetAll(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all budget members")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getAllBudgetMembershipsResponse{
+			Members: []budgetMembershipResponse{},
+		}
+		for _, membership := range memberships {
+			response.Members = append(response.Members, newBudgetMembershipResponse(membership))
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+type patchBudgetMembershipRequest struct {
+	Role string `json:"role"`
+}
+
+func (bm *BudgetMemberships) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bm.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		memberID := mux.Vars(r)["userAccountID"]
+
+		if !validateBudget(r.Context(), rw, bm.SBudgets, userAccount.ID, budgetID, models.BudgetRoleOwner) {
+			return
+		}
+		if !validateBudgetMembership(r.Context(), rw, bm.SBudgetMemberships, budgetID, memberID) {
+			return
+		}
+
+		var requestBody patchBudgetMembershipRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		updatedMembership, err := bm.SBudgetMemberships.UpdateRole(r.Context(), budgetID, memberID, models.BudgetRole(requestBody.Role))
+		switch err {
+		case constants.ErrInvalidBudgetRole:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrBudgetNeedsOwner:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating budget member")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newBudgetMembershipResponse(updatedMembership))
+	}
+}
+
+// Delete removes a member from the budget. Owners can remove anyone, and any
+// member can remove themselves to leave the budget.
+func (bm *BudgetMemberships) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, bm.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		memberID := mux.Vars(r)["userAccountID"]
+
+		requiredRole := models.BudgetRoleOwner
+		if memberID == userAccount.ID {
+			requiredRole = models.BudgetRoleViewer
+		}
+		if !validateBudget(r.Context(), rw, bm.SBudgets, userAccount.ID, budgetID, requiredRole) {
+			return
+		}
+		if !validateBudgetMembership(r.Context(), rw, bm.SBudgetMemberships, budgetID, memberID) {
+			return
+		}
+
+		err := bm.SBudgetMemberships.Delete(r.Context(), budgetID, memberID)
+		switch err {
+		case constants.ErrBudgetNeedsOwner:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error deleting budget member")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newBudgetMembershipResponse(membership *models.BudgetMembership) budgetMembershipResponse {
+	return budgetMembershipResponse{
+		UserAccountID: membership.UserAccountID,
+		Username:      membership.Username,
+		Role:          string(membership.Role),
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/currency"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/paulwrubel/moneybags-server/services"
 	log "github.com/sirupsen/logrus"
 )
@@ -14,12 +18,15 @@ type Budgets struct {
 }
 
 type getAllBudgetsResponse struct {
-	Budgets []getAllBudgetsResponseBudget `json:"budgets"`
+	Budgets    []getAllBudgetsResponseBudget `json:"budgets"`
+	NextCursor *string                       `json:"next_cursor,omitempty"`
 }
 
 type getAllBudgetsResponseBudget struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Archived bool   `json:"archived"`
 }
 
 func (b *Budgets) GetAll() http.HandlerFunc {
@@ -29,7 +36,12 @@ func (b *Budgets) GetAll() http.HandlerFunc {
 			return
 		}
 
-		budgets, err := b.SBudgets.GetAllByUserAccountID(userAccount.ID)
+		page, ok := validatePage(rw, r, []pagination.SortKey{{Name: "name", Type: pagination.SortText}}, "name")
+		if !ok {
+			return
+		}
+
+		budgets, next, err := b.SBudgets.GetPageByUserAccountID(r.Context(), userAccount.ID, page)
 		if err != nil {
 			log.WithError(err).Error("Error getting all budgets")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
@@ -37,12 +49,15 @@ func (b *Budgets) GetAll() http.HandlerFunc {
 		}
 
 		response := getAllBudgetsResponse{
-			Budgets: []getAllBudgetsResponseBudget{},
+			Budgets:    []getAllBudgetsResponseBudget{},
+			NextCursor: encodeCursor(next),
 		}
 		for _, budget := range budgets {
 			response.Budgets = append(response.Budgets, getAllBudgetsResponseBudget{
-				ID:   budget.ID,
-				Name: budget.Name,
+				ID:       budget.ID,
+				Name:     budget.Name,
+				Currency: budget.Currency,
+				Archived: budget.Archived,
 			})
 		}
 		writeResponse(rw, http.StatusOK, response)
@@ -50,8 +65,10 @@ func (b *Budgets) GetAll() http.HandlerFunc {
 }
 
 type getBudgetResponse struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Archived bool   `json:"archived"`
 }
 
 func (b *Budgets) Get() http.HandlerFunc {
@@ -63,29 +80,11 @@ func (b *Budgets) Get() http.HandlerFunc {
 
 		budgetID := mux.Vars(r)["budgetID"]
 
-		exists, err := b.SBudgets.ExistsByID(budgetID)
-		if err != nil {
-			log.WithError(err).Error("Error checking if budget exists")
-			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
-			return
-		}
-		if !exists {
-			writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Budget does not exist"))
+		if !validateBudget(r.Context(), rw, b.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
 			return
 		}
 
-		belongsToRequestor, err := b.SBudgets.BelongsTo(userAccount.ID, budgetID)
-		if err != nil {
-			log.WithError(err).Error("Error checking if budget belongs to user")
-			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
-			return
-		}
-		if !belongsToRequestor {
-			writeResponse(rw, http.StatusForbidden, errorsResponseFromMessages("Budget does not belong to user"))
-			return
-		}
-
-		budget, err := b.SBudgets.GetByID(budgetID)
+		budget, err := b.SBudgets.GetByID(r.Context(), budgetID)
 		if err != nil {
 			log.WithError(err).Error("Error getting budget")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
@@ -93,19 +92,26 @@ func (b *Budgets) Get() http.HandlerFunc {
 		}
 
 		writeResponse(rw, http.StatusOK, getBudgetResponse{
-			ID:   budget.ID,
-			Name: budget.Name,
+			ID:       budget.ID,
+			Name:     budget.Name,
+			Currency: budget.Currency,
+			Archived: budget.Archived,
 		})
 	}
 }
 
+// postBudgetRequest creates a budget. Currency defaults to
+// constants.DefaultCurrency when left out.
 type postBudgetRequest struct {
-	Name string `json:"name"`
+	Name     string  `json:"name"`
+	Currency *string `json:"currency"`
 }
 
 type postBudgetResponse struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Archived bool   `json:"archived"`
 }
 
 func (b *Budgets) Post() http.HandlerFunc {
@@ -122,7 +128,7 @@ func (b *Budgets) Post() http.HandlerFunc {
 			return
 		}
 
-		exists, err := b.SBudgets.ExistsByUserIDAndName(userAccount.ID, requestBody.Name)
+		exists, err := b.SBudgets.ExistsByUserIDAndName(r.Context(), userAccount.ID, requestBody.Name)
 		if err != nil {
 			log.WithError(err).Error("Error checking if budget exists")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
@@ -133,16 +139,149 @@ func (b *Budgets) Post() http.HandlerFunc {
 			return
 		}
 
-		createdBudget, err := b.SBudgets.Create(userAccount.ID, requestBody.Name)
-		if err != nil {
+		currencyCode := constants.DefaultCurrency
+		if requestBody.Currency != nil {
+			currencyCode = currency.Normalize(*requestBody.Currency)
+		}
+
+		createdBudget, err := b.SBudgets.Create(r.Context(), userAccount.ID, requestBody.Name, currencyCode)
+		switch err {
+		case constants.ErrInvalidCurrency:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
 			log.WithError(err).Error("Error creating budget")
 			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
 			return
 		}
 
 		writeResponse(rw, http.StatusCreated, postBudgetResponse{
-			ID:   createdBudget.ID,
-			Name: createdBudget.Name,
+			ID:       createdBudget.ID,
+			Name:     createdBudget.Name,
+			Currency: createdBudget.Currency,
+			Archived: createdBudget.Archived,
 		})
 	}
 }
+
+type patchBudgetRequest struct {
+	Name     *string `json:"name"`
+	Currency *string `json:"currency"`
+	Archived *bool   `json:"archived"`
+}
+
+type patchBudgetResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Archived bool   `json:"archived"`
+}
+
+func (b *Budgets) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, b.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, b.SBudgets, userAccount.ID, budgetID, models.BudgetRoleOwner) {
+			return
+		}
+
+		var requestBody patchBudgetRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		budget, err := b.SBudgets.GetByID(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting budget")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		if requestBody.Name != nil && *requestBody.Name != budget.Name {
+			exists, err := b.SBudgets.ExistsByUserIDAndName(r.Context(), budget.UserAccountID, *requestBody.Name)
+			if err != nil {
+				log.WithError(err).Error("Error checking if budget exists")
+				writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+				return
+			}
+			if exists {
+				writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Budget already exists"))
+				return
+			}
+			budget.Name = *requestBody.Name
+		}
+		if requestBody.Currency != nil {
+			budget.Currency = currency.Normalize(*requestBody.Currency)
+		}
+		if requestBody.Archived != nil {
+			budget.Archived = *requestBody.Archived
+		}
+
+		updatedBudget, err := b.SBudgets.Update(r.Context(), budget)
+		switch err {
+		case constants.ErrInvalidCurrency:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrBudgetCurrencyInUse:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Budget currency cannot change once it has bank accounts or allocations"))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating budget")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, patchBudgetResponse{
+			ID:       updatedBudget.ID,
+			Name:     updatedBudget.Name,
+			Currency: updatedBudget.Currency,
+			Archived: updatedBudget.Archived,
+		})
+	}
+}
+
+// Delete removes a budget. Budgets which still have bank accounts are only
+// deleted when the "cascade" query parameter is set to true; archiving the
+// budget through Patch is the non-destructive alternative.
+func (b *Budgets) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, b.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, b.SBudgets, userAccount.ID, budgetID, models.BudgetRoleOwner) {
+			return
+		}
+
+		cascade := r.URL.Query().Get("cascade") == "true"
+
+		err := b.SBudgets.Delete(r.Context(), budgetID, cascade)
+		switch err {
+		case constants.ErrBudgetHasBankAccounts:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Budget still has bank accounts. Delete them first or set cascade=true"))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error deleting budget")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/paulwrubel/moneybags-server/controllers"
 	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,12 +39,12 @@ func TestBudgetsGetAll(t *testing.T) {
 			},
 			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
 				userExistsCall := mua.EXPECT().
-					ExistsByUsername(gomock.Eq("user_1")).
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
 					Times(1).
 					Return(true, nil)
 
 				getUserCall := mua.EXPECT().
-					GetInfo(gomock.Eq("user_1")).
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
 					After(userExistsCall).
 					Times(1).
 					Return(&models.UserAccount{
@@ -54,7 +55,7 @@ func TestBudgetsGetAll(t *testing.T) {
 					}, nil)
 
 				mb.EXPECT().
-					GetAllByUserAccountID(gomock.Eq("__uaid_1__")).
+					GetPageByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq(&pagination.Params{Limit: constants.DefaultPageLimit, Sort: "name"})).
 					After(getUserCall).
 					Times(1).
 					Return([]*models.Budget{
@@ -62,13 +63,15 @@ func TestBudgetsGetAll(t *testing.T) {
 							ID:            "__bid_1__",
 							UserAccountID: "__uaid_1__",
 							Name:          "budget_1",
+							Currency:      "USD",
 						},
 						{
 							ID:            "__bid_2__",
 							UserAccountID: "__uaid_1__",
 							Name:          "budget_2",
+							Currency:      "USD",
 						},
-					}, nil)
+					}, nil, nil)
 			},
 			requestMethod:      http.MethodGet,
 			requestBody:        ``,
@@ -77,11 +80,15 @@ func TestBudgetsGetAll(t *testing.T) {
 				"budgets": [
 					{
 						"id": "__bid_1__",
-						"name": "budget_1"
+						"name": "budget_1",
+						"currency": "USD",
+						"archived": false
 					},
 					{
 						"id": "__bid_2__",
-						"name": "budget_2"
+						"name": "budget_2",
+						"currency": "USD",
+						"archived": false
 					}
 				]
 			}`,
@@ -141,12 +148,12 @@ func TestBudgetsGet(t *testing.T) {
 			},
 			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
 				userExistsCall := mua.EXPECT().
-					ExistsByUsername(gomock.Eq("user_1")).
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
 					Times(1).
 					Return(true, nil)
 
 				getUserCall := mua.EXPECT().
-					GetInfo(gomock.Eq("user_1")).
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
 					After(userExistsCall).
 					Times(1).
 					Return(&models.UserAccount{
@@ -157,24 +164,25 @@ func TestBudgetsGet(t *testing.T) {
 					}, nil)
 
 				existsCall := mb.EXPECT().
-					ExistsByID(gomock.Eq("__bid_1__")).
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
 					After(getUserCall).
 					Times(1).
 					Return(true, nil)
 
-				belongsToCall := mb.EXPECT().
-					BelongsTo(gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__")).
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
 					After(existsCall).
 					Times(1).
-					Return(true, nil)
+					Return(nil)
 
 				mb.EXPECT().
-					GetByID(gomock.Eq("__bid_1__")).
-					After(belongsToCall).
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
 					Times(1).
 					Return(&models.Budget{
-						ID:   "__bid_1__",
-						Name: "budget_1",
+						ID:       "__bid_1__",
+						Name:     "budget_1",
+						Currency: "USD",
 					}, nil)
 			},
 			requestMethod:      http.MethodGet,
@@ -182,7 +190,9 @@ func TestBudgetsGet(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedResponseBody: `{
 				"id": "__bid_1__",
-				"name": "budget_1"
+				"name": "budget_1",
+				"currency": "USD",
+				"archived": false
 			}`,
 		},
 	}
@@ -236,12 +246,12 @@ func TestBudgetsPost(t *testing.T) {
 			},
 			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
 				userExistsCall := mua.EXPECT().
-					ExistsByUsername(gomock.Eq("user_1")).
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
 					Times(1).
 					Return(true, nil)
 
 				getUserCall := mua.EXPECT().
-					GetInfo(gomock.Eq("user_1")).
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
 					After(userExistsCall).
 					Times(1).
 					Return(&models.UserAccount{
@@ -252,19 +262,20 @@ func TestBudgetsPost(t *testing.T) {
 					}, nil)
 
 				existsCall := mb.EXPECT().
-					ExistsByUserIDAndName(gomock.Eq("__uaid_1__"), gomock.Eq("budget_1")).
+					ExistsByUserIDAndName(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("budget_1")).
 					After(getUserCall).
 					Times(1).
 					Return(false, nil)
 
 				mb.EXPECT().
-					Create(gomock.Eq("__uaid_1__"), gomock.Eq("budget_1")).
+					Create(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("budget_1"), gomock.Eq(constants.DefaultCurrency)).
 					After(existsCall).
 					Times(1).
 					Return(&models.Budget{
 						ID:            "__bid_1__",
 						UserAccountID: "__uaid_1__",
 						Name:          "budget_1",
+						Currency:      "USD",
 					}, nil)
 			},
 			requestMethod: http.MethodPost,
@@ -274,7 +285,9 @@ func TestBudgetsPost(t *testing.T) {
 			expectedStatusCode: http.StatusCreated,
 			expectedResponseBody: `{
 				"id": "__bid_1__",
-				"name": "budget_1"
+				"name": "budget_1",
+				"currency": "USD",
+				"archived": false
 			}`,
 		},
 	}
@@ -307,3 +320,760 @@ func TestBudgetsPost(t *testing.T) {
 		})
 	}
 }
+
+func TestBudgetsPatch(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "patch - success - rename and change currency",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				getCall := mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "USD",
+						Archived:      false,
+					}, nil)
+
+				nameExistsCall := mb.EXPECT().
+					ExistsByUserIDAndName(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("budget_2")).
+					After(getCall).
+					Times(1).
+					Return(false, nil)
+
+				mb.EXPECT().
+					Update(gomock.Any(), gomock.Eq(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_2",
+						Currency:      "EUR",
+						Archived:      false,
+					})).
+					After(nameExistsCall).
+					Times(1).
+					DoAndReturn(func(_ context.Context, budget *models.Budget) (*models.Budget, error) {
+						return budget, nil
+					})
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"name": "budget_2", "currency": " eur "}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"id": "__bid_1__",
+				"name": "budget_2",
+				"currency": "EUR",
+				"archived": false
+			}`,
+		},
+		{
+			name:     "patch - success - archive keeps name",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				getCall := mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "USD",
+						Archived:      false,
+					}, nil)
+
+				mb.EXPECT().
+					ExistsByUserIDAndName(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				mb.EXPECT().
+					Update(gomock.Any(), gomock.Eq(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "USD",
+						Archived:      true,
+					})).
+					After(getCall).
+					Times(1).
+					DoAndReturn(func(_ context.Context, budget *models.Budget) (*models.Budget, error) {
+						return budget, nil
+					})
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"name": "budget_1", "archived": true}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"id": "__bid_1__",
+				"name": "budget_1",
+				"currency": "USD",
+				"archived": true
+			}`,
+		},
+		{
+			name:     "patch - failure - invalid request body",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					After(authorizeCall).
+					Times(0)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"archived": "yes"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "error unmarshalling request body: json: cannot unmarshal string into Go struct field patchBudgetRequest.archived of type bool"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - invalid currency",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				getCall := mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "USD",
+						Archived:      false,
+					}, nil)
+
+				mb.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					After(getCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidCurrency)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"currency": "ZZZ"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid currency"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - currency in use",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				getCall := mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "USD",
+						Archived:      false,
+					}, nil)
+
+				mb.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					After(getCall).
+					Times(1).
+					Return(nil, constants.ErrBudgetCurrencyInUse)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"currency": "JPY"}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget currency cannot change once it has bank accounts or allocations"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - name already exists",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				getCall := mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.Budget{
+						ID:            "__bid_1__",
+						UserAccountID: "__uaid_1__",
+						Name:          "budget_1",
+						Currency:      "USD",
+						Archived:      false,
+					}, nil)
+
+				nameExistsCall := mb.EXPECT().
+					ExistsByUserIDAndName(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("budget_2")).
+					After(getCall).
+					Times(1).
+					Return(true, nil)
+
+				mb.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					After(nameExistsCall).
+					Times(0)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"name": "budget_2"}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget already exists"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - budget does not exist",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(false, nil)
+
+				mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					After(existsCall).
+					Times(0)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"archived": true}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget does not exist"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - not a member of budget",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(constants.ErrNotBudgetMember)
+
+				mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					After(authorizeCall).
+					Times(0)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"archived": true}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget does not belong to user"
+				}]
+			}`,
+		},
+		{
+			name:     "patch - failure - insufficient role",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(constants.ErrInsufficientBudgetRole)
+
+				mb.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					After(authorizeCall).
+					Times(0)
+			},
+			requestMethod:      http.MethodPatch,
+			requestBody:        `{"archived": true}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget role owner is required"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBudgetsService, mockUserAccountsService)
+
+			b := &controllers.Budgets{
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			b.Patch().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestBudgetsDelete(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "delete - success - cascade",
+			endpoint: "/api/v1/budgets/__bid_1__?cascade=true",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mb.EXPECT().
+					Delete(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq(true)).
+					After(authorizeCall).
+					Times(1).
+					Return(nil)
+			},
+			requestMethod:        http.MethodDelete,
+			requestBody:          ``,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: ``,
+		},
+		{
+			name:     "delete - failure - has bank accounts",
+			endpoint: "/api/v1/budgets/__bid_1__",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				return r
+			},
+			mockSetupFunc: func(mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				existsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleOwner)).
+					After(existsCall).
+					Times(1).
+					Return(nil)
+
+				mb.EXPECT().
+					Delete(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq(false)).
+					After(authorizeCall).
+					Times(1).
+					Return(constants.ErrBudgetHasBankAccounts)
+			},
+			requestMethod:      http.MethodDelete,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget still has bank accounts. Delete them first or set cascade=true"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockBudgetsService, mockUserAccountsService)
+
+			b := &controllers.Budgets{
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			b.Delete().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Categories struct {
+	SCategories     services.ICategories
+	SCategoryGroups services.ICategoryGroups
+	SBudgets        services.IBudgets
+	SUserAccounts   services.IUserAccounts
+}
+
+type getAllCategoriesResponse struct {
+	CategoryGroups []getAllCategoriesResponseCategoryGroup `json:"category_groups"`
+}
+
+type getAllCategoriesResponseCategoryGroup struct {
+	ID         string                             `json:"id"`
+	Name       string                             `json:"name"`
+	Categories []getAllCategoriesResponseCategory `json:"categories"`
+}
+
+type getAllCategoriesResponseCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (c *Categories) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, c.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, c.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		categoryGroups, err := c.SCategoryGroups.GetAll(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all category groups")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+		categories, err := c.SCategories.GetAll(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all categories")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		categoriesByGroupID := map[string][]getAllCategoriesResponseCategory{}
+		for _, category := range categories {
+			categoriesByGroupID[category.CategoryGroupID] = append(categoriesByGroupID[category.CategoryGroupID], getAllCategoriesResponseCategory{
+				ID:   category.ID,
+				Name: category.Name,
+			})
+		}
+
+		response := getAllCategoriesResponse{
+			CategoryGroups: []getAllCategoriesResponseCategoryGroup{},
+		}
+		for _, categoryGroup := range categoryGroups {
+			groupCategories, ok := categoriesByGroupID[categoryGroup.ID]
+			if !ok {
+				groupCategories = []getAllCategoriesResponseCategory{}
+			}
+			response.CategoryGroups = append(response.CategoryGroups, getAllCategoriesResponseCategoryGroup{
+				ID:         categoryGroup.ID,
+				Name:       categoryGroup.Name,
+				Categories: groupCategories,
+			})
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+type postCategoryGroupRequest struct {
+	Name string `json:"name"`
+}
+
+type postCategoryGroupResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (c *Categories) PostGroup() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, c.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, c.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+
+		var requestBody postCategoryGroupRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		exists, err := c.SCategoryGroups.ExistsByBudgetIDAndName(r.Context(), budgetID, requestBody.Name)
+		if err != nil {
+			log.WithError(err).Error("Error checking if category group exists")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+		if exists {
+			writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Category group already exists"))
+			return
+		}
+
+		createdCategoryGroup, err := c.SCategoryGroups.Create(r.Context(), budgetID, requestBody.Name)
+		if err != nil {
+			log.WithError(err).Error("Error creating category group")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, postCategoryGroupResponse{
+			ID:   createdCategoryGroup.ID,
+			Name: createdCategoryGroup.Name,
+		})
+	}
+}
+
+type postCategoryRequest struct {
+	CategoryGroupID string `json:"category_group_id"`
+	Name            string `json:"name"`
+}
+
+type postCategoryResponse struct {
+	ID              string `json:"id"`
+	CategoryGroupID string `json:"category_group_id"`
+	Name            string `json:"name"`
+}
+
+func (c *Categories) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, c.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, c.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+
+		var requestBody postCategoryRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		if !validateCategoryGroup(r.Context(), rw, c.SCategoryGroups, budgetID, requestBody.CategoryGroupID) {
+			return
+		}
+
+		exists, err := c.SCategories.ExistsByCategoryGroupIDAndName(r.Context(), requestBody.CategoryGroupID, requestBody.Name)
+		if err != nil {
+			log.WithError(err).Error("Error checking if category exists")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+		if exists {
+			writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Category already exists"))
+			return
+		}
+
+		createdCategory, err := c.SCategories.Create(r.Context(), requestBody.CategoryGroupID, requestBody.Name)
+		if err != nil {
+			log.WithError(err).Error("Error creating category")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, postCategoryResponse{
+			ID:              createdCategory.ID,
+			CategoryGroupID: createdCategory.CategoryGroupID,
+			Name:            createdCategory.Name,
+		})
+	}
+}
+
+type patchCategoryRequest struct {
+	CategoryGroupID *string `json:"category_group_id"`
+	Name            *string `json:"name"`
+}
+
+type patchCategoryResponse struct {
+	ID              string `json:"id"`
+	CategoryGroupID string `json:"category_group_id"`
+	Name            string `json:"name"`
+}
+
+func (c *Categories) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, c.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		categoryID := mux.Vars(r)["categoryID"]
+
+		if !validateBudget(r.Context(), rw, c.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateCategory(r.Context(), rw, c.SCategories, budgetID, categoryID) {
+			return
+		}
+
+		var requestBody patchCategoryRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		category, err := c.SCategories.GetByID(r.Context(), categoryID)
+		if err != nil {
+			log.WithError(err).Error("Error getting category")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		if requestBody.CategoryGroupID != nil {
+			if !validateCategoryGroup(r.Context(), rw, c.SCategoryGroups, budgetID, *requestBody.CategoryGroupID) {
+				return
+			}
+			category.CategoryGroupID = *requestBody.CategoryGroupID
+		}
+		if requestBody.Name != nil {
+			category.Name = *requestBody.Name
+		}
+
+		if requestBody.CategoryGroupID != nil || requestBody.Name != nil {
+			exists, err := c.SCategories.ExistsByCategoryGroupIDAndName(r.Context(), category.CategoryGroupID, category.Name)
+			if err != nil {
+				log.WithError(err).Error("Error checking if category exists")
+				writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+				return
+			}
+			if exists {
+				writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Category already exists"))
+				return
+			}
+		}
+
+		updatedCategory, err := c.SCategories.Update(r.Context(), category)
+		if err != nil {
+			log.WithError(err).Error("Error updating category")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, patchCategoryResponse{
+			ID:              updatedCategory.ID,
+			CategoryGroupID: updatedCategory.CategoryGroupID,
+			Name:            updatedCategory.Name,
+		})
+	}
+}
+
+func (c *Categories) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, c.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		categoryID := mux.Vars(r)["categoryID"]
+
+		if !validateBudget(r.Context(), rw, c.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateCategory(r.Context(), rw, c.SCategories, budgetID, categoryID) {
+			return
+		}
+
+		err := c.SCategories.Delete(r.Context(), categoryID)
+		if err != nil {
+			log.WithError(err).Error("Error deleting category")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,163 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoriesGetAll(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mc *mockservices.MockICategories, mcg *mockservices.MockICategoryGroups, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "get all - success",
+			endpoint: "/api/v1/budgets/__bid_1__/categories",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{"budgetID": "__bid_1__"})
+				return r
+			},
+			mockSetupFunc: func(mc *mockservices.MockICategories, mcg *mockservices.MockICategoryGroups, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				getGroupsCall := mcg.EXPECT().
+					GetAll(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return([]*models.CategoryGroup{
+						{
+							ID:       "__cgid_1__",
+							BudgetID: "__bid_1__",
+							Name:     "category_group_1",
+						},
+						{
+							ID:       "__cgid_2__",
+							BudgetID: "__bid_1__",
+							Name:     "category_group_2",
+						},
+					}, nil)
+
+				mc.EXPECT().
+					GetAll(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getGroupsCall).
+					Times(1).
+					Return([]*models.Category{
+						{
+							ID:              "__cid_1__",
+							CategoryGroupID: "__cgid_1__",
+							Name:            "category_1",
+						},
+						{
+							ID:              "__cid_2__",
+							CategoryGroupID: "__cgid_1__",
+							Name:            "category_2",
+						},
+					}, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"category_groups": [
+					{
+						"id": "__cgid_1__",
+						"name": "category_group_1",
+						"categories": [
+							{
+								"id": "__cid_1__",
+								"name": "category_1"
+							},
+							{
+								"id": "__cid_2__",
+								"name": "category_2"
+							}
+						]
+					},
+					{
+						"id": "__cgid_2__",
+						"name": "category_group_2",
+						"categories": []
+					}
+				]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCategoriesService := mockservices.NewMockICategories(gomock.NewController(t))
+			mockCategoryGroupsService := mockservices.NewMockICategoryGroups(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockCategoriesService, mockCategoryGroupsService, mockBudgetsService, mockUserAccountsService)
+
+			c := &controllers.Categories{
+				SCategories:     mockCategoriesService,
+				SCategoryGroups: mockCategoryGroupsService,
+				SBudgets:        mockBudgetsService,
+				SUserAccounts:   mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			c.GetAll().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type CategorizationRules struct {
+	SCategorizationRules services.ICategorizationRules
+	SCategories          services.ICategories
+	SBudgets             services.IBudgets
+	SUserAccounts        services.IUserAccounts
+}
+
+type categorizationRuleResponse struct {
+	ID            string  `json:"id"`
+	CategoryID    string  `json:"category_id"`
+	PayeeContains *string `json:"payee_contains,omitempty"`
+	AmountMin     *int64  `json:"amount_min,omitempty"`
+	AmountMax     *int64  `json:"amount_max,omitempty"`
+	Priority      int     `json:"priority"`
+}
+
+type getAllCategorizationRulesResponse struct {
+	CategorizationRules []categorizationRuleResponse `json:"categorization_rules"`
+}
+
+func (cr *CategorizationRules) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, cr.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, cr.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		rules, err := cr.SCategorizationRules.GetAll(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all categorization rules")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getAllCategorizationRulesResponse{
+			CategorizationRules: []categorizationRuleResponse{},
+		}
+		for _, rule := range rules {
+			response.CategorizationRules = append(response.CategorizationRules, newCategorizationRuleResponse(rule))
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+// categorizationRuleRequest describes a whole rule. Rules are replaced rather
+// than patched, so that conditions can be removed by leaving them out.
+type categorizationRuleRequest struct {
+	CategoryID    string  `json:"category_id"`
+	PayeeContains *string `json:"payee_contains"`
+	AmountMin     *int64  `json:"amount_min"`
+	AmountMax     *int64  `json:"amount_max"`
+	Priority      int     `json:"priority"`
+}
+
+func (cr *CategorizationRules) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, cr.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, cr.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+
+		var requestBody categorizationRuleRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		if !validateCategory(r.Context(), rw, cr.SCategories, budgetID, requestBody.CategoryID) {
+			return
+		}
+
+		createdRule, err := cr.SCategorizationRules.Create(r.Context(), budgetID, requestBody.CategoryID, requestBody.PayeeContains, requestBody.AmountMin, requestBody.AmountMax, requestBody.Priority)
+		switch err {
+		case constants.ErrInvalidCategorizationRule:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating categorization rule")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, newCategorizationRuleResponse(createdRule))
+	}
+}
+
+func (cr *CategorizationRules) Put() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, cr.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		ruleID := mux.Vars(r)["ruleID"]
+
+		if !validateBudget(r.Context(), rw, cr.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateCategorizationRule(r.Context(), rw, cr.SCategorizationRules, budgetID, ruleID) {
+			return
+		}
+
+		var requestBody categorizationRuleRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		if !validateCategory(r.Context(), rw, cr.SCategories, budgetID, requestBody.CategoryID) {
+			return
+		}
+
+		updatedRule, err := cr.SCategorizationRules.Update(r.Context(), &models.CategorizationRule{
+			ID:            ruleID,
+			BudgetID:      budgetID,
+			CategoryID:    requestBody.CategoryID,
+			PayeeContains: requestBody.PayeeContains,
+			AmountMin:     requestBody.AmountMin,
+			AmountMax:     requestBody.AmountMax,
+			Priority:      requestBody.Priority,
+		})
+		switch err {
+		case constants.ErrInvalidCategorizationRule:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating categorization rule")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newCategorizationRuleResponse(updatedRule))
+	}
+}
+
+func (cr *CategorizationRules) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, cr.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		ruleID := mux.Vars(r)["ruleID"]
+
+		if !validateBudget(r.Context(), rw, cr.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateCategorizationRule(r.Context(), rw, cr.SCategorizationRules, budgetID, ruleID) {
+			return
+		}
+
+		err := cr.SCategorizationRules.Delete(r.Context(), ruleID)
+		if err != nil {
+			log.WithError(err).Error("Error deleting categorization rule")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newCategorizationRuleResponse(rule *models.CategorizationRule) categorizationRuleResponse {
+	return categorizationRuleResponse{
+		ID:            rule.ID,
+		CategoryID:    rule.CategoryID,
+		PayeeContains: rule.PayeeContains,
+		AmountMin:     rule.AmountMin,
+		AmountMax:     rule.AmountMax,
+		Priority:      rule.Priority,
+	}
+}
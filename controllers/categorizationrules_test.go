@@ -0,0 +1,221 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizationRulesPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mcr *mockservices.MockICategorizationRules, mc *mockservices.MockICategories, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/budgets/__bid_1__/categorization-rules",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mcr *mockservices.MockICategorizationRules, mc *mockservices.MockICategories, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				categoryExistsCall := mc.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__cid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				categoryBelongsToCall := mc.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__cid_1__")).
+					After(categoryExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mcr.EXPECT().
+					Create(gomock.Any(),
+						gomock.Eq("__bid_1__"),
+						gomock.Eq("__cid_1__"),
+						gomock.Eq(pointerify("AMAZON")),
+						gomock.Nil(),
+						gomock.Eq(int64ptr(0)),
+						gomock.Eq(10),
+					).
+					After(categoryBelongsToCall).
+					Times(1).
+					Return(&models.CategorizationRule{
+						ID:            "__crid_1__",
+						BudgetID:      "__bid_1__",
+						CategoryID:    "__cid_1__",
+						PayeeContains: pointerify("AMAZON"),
+						AmountMax:     int64ptr(0),
+						Priority:      10,
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"category_id": "__cid_1__",
+				"payee_contains": "AMAZON",
+				"amount_max": 0,
+				"priority": 10
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__crid_1__",
+				"category_id": "__cid_1__",
+				"payee_contains": "AMAZON",
+				"amount_max": 0,
+				"priority": 10
+			}`,
+		},
+		{
+			name:     "post - failure - no conditions",
+			endpoint: "/api/v1/budgets/__bid_1__/categorization-rules",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mcr *mockservices.MockICategorizationRules, mc *mockservices.MockICategories, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				categoryExistsCall := mc.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__cid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				categoryBelongsToCall := mc.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__cid_1__")).
+					After(categoryExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mcr.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__cid_1__"), gomock.Nil(), gomock.Nil(), gomock.Nil(), gomock.Eq(0)).
+					After(categoryBelongsToCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidCategorizationRule)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"category_id": "__cid_1__"
+			}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"errors":[{"message":"categorization rule must have a condition, and its minimum amount must not exceed its maximum"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockCategorizationRulesService := mockservices.NewMockICategorizationRules(mockCtrl)
+			mockCategoriesService := mockservices.NewMockICategories(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockCategorizationRulesService, mockCategoriesService, mockBudgetsService, mockUserAccountsService)
+
+			crc := &controllers.CategorizationRules{
+				SCategorizationRules: mockCategorizationRulesService,
+				SCategories:          mockCategoriesService,
+				SBudgets:             mockBudgetsService,
+				SUserAccounts:        mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			crc.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func int64ptr(i int64) *int64 {
+	return &i
+}
@@ -2,12 +2,107 @@ package controllers
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
 )
 
-type Health struct{}
+type Health struct {
+	SHealth services.IHealth
+}
+
+type livenessResponse struct {
+	Status models.HealthStatus `json:"status"`
+}
+
+type readinessResponse struct {
+	Status     models.HealthStatus         `json:"status"`
+	Draining   bool                        `json:"draining"`
+	Database   readinessResponseDatabase   `json:"database"`
+	JWTKey     readinessResponseJWTKey     `json:"jwt_key"`
+	Migrations readinessResponseMigrations `json:"migrations"`
+}
+
+type readinessResponseDatabase struct {
+	Status    models.HealthStatus   `json:"status"`
+	LatencyMS float64               `json:"latency_ms"`
+	Error     *string               `json:"error,omitempty"`
+	Pool      readinessResponsePool `json:"pool"`
+}
+
+type readinessResponsePool struct {
+	TotalConns        int32 `json:"total_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	MaxConns          int32 `json:"max_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+}
+
+type readinessResponseJWTKey struct {
+	Status models.HealthStatus `json:"status"`
+}
 
-func (h *Health) Get() http.HandlerFunc {
+type readinessResponseMigrations struct {
+	Status         models.HealthStatus `json:"status"`
+	CurrentVersion int                 `json:"current_version"`
+	LatestVersion  int                 `json:"latest_version"`
+	Error          *string             `json:"error,omitempty"`
+}
+
+// Ping reports that the server is up, and nothing else
+func (h *Health) Ping() http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		rw.WriteHeader(http.StatusOK)
 	}
 }
+
+// GetLiveness reports that the server is up, even while it is shutting down
+// or its database is unreachable, neither of which a restart would fix
+func (h *Health) GetLiveness() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		writeResponse(rw, http.StatusOK, livenessResponse{
+			Status: models.HealthStatusUp,
+		})
+	}
+}
+
+// GetReadiness reports whether the server is ready for traffic, with the
+// status of each component it depends on. It responds 503 when it isn't.
+func (h *Health) GetReadiness() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		health := h.SHealth.Check(r.Context())
+
+		status := http.StatusOK
+		if health.Status != models.HealthStatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		writeResponse(rw, status, readinessResponse{
+			Status:   health.Status,
+			Draining: health.Draining,
+			Database: readinessResponseDatabase{
+				Status:    health.Database.Status,
+				LatencyMS: float64(health.Database.Latency) / float64(time.Millisecond),
+				Error:     health.Database.Error,
+				Pool: readinessResponsePool{
+					TotalConns:        health.Database.Pool.TotalConns,
+					IdleConns:         health.Database.Pool.IdleConns,
+					AcquiredConns:     health.Database.Pool.AcquiredConns,
+					MaxConns:          health.Database.Pool.MaxConns,
+					AcquireCount:      health.Database.Pool.AcquireCount,
+					EmptyAcquireCount: health.Database.Pool.EmptyAcquireCount,
+				},
+			},
+			JWTKey: readinessResponseJWTKey{
+				Status: health.JWTKey.Status,
+			},
+			Migrations: readinessResponseMigrations{
+				Status:         health.Migrations.Status,
+				CurrentVersion: health.Migrations.CurrentVersion,
+				LatestVersion:  health.Migrations.LatestVersion,
+				Error:          health.Migrations.Error,
+			},
+		})
+	}
+}
@@ -7,39 +7,185 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestHealthGet(t *testing.T) {
+func TestHealthPing(t *testing.T) {
+	h := &controllers.Health{}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/ping", nil)
+
+	h.Ping().ServeHTTP(rw, r)
+
+	assert.Equal(t, http.StatusOK, rw.Result().StatusCode)
+}
+
+func TestHealthGetLiveness(t *testing.T) {
+	h := &controllers.Health{}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/health/live", nil)
+
+	h.GetLiveness().ServeHTTP(rw, r)
+
+	assert.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	resBody, _ := io.ReadAll(rw.Result().Body)
+	assert.JSONEq(t, `{"status": "up"}`, string(resBody))
+}
+
+func TestHealthGetReadiness(t *testing.T) {
+	databaseError := "unavailable"
+	pool := &models.DatabasePool{
+		TotalConns:        4,
+		IdleConns:         3,
+		AcquiredConns:     1,
+		MaxConns:          8,
+		AcquireCount:      120,
+		EmptyAcquireCount: 2,
+	}
+
 	tests := []struct {
 		name                 string
 		endpoint             string
+		mockSetupFunc        func(mh *mockservices.MockIHealth)
 		requestMethod        string
 		requestBody          string
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
-			name:                 "get",
-			endpoint:             "/health",
-			requestMethod:        http.MethodGet,
-			requestBody:          ``,
-			expectedStatusCode:   http.StatusOK,
-			expectedResponseBody: ``,
+			name:     "get - ready",
+			endpoint: "/health/ready",
+			mockSetupFunc: func(mh *mockservices.MockIHealth) {
+				mh.EXPECT().
+					Check(gomock.Any()).
+					Times(1).
+					Return(&models.Health{
+						Status:   models.HealthStatusUp,
+						Draining: false,
+						Database: &models.DatabaseHealth{
+							Status:  models.HealthStatusUp,
+							Latency: 1500 * time.Microsecond,
+							Pool:    pool,
+						},
+						JWTKey: &models.JWTKeyHealth{
+							Status: models.HealthStatusUp,
+						},
+						Migrations: &models.MigrationsHealth{
+							Status:         models.HealthStatusUp,
+							CurrentVersion: 20,
+							LatestVersion:  20,
+						},
+					})
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"status": "up",
+				"draining": false,
+				"database": {
+					"status": "up",
+					"latency_ms": 1.5,
+					"pool": {
+						"total_conns": 4,
+						"idle_conns": 3,
+						"acquired_conns": 1,
+						"max_conns": 8,
+						"acquire_count": 120,
+						"empty_acquire_count": 2
+					}
+				},
+				"jwt_key": {
+					"status": "up"
+				},
+				"migrations": {
+					"status": "up",
+					"current_version": 20,
+					"latest_version": 20
+				}
+			}`,
+		},
+		{
+			name:     "get - database down",
+			endpoint: "/health/ready",
+			mockSetupFunc: func(mh *mockservices.MockIHealth) {
+				mh.EXPECT().
+					Check(gomock.Any()).
+					Times(1).
+					Return(&models.Health{
+						Status:   models.HealthStatusDown,
+						Draining: false,
+						Database: &models.DatabaseHealth{
+							Status:  models.HealthStatusDown,
+							Latency: 2 * time.Second,
+							Error:   &databaseError,
+							Pool:    pool,
+						},
+						JWTKey: &models.JWTKeyHealth{
+							Status: models.HealthStatusUp,
+						},
+						Migrations: &models.MigrationsHealth{
+							Status:        models.HealthStatusDown,
+							LatestVersion: 20,
+							Error:         &databaseError,
+						},
+					})
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedResponseBody: `{
+				"status": "down",
+				"draining": false,
+				"database": {
+					"status": "down",
+					"latency_ms": 2000,
+					"error": "unavailable",
+					"pool": {
+						"total_conns": 4,
+						"idle_conns": 3,
+						"acquired_conns": 1,
+						"max_conns": 8,
+						"acquire_count": 120,
+						"empty_acquire_count": 2
+					}
+				},
+				"jwt_key": {
+					"status": "up"
+				},
+				"migrations": {
+					"status": "down",
+					"current_version": 0,
+					"latest_version": 20,
+					"error": "unavailable"
+				}
+			}`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockHealthService := mockservices.NewMockIHealth(mockCtrl)
 
-			b := &controllers.Health{}
+			tt.mockSetupFunc(mockHealthService)
+
+			h := &controllers.Health{
+				SHealth: mockHealthService,
+			}
 
 			rw := httptest.NewRecorder()
 			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
 
-			b.Get().ServeHTTP(rw, r)
+			h.GetReadiness().ServeHTTP(rw, r)
 
 			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
 			resBody, _ := io.ReadAll(rw.Result().Body)
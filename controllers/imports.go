@@ -0,0 +1,468 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/importers"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Imports struct {
+	SImports      services.IImports
+	SBankAccounts services.IBankAccounts
+	SBudgets      services.IBudgets
+	SUserAccounts services.IUserAccounts
+}
+
+type csvImportRequestMapping struct {
+	Delimiter        string `json:"delimiter"`
+	SkipRows         int    `json:"skip_rows"`
+	DateColumn       string `json:"date_column"`
+	DateFormat       string `json:"date_format"`
+	AmountColumn     string `json:"amount_column"`
+	AmountSign       string `json:"amount_sign"`
+	DebitColumn      string `json:"debit_column"`
+	CreditColumn     string `json:"credit_column"`
+	PayeeColumn      string `json:"payee_column"`
+	MemoColumn       string `json:"memo_column"`
+	DecimalSeparator string `json:"decimal_separator"`
+}
+
+func (m csvImportRequestMapping) toCSVMapping() importers.CSVMapping {
+	return importers.CSVMapping{
+		Delimiter:        m.Delimiter,
+		SkipRows:         m.SkipRows,
+		DateColumn:       m.DateColumn,
+		DateFormat:       m.DateFormat,
+		AmountColumn:     m.AmountColumn,
+		AmountSign:       importers.AmountSign(m.AmountSign),
+		DebitColumn:      m.DebitColumn,
+		CreditColumn:     m.CreditColumn,
+		PayeeColumn:      m.PayeeColumn,
+		MemoColumn:       m.MemoColumn,
+		DecimalSeparator: m.DecimalSeparator,
+	}
+}
+
+type postCSVImportPreviewRequest struct {
+	Data    string                  `json:"data"`
+	Mapping csvImportRequestMapping `json:"mapping"`
+}
+
+type postImportPreviewResponse struct {
+	Rows          []postImportPreviewResponseRow `json:"rows"`
+	LedgerBalance *importBalanceResponse         `json:"ledger_balance,omitempty"`
+}
+
+type postImportPreviewResponseRow struct {
+	Row       int     `json:"row"`
+	Date      string  `json:"date"`
+	Amount    int64   `json:"amount"`
+	Payee     string  `json:"payee"`
+	Memo      *string `json:"memo,omitempty"`
+	FITID     *string `json:"fitid,omitempty"`
+	Duplicate bool    `json:"duplicate"`
+}
+
+type importBalanceResponse struct {
+	Date   string `json:"date"`
+	Amount int64  `json:"amount"`
+}
+
+// PostCSVPreview parses a CSV export and reports which rows would be
+// imported, without creating any transactions
+func (i *Imports) PostCSVPreview() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, i.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, i.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, i.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postCSVImportPreviewRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		minorUnits, err := i.SImports.MinorUnits(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account currency")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		entries, err := importers.ParseCSV(strings.NewReader(requestBody.Data), requestBody.Mapping.toCSVMapping(), minorUnits)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		i.writePreview(r.Context(), rw, bankAccountID, &importers.Statement{
+			Entries: entries,
+		})
+	}
+}
+
+type postCSVImportRequest struct {
+	Data        string                  `json:"data"`
+	Mapping     csvImportRequestMapping `json:"mapping"`
+	ExcludeRows []int                   `json:"exclude_rows"`
+}
+
+type postImportResponse struct {
+	Transactions      []postImportResponseTransaction `json:"transactions"`
+	SkippedDuplicates int                             `json:"skipped_duplicates"`
+	LedgerBalance     *importBalanceResponse          `json:"ledger_balance,omitempty"`
+}
+
+type postImportResponseTransaction struct {
+	ID      string  `json:"id"`
+	Date    string  `json:"date"`
+	Amount  int64   `json:"amount"`
+	Payee   string  `json:"payee"`
+	Memo    *string `json:"memo,omitempty"`
+	Cleared string  `json:"cleared"`
+}
+
+// PostCSV imports the rows of a CSV export as transactions, skipping
+// duplicates and any rows the client excluded after previewing
+func (i *Imports) PostCSV() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, i.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, i.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, i.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postCSVImportRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		minorUnits, err := i.SImports.MinorUnits(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account currency")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		entries, err := importers.ParseCSV(strings.NewReader(requestBody.Data), requestBody.Mapping.toCSVMapping(), minorUnits)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		i.writeImport(r.Context(), rw, bankAccountID, &importers.Statement{
+			Entries: excludeRows(entries, requestBody.ExcludeRows),
+		})
+	}
+}
+
+type postOFXImportPreviewRequest struct {
+	Data string `json:"data"`
+}
+
+// PostOFXPreview parses an OFX or QFX statement and reports which
+// transactions would be imported, without creating any
+func (i *Imports) PostOFXPreview() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, i.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, i.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, i.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postOFXImportPreviewRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		minorUnits, err := i.SImports.MinorUnits(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account currency")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		statement, err := importers.ParseOFX(strings.NewReader(requestBody.Data), minorUnits)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		i.writePreview(r.Context(), rw, bankAccountID, statement)
+	}
+}
+
+type postOFXImportRequest struct {
+	Data        string `json:"data"`
+	ExcludeRows []int  `json:"exclude_rows"`
+}
+
+// PostOFX imports the transactions of an OFX or QFX statement, and records
+// its ledger balance for reconciliation
+func (i *Imports) PostOFX() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, i.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, i.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, i.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postOFXImportRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		minorUnits, err := i.SImports.MinorUnits(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account currency")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		statement, err := importers.ParseOFX(strings.NewReader(requestBody.Data), minorUnits)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+		statement.Entries = excludeRows(statement.Entries, requestBody.ExcludeRows)
+
+		i.writeImport(r.Context(), rw, bankAccountID, statement)
+	}
+}
+
+type postQIFImportPreviewRequest struct {
+	Data      string `json:"data"`
+	DateOrder string `json:"date_order"`
+}
+
+// PostQIFPreview parses a QIF export and reports which transactions would
+// be imported, without creating any
+func (i *Imports) PostQIFPreview() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, i.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, i.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, i.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postQIFImportPreviewRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		minorUnits, err := i.SImports.MinorUnits(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account currency")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		statement, err := importers.ParseQIF(strings.NewReader(requestBody.Data), importers.QIFDateOrder(requestBody.DateOrder), minorUnits)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		i.writePreview(r.Context(), rw, bankAccountID, statement)
+	}
+}
+
+type postQIFImportRequest struct {
+	Data        string `json:"data"`
+	DateOrder   string `json:"date_order"`
+	ExcludeRows []int  `json:"exclude_rows"`
+}
+
+// PostQIF imports the transactions of a QIF export
+func (i *Imports) PostQIF() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, i.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, i.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, i.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postQIFImportRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		minorUnits, err := i.SImports.MinorUnits(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting bank account currency")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		statement, err := importers.ParseQIF(strings.NewReader(requestBody.Data), importers.QIFDateOrder(requestBody.DateOrder), minorUnits)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+		statement.Entries = excludeRows(statement.Entries, requestBody.ExcludeRows)
+
+		i.writeImport(r.Context(), rw, bankAccountID, statement)
+	}
+}
+
+func (i *Imports) writePreview(ctx context.Context, rw http.ResponseWriter, bankAccountID string, statement *importers.Statement) {
+	duplicates, err := i.SImports.FindDuplicates(ctx, bankAccountID, statement.Entries)
+	if err != nil {
+		log.WithError(err).Error("Error finding duplicate transactions")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return
+	}
+
+	response := postImportPreviewResponse{
+		Rows:          []postImportPreviewResponseRow{},
+		LedgerBalance: balanceResponse(statement.LedgerBalance),
+	}
+	for index, entry := range statement.Entries {
+		response.Rows = append(response.Rows, postImportPreviewResponseRow{
+			Row:       index + 1,
+			Date:      entry.Date.Format(constants.DateLayout),
+			Amount:    entry.Amount,
+			Payee:     entry.Payee,
+			Memo:      entry.Memo,
+			FITID:     entry.FITID,
+			Duplicate: duplicates[index],
+		})
+	}
+
+	writeResponse(rw, http.StatusOK, response)
+}
+
+func (i *Imports) writeImport(ctx context.Context, rw http.ResponseWriter, bankAccountID string, statement *importers.Statement) {
+	createdTransactions, skippedEntries, err := i.SImports.Import(ctx, bankAccountID, statement.Entries)
+	if err != nil {
+		log.WithError(err).Error("Error importing transactions")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return
+	}
+	if statement.LedgerBalance != nil {
+		_, err := i.SImports.SaveStatementBalance(ctx, bankAccountID, statement.LedgerBalance)
+		if err != nil {
+			log.WithError(err).Error("Error saving statement balance")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+	}
+
+	response := postImportResponse{
+		Transactions:      []postImportResponseTransaction{},
+		SkippedDuplicates: len(skippedEntries),
+		LedgerBalance:     balanceResponse(statement.LedgerBalance),
+	}
+	for _, transaction := range createdTransactions {
+		response.Transactions = append(response.Transactions, postImportResponseTransaction{
+			ID:      transaction.ID,
+			Date:    transaction.Date.Format(constants.DateLayout),
+			Amount:  transaction.Amount,
+			Payee:   transaction.Payee,
+			Memo:    transaction.Memo,
+			Cleared: string(transaction.Cleared),
+		})
+	}
+
+	writeResponse(rw, http.StatusCreated, response)
+}
+
+func balanceResponse(balance *importers.Balance) *importBalanceResponse {
+	if balance == nil {
+		return nil
+	}
+	return &importBalanceResponse{
+		Date:   balance.Date.Format(constants.DateLayout),
+		Amount: balance.Amount,
+	}
+}
+
+// excludeRows drops the entries with the given preview row numbers
+func excludeRows(entries []*importers.Entry, rows []int) []*importers.Entry {
+	excluded := map[int]bool{}
+	for _, row := range rows {
+		excluded[row] = true
+	}
+
+	included := []*importers.Entry{}
+	for index, entry := range entries {
+		if !excluded[index+1] {
+			included = append(included, entry)
+		}
+	}
+	return included
+}
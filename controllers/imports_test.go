@@ -0,0 +1,625 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	"github.com/paulwrubel/moneybags-server/importers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportsPostCSVPreview(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post preview - success",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/imports/csv/preview",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				minorUnitsCall := mi.EXPECT().
+					MinorUnits(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(2, nil)
+
+				mi.EXPECT().
+					FindDuplicates(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq([]*importers.Entry{
+						{
+							Date:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+							Amount: -4599,
+							Payee:  "Grocery Store",
+						},
+						{
+							Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount: 150000,
+							Payee:  "Employer",
+							Memo:   pointerify("salary"),
+						},
+					})).
+					After(minorUnitsCall).
+					Times(1).
+					Return([]bool{true, false}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"data": "Date,Description,Debit,Credit,Notes\n03/01/2022,Grocery Store,45.99,,\n03/02/2022,Employer,,\"1,500.00\",salary\n",
+				"mapping": {
+					"date_column": "Date",
+					"date_format": "MM/DD/YYYY",
+					"debit_column": "Debit",
+					"credit_column": "Credit",
+					"payee_column": "Description",
+					"memo_column": "Notes"
+				}
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"rows": [
+					{
+						"row": 1,
+						"date": "2022-03-01",
+						"amount": -4599,
+						"payee": "Grocery Store",
+						"duplicate": true
+					},
+					{
+						"row": 2,
+						"date": "2022-03-02",
+						"amount": 150000,
+						"payee": "Employer",
+						"memo": "salary",
+						"duplicate": false
+					}
+				]
+			}`,
+		},
+		{
+			name:     "post preview - failure - missing column",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/imports/csv/preview",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mi.EXPECT().
+					MinorUnits(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(2, nil)
+
+				mi.EXPECT().
+					FindDuplicates(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"data": "Date,Description,Amount\n2022-03-01,Grocery Store,-45.99\n",
+				"mapping": {
+					"date_column": "Posted",
+					"amount_column": "Amount",
+					"payee_column": "Description"
+				}
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "column \"Posted\" not found in header"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockImportsService := mockservices.NewMockIImports(mockCtrl)
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockImportsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			i := &controllers.Imports{
+				SImports:      mockImportsService,
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			i.PostCSVPreview().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestImportsPostCSV(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success - excluded row",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/imports/csv",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				minorUnitsCall := mi.EXPECT().
+					MinorUnits(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(2, nil)
+
+				mi.EXPECT().
+					Import(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq([]*importers.Entry{
+						{
+							Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount: -1250,
+							Payee:  "Coffee Shop",
+						},
+					})).
+					After(minorUnitsCall).
+					Times(1).
+					Return([]*models.Transaction{
+						{
+							ID:            "__tid_1__",
+							BankAccountID: "__baid_1__",
+							Date:          time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount:        -1250,
+							Payee:         "Coffee Shop",
+							Cleared:       models.ClearedStateCleared,
+						},
+					}, []*importers.Entry{}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"data": "Posted;Payee;Amount\n2022-03-01;Card Payment;100,00\n2022-03-02;Coffee Shop;12,50\n",
+				"mapping": {
+					"delimiter": ";",
+					"date_column": "Posted",
+					"amount_column": "Amount",
+					"amount_sign": "outflow_positive",
+					"payee_column": "Payee",
+					"decimal_separator": ","
+				},
+				"exclude_rows": [1]
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"transactions": [
+					{
+						"id": "__tid_1__",
+						"date": "2022-03-02",
+						"amount": -1250,
+						"payee": "Coffee Shop",
+						"cleared": "cleared"
+					}
+				],
+				"skipped_duplicates": 0
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockImportsService := mockservices.NewMockIImports(mockCtrl)
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockImportsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			i := &controllers.Imports{
+				SImports:      mockImportsService,
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			i.PostCSV().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestImportsPostOFX(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success - ledger balance",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/imports/ofx",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				minorUnitsCall := mi.EXPECT().
+					MinorUnits(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(2, nil)
+
+				importCall := mi.EXPECT().
+					Import(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq([]*importers.Entry{
+						{
+							Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount: -1250,
+							Payee:  "Coffee Shop",
+							FITID:  pointerify("F2"),
+						},
+					})).
+					After(minorUnitsCall).
+					Times(1).
+					Return([]*models.Transaction{}, []*importers.Entry{
+						{
+							Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount: -1250,
+							Payee:  "Coffee Shop",
+							FITID:  pointerify("F2"),
+						},
+					}, nil)
+
+				mi.EXPECT().
+					SaveStatementBalance(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq(&importers.Balance{
+						Date:   time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+						Amount: 10000,
+					})).
+					After(importCall).
+					Times(1).
+					Return(&models.StatementBalance{
+						ID:            "__sbid_1__",
+						BankAccountID: "__baid_1__",
+						Date:          time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+						Balance:       10000,
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"data": "<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST><STMTTRN><DTPOSTED>20220301<TRNAMT>100.00<FITID>F1<NAME>Card Payment</STMTTRN><STMTTRN><DTPOSTED>20220302<TRNAMT>-12.50<FITID>F2<NAME>Coffee Shop</STMTTRN></BANKTRANLIST><LEDGERBAL><BALAMT>100.00<DTASOF>20220331</LEDGERBAL></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>",
+				"exclude_rows": [1]
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"transactions": [],
+				"skipped_duplicates": 1,
+				"ledger_balance": {
+					"date": "2022-03-31",
+					"amount": 10000
+				}
+			}`,
+		},
+		{
+			name:     "post - failure - malformed statement",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/imports/ofx",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mi *mockservices.MockIImports, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mi.EXPECT().
+					MinorUnits(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(2, nil)
+			},
+			requestMethod:        http.MethodPost,
+			requestBody:          `{"data": "not a statement"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"errors":[{"message":"missing <OFX> element"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockImportsService := mockservices.NewMockIImports(mockCtrl)
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockImportsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			i := &controllers.Imports{
+				SImports:      mockImportsService,
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			i.PostOFX().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
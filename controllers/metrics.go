@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metrics struct {
+	Gatherer prometheus.Gatherer
+}
+
+// Get serves the metrics in the Prometheus text format. Metrics which can't
+// be gathered are left out rather than failing the whole scrape.
+func (m *Metrics) Get() http.HandlerFunc {
+	return promhttp.HandlerFor(m.Gatherer, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	}).ServeHTTP
+}
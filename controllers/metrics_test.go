@@ -0,0 +1,34 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/paulwrubel/moneybags-server/controllers"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsGet(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test_events_total",
+		Help: "Events.",
+	})
+	registry.MustRegister(counter)
+	counter.Add(3)
+
+	m := &controllers.Metrics{
+		Gatherer: registry,
+	}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+
+	m.Get().ServeHTTP(rw, r)
+
+	assert.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	assert.Contains(t, rw.Result().Header.Get("Content-Type"), "text/plain")
+	assert.Contains(t, rw.Body.String(), "test_events_total 3")
+}
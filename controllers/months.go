@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Months struct {
+	SMonths       services.IMonths
+	SCategories   services.ICategories
+	SBudgets      services.IBudgets
+	SUserAccounts services.IUserAccounts
+}
+
+type getMonthResponse struct {
+	Month             string                     `json:"month"`
+	AvailableToBudget int64                      `json:"available_to_budget"`
+	Categories        []getMonthResponseCategory `json:"categories"`
+}
+
+type getMonthResponseCategory struct {
+	CategoryID string `json:"category_id"`
+	Assigned   int64  `json:"assigned"`
+	Activity   int64  `json:"activity"`
+	Available  int64  `json:"available"`
+}
+
+func (m *Months) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, m.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, m.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		month, err := time.Parse(constants.MonthLayout, mux.Vars(r)["month"])
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidMonth))
+			return
+		}
+
+		budgetMonth, err := m.SMonths.Get(r.Context(), budgetID, month)
+		switch err {
+		case constants.ErrNoExchangeRate:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error getting budget month")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getMonthResponse{
+			Month:             budgetMonth.Month.Format(constants.MonthLayout),
+			AvailableToBudget: budgetMonth.AvailableToBudget,
+			Categories:        []getMonthResponseCategory{},
+		}
+		for _, categoryMonth := range budgetMonth.Categories {
+			response.Categories = append(response.Categories, getMonthResponseCategory{
+				CategoryID: categoryMonth.CategoryID,
+				Assigned:   categoryMonth.Assigned,
+				Activity:   categoryMonth.Activity,
+				Available:  categoryMonth.Available,
+			})
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+type putMonthCategoryRequest struct {
+	Assigned int64 `json:"assigned"`
+}
+
+type putMonthCategoryResponse struct {
+	CategoryID string `json:"category_id"`
+	Assigned   int64  `json:"assigned"`
+	Activity   int64  `json:"activity"`
+	Available  int64  `json:"available"`
+}
+
+func (m *Months) PutCategory() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, m.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		categoryID := mux.Vars(r)["categoryID"]
+
+		if !validateBudget(r.Context(), rw, m.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateCategory(r.Context(), rw, m.SCategories, budgetID, categoryID) {
+			return
+		}
+
+		month, err := time.Parse(constants.MonthLayout, mux.Vars(r)["month"])
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidMonth))
+			return
+		}
+
+		var requestBody putMonthCategoryRequest
+		err = unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		err = m.SMonths.SetAssigned(r.Context(), categoryID, month, requestBody.Assigned)
+		if err != nil {
+			log.WithError(err).Error("Error setting assigned amount")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		budgetMonth, err := m.SMonths.Get(r.Context(), budgetID, month)
+		switch err {
+		case constants.ErrNoExchangeRate:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error getting budget month")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := putMonthCategoryResponse{
+			CategoryID: categoryID,
+			Assigned:   requestBody.Assigned,
+		}
+		for _, categoryMonth := range budgetMonth.Categories {
+			if categoryMonth.CategoryID == categoryID {
+				response.Activity = categoryMonth.Activity
+				response.Available = categoryMonth.Available
+			}
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,191 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthsGet(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mm *mockservices.MockIMonths, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "get - success",
+			endpoint: "/api/v1/budgets/__bid_1__/months/2022-03",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+					"month":    "2022-03",
+				})
+				return r
+			},
+			mockSetupFunc: func(mm *mockservices.MockIMonths, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				mm.EXPECT().
+					Get(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq(time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC))).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.BudgetMonth{
+						BudgetID:          "__bid_1__",
+						Month:             time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+						AvailableToBudget: 25000,
+						Categories: []*models.CategoryMonth{
+							{
+								CategoryID: "__cid_1__",
+								Assigned:   50000,
+								Activity:   -12345,
+								Available:  37655,
+							},
+						},
+					}, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"month": "2022-03",
+				"available_to_budget": 25000,
+				"categories": [
+					{
+						"category_id": "__cid_1__",
+						"assigned": 50000,
+						"activity": -12345,
+						"available": 37655
+					}
+				]
+			}`,
+		},
+		{
+			name:     "get - failure - invalid month",
+			endpoint: "/api/v1/budgets/__bid_1__/months/march",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+					"month":    "march",
+				})
+				return r
+			},
+			mockSetupFunc: func(mm *mockservices.MockIMonths, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				mm.EXPECT().
+					Get(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid month"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMonthsService := mockservices.NewMockIMonths(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockMonthsService, mockBudgetsService, mockUserAccountsService)
+
+			m := &controllers.Months{
+				SMonths:       mockMonthsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			m.Get().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type OpenAPI struct {
+	Document []byte
+}
+
+func (o *OpenAPI) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write(o.Document)
+		if err != nil {
+			log.WithError(err).Error("Error writing response")
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// documentedBodies are the request and response bodies of an operation. A nil
+// body is one the operation does not have, and a slice of response bodies is
+// one of several shapes.
+type documentedBodies struct {
+	request  interface{}
+	response interface{}
+}
+
+var operationBodies = map[string]documentedBodies{
+	"GET /ping":                {},
+	"GET /health":              {response: livenessResponse{}},
+	"GET /health/live":         {response: livenessResponse{}},
+	"GET /health/ready":        {response: readinessResponse{}},
+	"GET /api/v1/openapi.json": {response: map[string]interface{}{}},
+
+	"POST /api/v1/user-accounts":                        {postUserAccountRequest{}, postUserAccountResponse{}},
+	"GET /api/v1/user-accounts":                         {nil, getUserAccountResponse{}},
+	"PUT /api/v1/user-accounts/password":                {putPasswordRequest{}, nil},
+	"POST /api/v1/user-accounts/password-reset":         {postPasswordResetRequest{}, nil},
+	"POST /api/v1/user-accounts/password-reset/confirm": {postPasswordResetConfirmRequest{}, nil},
+
+	"GET /api/v1/user-accounts/2fa/totp":                 {nil, getTOTPResponse{}},
+	"POST /api/v1/user-accounts/2fa/totp":                {nil, postTOTPEnrollmentResponse{}},
+	"DELETE /api/v1/user-accounts/2fa/totp":              {deleteTOTPRequest{}, nil},
+	"POST /api/v1/user-accounts/2fa/totp/verify":         {postTOTPVerificationRequest{}, postTOTPVerificationResponse{}},
+	"POST /api/v1/user-accounts/2fa/totp/recovery-codes": {postTOTPRecoveryCodesRequest{}, postTOTPRecoveryCodesResponse{}},
+
+	"GET /api/v1/user-accounts/api-keys":               {nil, getAllAPIKeysResponse{}},
+	"POST /api/v1/user-accounts/api-keys":              {postAPIKeyRequest{}, postAPIKeyResponse{}},
+	"DELETE /api/v1/user-accounts/api-keys/{apiKeyID}": {},
+
+	"POST /api/v1/auth/token":           {postLoginRequest{}, []interface{}{postLoginResponse{}, postLoginChallengeResponse{}}},
+	"POST /api/v1/auth/token/challenge": {postTokenChallengeRequest{}, postTokenChallengeResponse{}},
+	"POST /api/v1/auth/refresh":         {postRefreshRequest{}, postRefreshResponse{}},
+	"POST /api/v1/auth/logout":          {},
+	"POST /api/v1/auth/logout-all":      {},
+
+	"GET /api/v1/budgets":               {nil, getAllBudgetsResponse{}},
+	"POST /api/v1/budgets":              {postBudgetRequest{}, postBudgetResponse{}},
+	"GET /api/v1/budgets/{budgetID}":    {nil, getBudgetResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}":  {patchBudgetRequest{}, patchBudgetResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}": {},
+
+	"GET /api/v1/budgets/{budgetID}/members":                    {nil, getAllBudgetMembershipsResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/members/{userAccountID}":  {patchBudgetMembershipRequest{}, budgetMembershipResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/members/{userAccountID}": {},
+
+	"GET /api/v1/budgets/{budgetID}/invitations":                   {nil, getAllBudgetInvitationsResponse{}},
+	"POST /api/v1/budgets/{budgetID}/invitations":                  {postBudgetInvitationRequest{}, budgetInvitationResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/invitations/{invitationID}": {},
+	"GET /api/v1/invitations":                                      {nil, getAllBudgetInvitationsResponse{}},
+	"DELETE /api/v1/invitations/{invitationID}":                    {},
+	"POST /api/v1/invitations/{invitationID}/accept":               {},
+
+	"GET /api/v1/budgets/{budgetID}/bank-accounts":                         {nil, getAllBankAccountsResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts":                        {postBankAccountRequest{}, postBankAccountResponse{}},
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}":         {nil, getBankAccountResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}":       {patchBankAccountRequest{}, patchBankAccountResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}":      {},
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/balance": {nil, getBankAccountBalanceResponse{}},
+
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/transactions":                    {nil, getAllTransactionsResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/transactions":                   {postTransactionRequest{}, postTransactionResponse{}},
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/transactions/{transactionID}":    {nil, getTransactionResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/transactions/{transactionID}":  {patchTransactionRequest{}, patchTransactionResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/transactions/{transactionID}": {},
+
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports/csv/preview": {postCSVImportPreviewRequest{}, postImportPreviewResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports/csv":         {postCSVImportRequest{}, postImportResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports/ofx/preview": {postOFXImportPreviewRequest{}, postImportPreviewResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports/ofx":         {postOFXImportRequest{}, postImportResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports/qif/preview": {postQIFImportPreviewRequest{}, postImportPreviewResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports/qif":         {postQIFImportRequest{}, postImportResponse{}},
+
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions":                             {nil, getAllScheduledTransactionsResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions":                            {postScheduledTransactionRequest{}, scheduledTransactionResponse{}},
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions/upcoming":                    {nil, getUpcomingScheduledTransactionsResponse{}},
+	"GET /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions/{scheduledTransactionID}":    {nil, scheduledTransactionResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions/{scheduledTransactionID}":  {patchScheduledTransactionRequest{}, scheduledTransactionResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions/{scheduledTransactionID}": {},
+
+	"POST /api/v1/budgets/{budgetID}/transfers":                {postTransferRequest{}, transferResponse{}},
+	"GET /api/v1/budgets/{budgetID}/transfers/{transferID}":    {nil, transferResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/transfers/{transferID}":  {patchTransferRequest{}, transferResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/transfers/{transferID}": {},
+
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/reconciliation/preview": {postReconciliationPreviewRequest{}, reconciliationResponse{}},
+	"POST /api/v1/budgets/{budgetID}/bank-accounts/{bankAccountID}/reconciliation":         {postReconciliationRequest{}, reconciliationResponse{}},
+
+	"GET /api/v1/budgets/{budgetID}/categories":                 {nil, getAllCategoriesResponse{}},
+	"POST /api/v1/budgets/{budgetID}/categories":                {postCategoryRequest{}, postCategoryResponse{}},
+	"POST /api/v1/budgets/{budgetID}/categories/groups":         {postCategoryGroupRequest{}, postCategoryGroupResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/categories/{categoryID}":  {patchCategoryRequest{}, patchCategoryResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/categories/{categoryID}": {},
+
+	"GET /api/v1/budgets/{budgetID}/payees":                  {nil, getAllPayeesResponse{}},
+	"POST /api/v1/budgets/{budgetID}/payees":                 {postPayeeRequest{}, payeeResponse{}},
+	"GET /api/v1/budgets/{budgetID}/payees/{payeeID}":        {nil, payeeResponse{}},
+	"PATCH /api/v1/budgets/{budgetID}/payees/{payeeID}":      {patchPayeeRequest{}, payeeResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/payees/{payeeID}":     {},
+	"POST /api/v1/budgets/{budgetID}/payees/{payeeID}/merge": {postPayeeMergeRequest{}, payeeResponse{}},
+
+	"GET /api/v1/budgets/{budgetID}/categorization-rules":             {nil, getAllCategorizationRulesResponse{}},
+	"POST /api/v1/budgets/{budgetID}/categorization-rules":            {categorizationRuleRequest{}, categorizationRuleResponse{}},
+	"PUT /api/v1/budgets/{budgetID}/categorization-rules/{ruleID}":    {categorizationRuleRequest{}, categorizationRuleResponse{}},
+	"DELETE /api/v1/budgets/{budgetID}/categorization-rules/{ruleID}": {},
+
+	"GET /api/v1/budgets/{budgetID}/months/{month}":                         {nil, getMonthResponse{}},
+	"PUT /api/v1/budgets/{budgetID}/months/{month}/categories/{categoryID}": {putMonthCategoryRequest{}, putMonthCategoryResponse{}},
+}
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas   map[string]*openAPISchema `json:"schemas"`
+		Responses map[string]struct {
+			Content map[string]openAPIMediaType `json:"content"`
+		} `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	RequestBody *struct {
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Ref     string                      `json:"$ref"`
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"responses"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Format     string                    `json:"format"`
+	Required   []string                  `json:"required"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	OneOf      []*openAPISchema          `json:"oneOf"`
+}
+
+func TestOpenAPIBodies(t *testing.T) {
+	document := &openAPIDocument{}
+	err := json.Unmarshal(openapi.Document, document)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range document.Paths {
+		for method, operation := range operations {
+			name := strings.ToUpper(method) + " " + path
+			documented[name] = true
+
+			bodies, ok := operationBodies[name]
+			if !assert.True(t, ok, "%s has no bodies to check against", name) {
+				continue
+			}
+
+			if bodies.request == nil {
+				assert.Nil(t, operation.RequestBody, "%s documents a request body", name)
+			} else if assert.NotNil(t, operation.RequestBody, "%s documents no request body", name) {
+				schema := operation.RequestBody.Content["application/json"].Schema
+				assertSchemaMatches(t, document, schema, reflect.TypeOf(bodies.request), false, name+" request")
+			}
+
+			for status, response := range operation.Responses {
+				code := status[0]
+				// errors are shared, unless they respond with the same body
+				// as success
+				if code != '2' {
+					if response.Ref != "" {
+						continue
+					}
+					if !assert.NotNil(t, bodies.response, "%s documents a %s response which is not shared", name, status) {
+						continue
+					}
+				}
+
+				media, hasContent := response.Content["application/json"]
+				switch response := bodies.response.(type) {
+				case nil:
+					assert.False(t, hasContent, "%s documents a %s response body", name, status)
+				case []interface{}:
+					if assert.True(t, hasContent, "%s documents no %s response body", name, status) &&
+						assert.Len(t, media.Schema.OneOf, len(response), "%s response", name) {
+						for i, shape := range response {
+							assertSchemaMatches(t, document, media.Schema.OneOf[i], reflect.TypeOf(shape), true, name+" response")
+						}
+					}
+				default:
+					if assert.True(t, hasContent, "%s documents no %s response body", name, status) {
+						assertSchemaMatches(t, document, media.Schema, reflect.TypeOf(response), true, name+" response")
+					}
+				}
+			}
+		}
+	}
+
+	for name := range operationBodies {
+		assert.True(t, documented[name], "%s is not documented", name)
+	}
+
+	for name, response := range document.Components.Responses {
+		if media, ok := response.Content["application/json"]; ok {
+			assertSchemaMatches(t, document, media.Schema, reflect.TypeOf(errorsResponse{}), true, name+" response")
+		}
+	}
+}
+
+func TestOpenAPIGet(t *testing.T) {
+	o := &OpenAPI{
+		Document: openapi.Document,
+	}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+
+	o.Get().ServeHTTP(rw, r)
+
+	assert.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	assert.Equal(t, "application/json", rw.Result().Header.Get("Content-Type"))
+	assert.Equal(t, openapi.Document, rw.Body.Bytes())
+}
+
+// assertSchemaMatches asserts that values of typ marshal to JSON described by
+// schema. Response schemas must require exactly the fields which are always
+// present, and request schemas may only require fields which exist.
+func assertSchemaMatches(t *testing.T, document *openAPIDocument, schema *openAPISchema, typ reflect.Type, response bool, path string) {
+	if !assert.NotNil(t, schema, "%s has no schema", path) {
+		return
+	}
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := document.Components.Schemas[name]
+		if !assert.True(t, ok, "%s refers to missing schema %s", path, name) {
+			return
+		}
+		schema = resolved
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		assert.Equal(t, "string", schema.Type, path)
+		assert.Equal(t, "date-time", schema.Format, path)
+	case typ.Kind() == reflect.String:
+		assert.Equal(t, "string", schema.Type, path)
+	case typ.Kind() == reflect.Int || typ.Kind() == reflect.Int32 || typ.Kind() == reflect.Int64:
+		assert.Equal(t, "integer", schema.Type, path)
+	case typ.Kind() == reflect.Float64:
+		assert.Equal(t, "number", schema.Type, path)
+	case typ.Kind() == reflect.Bool:
+		assert.Equal(t, "boolean", schema.Type, path)
+	case typ.Kind() == reflect.Slice:
+		if assert.Equal(t, "array", schema.Type, path) {
+			assertSchemaMatches(t, document, schema.Items, typ.Elem(), response, path+"[]")
+		}
+	case typ.Kind() == reflect.Map:
+		assert.Equal(t, "object", schema.Type, path)
+	case typ.Kind() == reflect.Struct:
+		if !assert.Equal(t, "object", schema.Type, path) {
+			return
+		}
+
+		properties := []string{}
+		required := []string{}
+		for name, fieldType := range jsonFields(typ, &required) {
+			properties = append(properties, name)
+			property, ok := schema.Properties[name]
+			if assert.True(t, ok, "%s has no property %s", path, name) {
+				assertSchemaMatches(t, document, property, fieldType, response, path+"."+name)
+			}
+		}
+
+		documentedProperties := []string{}
+		for name := range schema.Properties {
+			documentedProperties = append(documentedProperties, name)
+		}
+		sort.Strings(properties)
+		sort.Strings(documentedProperties)
+		assert.Equal(t, properties, documentedProperties, "%s properties", path)
+
+		documentedRequired := append([]string{}, schema.Required...)
+		sort.Strings(required)
+		sort.Strings(documentedRequired)
+		if response {
+			assert.Equal(t, required, documentedRequired, "%s required properties", path)
+		} else {
+			assert.Subset(t, properties, documentedRequired, "%s required properties", path)
+		}
+	default:
+		t.Errorf("%s has unexpected type %s", path, typ)
+	}
+}
+
+// jsonFields returns the types of the fields of a struct by their JSON names,
+// including those of embedded structs, and appends the names of the fields
+// which are never omitted to required
+func jsonFields(typ reflect.Type, required *[]string) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			for name, fieldType := range jsonFields(field.Type, required) {
+				fields[name] = fieldType
+			}
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		name := options[0]
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+		omitEmpty := false
+		for _, option := range options[1:] {
+			omitEmpty = omitEmpty || option == "omitempty"
+		}
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Payees struct {
+	SPayees       services.IPayees
+	SCategories   services.ICategories
+	SBudgets      services.IBudgets
+	SUserAccounts services.IUserAccounts
+}
+
+type payeeResponse struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name"`
+	DefaultCategoryID *string `json:"default_category_id,omitempty"`
+}
+
+type getAllPayeesResponse struct {
+	Payees []payeeResponse `json:"payees"`
+}
+
+func (p *Payees) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, p.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, p.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+
+		payees, err := p.SPayees.GetAll(r.Context(), budgetID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all payees")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getAllPayeesResponse{
+			Payees: []payeeResponse{},
+		}
+		for _, payee := range payees {
+			response.Payees = append(response.Payees, newPayeeResponse(payee))
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+func (p *Payees) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, p.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		payeeID := mux.Vars(r)["payeeID"]
+
+		if !validateBudget(r.Context(), rw, p.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validatePayee(r.Context(), rw, p.SPayees, budgetID, payeeID) {
+			return
+		}
+
+		payee, err := p.SPayees.GetByID(r.Context(), payeeID)
+		if err != nil {
+			log.WithError(err).Error("Error getting payee")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newPayeeResponse(payee))
+	}
+}
+
+type postPayeeRequest struct {
+	Name              string  `json:"name"`
+	DefaultCategoryID *string `json:"default_category_id"`
+}
+
+func (p *Payees) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, p.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, p.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+
+		var requestBody postPayeeRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		if requestBody.DefaultCategoryID != nil && !validateCategory(r.Context(), rw, p.SCategories, budgetID, *requestBody.DefaultCategoryID) {
+			return
+		}
+
+		exists, err := p.SPayees.ExistsByBudgetIDAndName(r.Context(), budgetID, strings.TrimSpace(requestBody.Name))
+		if err != nil {
+			log.WithError(err).Error("Error checking if payee exists")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+		if exists {
+			writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Payee already exists"))
+			return
+		}
+
+		createdPayee, err := p.SPayees.Create(r.Context(), budgetID, requestBody.Name, requestBody.DefaultCategoryID)
+		switch err {
+		case constants.ErrInvalidPayeeName:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating payee")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, newPayeeResponse(createdPayee))
+	}
+}
+
+// patchPayeeRequest treats an empty default_category_id as a request to
+// remove the payee's default category
+type patchPayeeRequest struct {
+	Name              *string `json:"name"`
+	DefaultCategoryID *string `json:"default_category_id"`
+}
+
+func (p *Payees) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, p.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		payeeID := mux.Vars(r)["payeeID"]
+
+		if !validateBudget(r.Context(), rw, p.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validatePayee(r.Context(), rw, p.SPayees, budgetID, payeeID) {
+			return
+		}
+
+		var requestBody patchPayeeRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		payee, err := p.SPayees.GetByID(r.Context(), payeeID)
+		if err != nil {
+			log.WithError(err).Error("Error getting payee")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		// changing only the case of a name does not clash with the payee itself
+		if requestBody.Name != nil && !strings.EqualFold(strings.TrimSpace(*requestBody.Name), payee.Name) {
+			exists, err := p.SPayees.ExistsByBudgetIDAndName(r.Context(), budgetID, strings.TrimSpace(*requestBody.Name))
+			if err != nil {
+				log.WithError(err).Error("Error checking if payee exists")
+				writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+				return
+			}
+			if exists {
+				writeResponse(rw, http.StatusConflict, errorsResponseFromMessages("Payee already exists"))
+				return
+			}
+		}
+		if requestBody.Name != nil {
+			payee.Name = *requestBody.Name
+		}
+		if requestBody.DefaultCategoryID != nil {
+			if *requestBody.DefaultCategoryID == "" {
+				payee.DefaultCategoryID = nil
+			} else {
+				if !validateCategory(r.Context(), rw, p.SCategories, budgetID, *requestBody.DefaultCategoryID) {
+					return
+				}
+				payee.DefaultCategoryID = requestBody.DefaultCategoryID
+			}
+		}
+
+		updatedPayee, err := p.SPayees.Update(r.Context(), payee)
+		switch err {
+		case constants.ErrInvalidPayeeName:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating payee")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newPayeeResponse(updatedPayee))
+	}
+}
+
+type postPayeeMergeRequest struct {
+	TargetPayeeID string `json:"target_payee_id"`
+}
+
+// PostMerge merges the payee into the target payee, moving its transactions
+// across and deleting it. The target payee is returned.
+func (p *Payees) PostMerge() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, p.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		payeeID := mux.Vars(r)["payeeID"]
+
+		if !validateBudget(r.Context(), rw, p.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validatePayee(r.Context(), rw, p.SPayees, budgetID, payeeID) {
+			return
+		}
+
+		var requestBody postPayeeMergeRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		if !validatePayee(r.Context(), rw, p.SPayees, budgetID, requestBody.TargetPayeeID) {
+			return
+		}
+
+		mergedPayee, err := p.SPayees.Merge(r.Context(), payeeID, requestBody.TargetPayeeID)
+		switch err {
+		case constants.ErrInvalidPayeeMerge:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error merging payees")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newPayeeResponse(mergedPayee))
+	}
+}
+
+func (p *Payees) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, p.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		payeeID := mux.Vars(r)["payeeID"]
+
+		if !validateBudget(r.Context(), rw, p.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validatePayee(r.Context(), rw, p.SPayees, budgetID, payeeID) {
+			return
+		}
+
+		err := p.SPayees.Delete(r.Context(), payeeID)
+		if err != nil {
+			log.WithError(err).Error("Error deleting payee")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newPayeeResponse(payee *models.Payee) payeeResponse {
+	return payeeResponse{
+		ID:                payee.ID,
+		Name:              payee.Name,
+		DefaultCategoryID: payee.DefaultCategoryID,
+	}
+}
@@ -0,0 +1,302 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayeesPostMerge(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mp *mockservices.MockIPayees, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post merge - success",
+			endpoint: "/api/v1/budgets/__bid_1__/payees/__pid_1__/merge",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+					"payeeID":  "__pid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mp *mockservices.MockIPayees, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				payeeExistsCall := mp.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__pid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				payeeBelongsToCall := mp.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__pid_1__")).
+					After(payeeExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				targetExistsCall := mp.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__pid_2__")).
+					After(payeeBelongsToCall).
+					Times(1).
+					Return(true, nil)
+
+				targetBelongsToCall := mp.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__pid_2__")).
+					After(targetExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mp.EXPECT().
+					Merge(gomock.Any(), gomock.Eq("__pid_1__"), gomock.Eq("__pid_2__")).
+					After(targetBelongsToCall).
+					Times(1).
+					Return(&models.Payee{
+						ID:                "__pid_2__",
+						BudgetID:          "__bid_1__",
+						Name:              "Amazon",
+						DefaultCategoryID: pointerify("__cid_1__"),
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"target_payee_id": "__pid_2__"
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"id": "__pid_2__",
+				"name": "Amazon",
+				"default_category_id": "__cid_1__"
+			}`,
+		},
+		{
+			name:     "post merge - failure - target payee in another budget",
+			endpoint: "/api/v1/budgets/__bid_1__/payees/__pid_1__/merge",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+					"payeeID":  "__pid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mp *mockservices.MockIPayees, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				payeeExistsCall := mp.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__pid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				payeeBelongsToCall := mp.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__pid_1__")).
+					After(payeeExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				targetExistsCall := mp.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__pid_2__")).
+					After(payeeBelongsToCall).
+					Times(1).
+					Return(true, nil)
+
+				mp.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__pid_2__")).
+					After(targetExistsCall).
+					Times(1).
+					Return(false, nil)
+
+				mp.EXPECT().
+					Merge(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"target_payee_id": "__pid_2__"
+			}`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"errors":[{"message":"Payee does not exist"}]}`,
+		},
+		{
+			name:     "post merge - failure - merge into itself",
+			endpoint: "/api/v1/budgets/__bid_1__/payees/__pid_1__/merge",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+					"payeeID":  "__pid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mp *mockservices.MockIPayees, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				payeeExistsCall := mp.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__pid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				payeeBelongsToCall := mp.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__pid_1__")).
+					After(payeeExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				targetExistsCall := mp.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__pid_1__")).
+					After(payeeBelongsToCall).
+					Times(1).
+					Return(true, nil)
+
+				targetBelongsToCall := mp.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__pid_1__")).
+					After(targetExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mp.EXPECT().
+					Merge(gomock.Any(), gomock.Eq("__pid_1__"), gomock.Eq("__pid_1__")).
+					After(targetBelongsToCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidPayeeMerge)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"target_payee_id": "__pid_1__"
+			}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"errors":[{"message":"a payee cannot be merged into itself"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockPayeesService := mockservices.NewMockIPayees(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockPayeesService, mockBudgetsService, mockUserAccountsService)
+
+			pc := &controllers.Payees{
+				SPayees:       mockPayeesService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			pc.PostMerge().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
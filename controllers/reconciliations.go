@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Reconciliations struct {
+	SReconciliations services.IReconciliations
+	SBankAccounts    services.IBankAccounts
+	SBudgets         services.IBudgets
+	SUserAccounts    services.IUserAccounts
+}
+
+type reconciliationResponse struct {
+	StatementDate    string                              `json:"statement_date"`
+	StatementBalance int64                               `json:"statement_balance"`
+	ClearedBalance   int64                               `json:"cleared_balance"`
+	Difference       int64                               `json:"difference"`
+	Transactions     []reconciliationResponseTransaction `json:"transactions"`
+	Adjustment       *reconciliationResponseTransaction  `json:"adjustment,omitempty"`
+}
+
+type reconciliationResponseTransaction struct {
+	ID         string  `json:"id"`
+	CategoryID *string `json:"category_id,omitempty"`
+	Date       string  `json:"date"`
+	Amount     int64   `json:"amount"`
+	Payee      string  `json:"payee"`
+	Memo       *string `json:"memo,omitempty"`
+	Cleared    string  `json:"cleared"`
+}
+
+// postReconciliationPreviewRequest may leave out both the statement date and
+// balance to use the latest balance recorded by a statement import
+type postReconciliationPreviewRequest struct {
+	StatementDate    *string `json:"statement_date"`
+	StatementBalance *int64  `json:"statement_balance"`
+}
+
+// PostPreview shows the difference between the bank account's cleared
+// balance and a statement balance, without reconciling anything
+func (rc *Reconciliations) PostPreview() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, rc.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, rc.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, rc.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postReconciliationPreviewRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		statementDate, statementBalance, ok := rc.resolveStatement(r.Context(), rw, bankAccountID, requestBody.StatementDate, requestBody.StatementBalance)
+		if !ok {
+			return
+		}
+
+		reconciliation, err := rc.SReconciliations.Preview(r.Context(), bankAccountID, statementDate, statementBalance)
+		if err != nil {
+			log.WithError(err).Error("Error previewing reconciliation")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newReconciliationResponse(reconciliation))
+	}
+}
+
+type postReconciliationRequest struct {
+	StatementDate    *string `json:"statement_date"`
+	StatementBalance *int64  `json:"statement_balance"`
+	CreateAdjustment bool    `json:"create_adjustment"`
+}
+
+// Post reconciles the bank account against a statement balance, locking its
+// cleared transactions. A difference is refused unless create_adjustment is
+// set.
+func (rc *Reconciliations) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, rc.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, rc.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, rc.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postReconciliationRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		statementDate, statementBalance, ok := rc.resolveStatement(r.Context(), rw, bankAccountID, requestBody.StatementDate, requestBody.StatementBalance)
+		if !ok {
+			return
+		}
+
+		reconciliation, err := rc.SReconciliations.Commit(r.Context(), bankAccountID, statementDate, statementBalance, requestBody.CreateAdjustment)
+		switch err {
+		case constants.ErrReconciliationUnbalanced:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error committing reconciliation")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newReconciliationResponse(reconciliation))
+	}
+}
+
+// resolveStatement parses the statement date and balance of a request,
+// falling back to the latest imported statement balance when neither is
+// given. It writes an error response and returns false if that fails.
+func (rc *Reconciliations) resolveStatement(ctx context.Context, rw http.ResponseWriter, bankAccountID string, date *string, balance *int64) (time.Time, int64, bool) {
+	if date == nil && balance == nil {
+		statementBalance, err := rc.SReconciliations.GetLatestStatementBalance(ctx, bankAccountID)
+		switch err {
+		case constants.ErrNoStatementBalance:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromMessages("statement_date and statement_balance are required"))
+			return time.Time{}, 0, false
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error getting latest statement balance")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return time.Time{}, 0, false
+		}
+		return statementBalance.Date, statementBalance.Balance, true
+	}
+	if date == nil || balance == nil {
+		writeResponse(rw, http.StatusBadRequest, errorsResponseFromMessages("statement_date and statement_balance are required"))
+		return time.Time{}, 0, false
+	}
+
+	statementDate, err := time.Parse(constants.DateLayout, *date)
+	if err != nil {
+		writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+		return time.Time{}, 0, false
+	}
+
+	return statementDate, *balance, true
+}
+
+func newReconciliationResponse(reconciliation *models.Reconciliation) reconciliationResponse {
+	response := reconciliationResponse{
+		StatementDate:    reconciliation.StatementDate.Format(constants.DateLayout),
+		StatementBalance: reconciliation.StatementBalance,
+		ClearedBalance:   reconciliation.ClearedBalance,
+		Difference:       reconciliation.Difference,
+		Transactions:     []reconciliationResponseTransaction{},
+	}
+	for _, transaction := range reconciliation.Transactions {
+		response.Transactions = append(response.Transactions, newReconciliationResponseTransaction(transaction))
+	}
+	if reconciliation.Adjustment != nil {
+		adjustment := newReconciliationResponseTransaction(reconciliation.Adjustment)
+		response.Adjustment = &adjustment
+	}
+
+	return response
+}
+
+func newReconciliationResponseTransaction(transaction *models.Transaction) reconciliationResponseTransaction {
+	return reconciliationResponseTransaction{
+		ID:         transaction.ID,
+		CategoryID: transaction.CategoryID,
+		Date:       transaction.Date.Format(constants.DateLayout),
+		Amount:     transaction.Amount,
+		Payee:      transaction.Payee,
+		Memo:       transaction.Memo,
+		Cleared:    string(transaction.Cleared),
+	}
+}
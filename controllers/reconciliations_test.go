@@ -0,0 +1,406 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationsPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mr *mockservices.MockIReconciliations, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success - adjustment",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/reconciliation",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mr *mockservices.MockIReconciliations, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mr.EXPECT().
+					Commit(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq(time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC)), gomock.Eq(int64(10000)), gomock.Eq(true)).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(&models.Reconciliation{
+						BankAccountID:    "__baid_1__",
+						StatementDate:    time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+						StatementBalance: 10000,
+						ClearedBalance:   10000,
+						Difference:       0,
+						Transactions: []*models.Transaction{
+							{
+								ID:            "__tid_1__",
+								BankAccountID: "__baid_1__",
+								Date:          time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+								Amount:        9950,
+								Payee:         "Paycheck",
+								Cleared:       models.ClearedStateReconciled,
+							},
+							{
+								ID:            "__tid_2__",
+								BankAccountID: "__baid_1__",
+								Date:          time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+								Amount:        50,
+								Payee:         constants.ReconciliationAdjustmentPayee,
+								Cleared:       models.ClearedStateReconciled,
+							},
+						},
+						Adjustment: &models.Transaction{
+							ID:            "__tid_2__",
+							BankAccountID: "__baid_1__",
+							Date:          time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+							Amount:        50,
+							Payee:         constants.ReconciliationAdjustmentPayee,
+							Cleared:       models.ClearedStateReconciled,
+						},
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"statement_date": "2022-03-31",
+				"statement_balance": 10000,
+				"create_adjustment": true
+			}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"statement_date": "2022-03-31",
+				"statement_balance": 10000,
+				"cleared_balance": 10000,
+				"difference": 0,
+				"transactions": [
+					{
+						"id": "__tid_1__",
+						"date": "2022-03-02",
+						"amount": 9950,
+						"payee": "Paycheck",
+						"cleared": "reconciled"
+					},
+					{
+						"id": "__tid_2__",
+						"date": "2022-03-31",
+						"amount": 50,
+						"payee": "Reconciliation Balance Adjustment",
+						"cleared": "reconciled"
+					}
+				],
+				"adjustment": {
+					"id": "__tid_2__",
+					"date": "2022-03-31",
+					"amount": 50,
+					"payee": "Reconciliation Balance Adjustment",
+					"cleared": "reconciled"
+				}
+			}`,
+		},
+		{
+			name:     "post - success - latest statement balance",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/reconciliation",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mr *mockservices.MockIReconciliations, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				latestCall := mr.EXPECT().
+					GetLatestStatementBalance(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(&models.StatementBalance{
+						ID:            "__sbid_1__",
+						BankAccountID: "__baid_1__",
+						Date:          time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+						Balance:       0,
+					}, nil)
+
+				mr.EXPECT().
+					Commit(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq(time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC)), gomock.Eq(int64(0)), gomock.Eq(false)).
+					After(latestCall).
+					Times(1).
+					Return(&models.Reconciliation{
+						BankAccountID:    "__baid_1__",
+						StatementDate:    time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+						StatementBalance: 0,
+						ClearedBalance:   0,
+						Difference:       0,
+						Transactions:     []*models.Transaction{},
+					}, nil)
+			},
+			requestMethod:      http.MethodPost,
+			requestBody:        `{}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"statement_date": "2022-03-31",
+				"statement_balance": 0,
+				"cleared_balance": 0,
+				"difference": 0,
+				"transactions": []
+			}`,
+		},
+		{
+			name:     "post - failure - unbalanced",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/reconciliation",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mr *mockservices.MockIReconciliations, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mr.EXPECT().
+					Commit(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq(time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC)), gomock.Eq(int64(10000)), gomock.Eq(false)).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(nil, constants.ErrReconciliationUnbalanced)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"statement_date": "2022-03-31",
+				"statement_balance": 10000
+			}`,
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"errors":[{"message":"cleared balance does not match statement balance"}]}`,
+		},
+		{
+			name:     "post - failure - missing statement balance",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/reconciliation",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mr *mockservices.MockIReconciliations, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"statement_date": "2022-03-31"
+			}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"errors":[{"message":"statement_date and statement_balance are required"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockReconciliationsService := mockservices.NewMockIReconciliations(mockCtrl)
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockReconciliationsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			rc := &controllers.Reconciliations{
+				SReconciliations: mockReconciliationsService,
+				SBankAccounts:    mockBankAccountsService,
+				SBudgets:         mockBudgetsService,
+				SUserAccounts:    mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			rc.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
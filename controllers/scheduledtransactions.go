@@ -0,0 +1,423 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type ScheduledTransactions struct {
+	SScheduledTransactions services.IScheduledTransactions
+	SBankAccounts          services.IBankAccounts
+	SCategories            services.ICategories
+	SBudgets               services.IBudgets
+	SUserAccounts          services.IUserAccounts
+}
+
+type scheduledTransactionResponse struct {
+	ID         string  `json:"id"`
+	CategoryID *string `json:"category_id,omitempty"`
+	Amount     int64   `json:"amount"`
+	Payee      string  `json:"payee"`
+	Memo       *string `json:"memo,omitempty"`
+	Frequency  string  `json:"frequency"`
+	Interval   int     `json:"interval"`
+	DayOfMonth *int    `json:"day_of_month,omitempty"`
+	StartDate  string  `json:"start_date"`
+	EndDate    *string `json:"end_date,omitempty"`
+	NextDate   *string `json:"next_date,omitempty"`
+}
+
+type getAllScheduledTransactionsResponse struct {
+	ScheduledTransactions []scheduledTransactionResponse `json:"scheduled_transactions"`
+}
+
+func (st *ScheduledTransactions) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, st.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, st.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, st.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		scheduledTransactions, err := st.SScheduledTransactions.GetAll(r.Context(), bankAccountID)
+		if err != nil {
+			log.WithError(err).Error("Error getting all scheduled transactions")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getAllScheduledTransactionsResponse{
+			ScheduledTransactions: []scheduledTransactionResponse{},
+		}
+		for _, scheduledTransaction := range scheduledTransactions {
+			response.ScheduledTransactions = append(response.ScheduledTransactions, newScheduledTransactionResponse(scheduledTransaction))
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+func (st *ScheduledTransactions) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, st.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+		scheduledTransactionID := mux.Vars(r)["scheduledTransactionID"]
+
+		if !validateBudget(r.Context(), rw, st.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, st.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+		if !validateScheduledTransaction(r.Context(), rw, st.SScheduledTransactions, bankAccountID, scheduledTransactionID) {
+			return
+		}
+
+		scheduledTransaction, err := st.SScheduledTransactions.GetByID(r.Context(), scheduledTransactionID)
+		if err != nil {
+			log.WithError(err).Error("Error getting scheduled transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newScheduledTransactionResponse(scheduledTransaction))
+	}
+}
+
+type getUpcomingScheduledTransactionsResponse struct {
+	Occurrences []getUpcomingScheduledTransactionsResponseOccurrence `json:"occurrences"`
+}
+
+type getUpcomingScheduledTransactionsResponseOccurrence struct {
+	ScheduledTransactionID string  `json:"scheduled_transaction_id"`
+	Date                   string  `json:"date"`
+	CategoryID             *string `json:"category_id,omitempty"`
+	Amount                 int64   `json:"amount"`
+	Payee                  string  `json:"payee"`
+	Memo                   *string `json:"memo,omitempty"`
+}
+
+// GetUpcoming lists the occurrences of the bank account's scheduled
+// transactions over the next few days, 30 unless the days query parameter
+// says otherwise
+func (st *ScheduledTransactions) GetUpcoming() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, st.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, st.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, st.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		days := constants.DefaultUpcomingDays
+		if daysString := r.URL.Query().Get("days"); daysString != "" {
+			var err error
+			days, err = strconv.Atoi(daysString)
+			if err != nil || days < 1 || days > constants.MaxUpcomingDays {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromMessages("days must be between 1 and "+strconv.Itoa(constants.MaxUpcomingDays)))
+				return
+			}
+		}
+
+		from := time.Now().UTC().Truncate(24 * time.Hour)
+		to := from.AddDate(0, 0, days-1)
+
+		occurrences, err := st.SScheduledTransactions.GetUpcoming(r.Context(), bankAccountID, from, to)
+		if err != nil {
+			log.WithError(err).Error("Error getting upcoming scheduled transactions")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getUpcomingScheduledTransactionsResponse{
+			Occurrences: []getUpcomingScheduledTransactionsResponseOccurrence{},
+		}
+		for _, occurrence := range occurrences {
+			response.Occurrences = append(response.Occurrences, getUpcomingScheduledTransactionsResponseOccurrence{
+				ScheduledTransactionID: occurrence.ScheduledTransaction.ID,
+				Date:                   occurrence.Date.Format(constants.DateLayout),
+				CategoryID:             occurrence.ScheduledTransaction.CategoryID,
+				Amount:                 occurrence.ScheduledTransaction.Amount,
+				Payee:                  occurrence.ScheduledTransaction.Payee,
+				Memo:                   occurrence.ScheduledTransaction.Memo,
+			})
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+type postScheduledTransactionRequest struct {
+	CategoryID *string `json:"category_id"`
+	Amount     int64   `json:"amount"`
+	Payee      string  `json:"payee"`
+	Memo       *string `json:"memo"`
+	Frequency  string  `json:"frequency"`
+	Interval   *int    `json:"interval"`
+	DayOfMonth *int    `json:"day_of_month"`
+	StartDate  string  `json:"start_date"`
+	EndDate    *string `json:"end_date"`
+}
+
+func (st *ScheduledTransactions) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, st.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, st.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, st.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postScheduledTransactionRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		startDate, err := time.Parse(constants.DateLayout, requestBody.StartDate)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+			return
+		}
+		var endDate *time.Time
+		if requestBody.EndDate != nil {
+			date, err := time.Parse(constants.DateLayout, *requestBody.EndDate)
+			if err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+				return
+			}
+			endDate = &date
+		}
+		if requestBody.CategoryID != nil && !validateCategory(r.Context(), rw, st.SCategories, budgetID, *requestBody.CategoryID) {
+			return
+		}
+		interval := 1
+		if requestBody.Interval != nil {
+			interval = *requestBody.Interval
+		}
+
+		createdScheduledTransaction, err := st.SScheduledTransactions.Create(r.Context(), bankAccountID, requestBody.CategoryID, requestBody.Amount, requestBody.Payee, requestBody.Memo, models.RecurrenceFrequency(requestBody.Frequency), interval, requestBody.DayOfMonth, startDate, endDate)
+		switch err {
+		case constants.ErrInvalidRecurrenceRule:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating scheduled transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, newScheduledTransactionResponse(createdScheduledTransaction))
+	}
+}
+
+// patchScheduledTransactionRequest treats an empty category_id or end_date as
+// a request to remove it
+type patchScheduledTransactionRequest struct {
+	CategoryID *string `json:"category_id"`
+	Amount     *int64  `json:"amount"`
+	Payee      *string `json:"payee"`
+	Memo       *string `json:"memo"`
+	Frequency  *string `json:"frequency"`
+	Interval   *int    `json:"interval"`
+	DayOfMonth *int    `json:"day_of_month"`
+	StartDate  *string `json:"start_date"`
+	EndDate    *string `json:"end_date"`
+}
+
+func (st *ScheduledTransactions) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, st.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+		scheduledTransactionID := mux.Vars(r)["scheduledTransactionID"]
+
+		if !validateBudget(r.Context(), rw, st.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, st.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+		if !validateScheduledTransaction(r.Context(), rw, st.SScheduledTransactions, bankAccountID, scheduledTransactionID) {
+			return
+		}
+
+		var requestBody patchScheduledTransactionRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		scheduledTransaction, err := st.SScheduledTransactions.GetByID(r.Context(), scheduledTransactionID)
+		if err != nil {
+			log.WithError(err).Error("Error getting scheduled transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		if requestBody.CategoryID != nil {
+			if *requestBody.CategoryID == "" {
+				scheduledTransaction.CategoryID = nil
+			} else {
+				if !validateCategory(r.Context(), rw, st.SCategories, budgetID, *requestBody.CategoryID) {
+					return
+				}
+				scheduledTransaction.CategoryID = requestBody.CategoryID
+			}
+		}
+		if requestBody.Amount != nil {
+			scheduledTransaction.Amount = *requestBody.Amount
+		}
+		if requestBody.Payee != nil {
+			scheduledTransaction.Payee = *requestBody.Payee
+		}
+		if requestBody.Memo != nil {
+			scheduledTransaction.Memo = requestBody.Memo
+		}
+		if requestBody.Frequency != nil {
+			scheduledTransaction.Frequency = models.RecurrenceFrequency(*requestBody.Frequency)
+		}
+		if requestBody.Interval != nil {
+			scheduledTransaction.Interval = *requestBody.Interval
+		}
+		if requestBody.DayOfMonth != nil {
+			scheduledTransaction.DayOfMonth = requestBody.DayOfMonth
+		}
+		if requestBody.StartDate != nil {
+			date, err := time.Parse(constants.DateLayout, *requestBody.StartDate)
+			if err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+				return
+			}
+			scheduledTransaction.StartDate = date
+		}
+		if requestBody.EndDate != nil {
+			if *requestBody.EndDate == "" {
+				scheduledTransaction.EndDate = nil
+			} else {
+				date, err := time.Parse(constants.DateLayout, *requestBody.EndDate)
+				if err != nil {
+					writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+					return
+				}
+				scheduledTransaction.EndDate = &date
+			}
+		}
+
+		updatedScheduledTransaction, err := st.SScheduledTransactions.Update(r.Context(), scheduledTransaction)
+		switch err {
+		case constants.ErrInvalidRecurrenceRule:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating scheduled transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newScheduledTransactionResponse(updatedScheduledTransaction))
+	}
+}
+
+func (st *ScheduledTransactions) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, st.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+		scheduledTransactionID := mux.Vars(r)["scheduledTransactionID"]
+
+		if !validateBudget(r.Context(), rw, st.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, st.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+		if !validateScheduledTransaction(r.Context(), rw, st.SScheduledTransactions, bankAccountID, scheduledTransactionID) {
+			return
+		}
+
+		err := st.SScheduledTransactions.Delete(r.Context(), scheduledTransactionID)
+		if err != nil {
+			log.WithError(err).Error("Error deleting scheduled transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newScheduledTransactionResponse(scheduledTransaction *models.ScheduledTransaction) scheduledTransactionResponse {
+	response := scheduledTransactionResponse{
+		ID:         scheduledTransaction.ID,
+		CategoryID: scheduledTransaction.CategoryID,
+		Amount:     scheduledTransaction.Amount,
+		Payee:      scheduledTransaction.Payee,
+		Memo:       scheduledTransaction.Memo,
+		Frequency:  string(scheduledTransaction.Frequency),
+		Interval:   scheduledTransaction.Interval,
+		DayOfMonth: scheduledTransaction.DayOfMonth,
+		StartDate:  scheduledTransaction.StartDate.Format(constants.DateLayout),
+	}
+	if scheduledTransaction.EndDate != nil {
+		endDate := scheduledTransaction.EndDate.Format(constants.DateLayout)
+		response.EndDate = &endDate
+	}
+	if scheduledTransaction.NextDate != nil {
+		nextDate := scheduledTransaction.NextDate.Format(constants.DateLayout)
+		response.NextDate = &nextDate
+	}
+
+	return response
+}
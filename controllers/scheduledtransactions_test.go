@@ -0,0 +1,225 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTransactionsPost(t *testing.T) {
+	dayOfMonth := 1
+	nextDate := time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mst *mockservices.MockIScheduledTransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/scheduled-transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mst *mockservices.MockIScheduledTransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mst.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Nil(), gomock.Eq(int64(-120000)), gomock.Eq("Landlord"), gomock.Nil(), gomock.Eq(models.RecurrenceFrequencyMonthly), gomock.Eq(1), gomock.Eq(&dayOfMonth), gomock.Eq(time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)), gomock.Nil()).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(&models.ScheduledTransaction{
+						ID:            "__stid_1__",
+						BankAccountID: "__baid_1__",
+						Amount:        -120000,
+						Payee:         "Landlord",
+						Frequency:     models.RecurrenceFrequencyMonthly,
+						Interval:      1,
+						DayOfMonth:    &dayOfMonth,
+						StartDate:     time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC),
+						NextDate:      &nextDate,
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"amount": -120000,
+				"payee": "Landlord",
+				"frequency": "monthly",
+				"day_of_month": 1,
+				"start_date": "2022-04-01"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__stid_1__",
+				"amount": -120000,
+				"payee": "Landlord",
+				"frequency": "monthly",
+				"interval": 1,
+				"day_of_month": 1,
+				"start_date": "2022-04-01",
+				"next_date": "2022-04-01"
+			}`,
+		},
+		{
+			name:     "post - failure - invalid recurrence rule",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/scheduled-transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mst *mockservices.MockIScheduledTransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mst.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Nil(), gomock.Eq(int64(-1500)), gomock.Eq("Streaming"), gomock.Nil(), gomock.Eq(models.RecurrenceFrequency("hourly")), gomock.Eq(1), gomock.Nil(), gomock.Eq(time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)), gomock.Nil()).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidRecurrenceRule)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"amount": -1500,
+				"payee": "Streaming",
+				"frequency": "hourly",
+				"start_date": "2022-04-01"
+			}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"errors":[{"message":"invalid recurrence rule"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockScheduledTransactionsService := mockservices.NewMockIScheduledTransactions(mockCtrl)
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockScheduledTransactionsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			st := &controllers.ScheduledTransactions{
+				SScheduledTransactions: mockScheduledTransactionsService,
+				SBankAccounts:          mockBankAccountsService,
+				SBudgets:               mockBudgetsService,
+				SUserAccounts:          mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			st.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type TOTP struct {
+	STOTP         services.ITOTP
+	SUserAccounts services.IUserAccounts
+}
+
+type getTOTPResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (t *TOTP) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		enabled, err := t.STOTP.IsEnabled(r.Context(), userAccount.Username)
+		if err != nil {
+			log.WithError(err).Error("Error checking if two-factor authentication is enabled")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, getTOTPResponse{
+			Enabled: enabled,
+		})
+	}
+}
+
+type postTOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+func (t *TOTP) PostEnrollment() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		secret, uri, err := t.STOTP.Enroll(r.Context(), userAccount.Username)
+		switch err {
+		case constants.ErrTOTPAlreadyEnabled:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error enrolling two-factor authentication")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, postTOTPEnrollmentResponse{
+			Secret: secret,
+			URI:    uri,
+		})
+	}
+}
+
+type postTOTPVerificationRequest struct {
+	Code string `json:"code"`
+}
+
+type postTOTPVerificationResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (t *TOTP) PostVerification() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		var requestBody postTOTPVerificationRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		recoveryCodes, err := t.STOTP.Enable(r.Context(), userAccount.Username, requestBody.Code)
+		switch err {
+		case constants.ErrTOTPNotEnrolled:
+			writeResponse(rw, http.StatusNotFound, errorsResponseFromErrors(err))
+			return
+		case constants.ErrTOTPAlreadyEnabled:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case constants.ErrInvalidTOTPCode:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error enabling two-factor authentication")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, postTOTPVerificationResponse{
+			RecoveryCodes: recoveryCodes,
+		})
+	}
+}
+
+type postTOTPRecoveryCodesRequest struct {
+	Code string `json:"code"`
+}
+
+type postTOTPRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (t *TOTP) PostRecoveryCodes() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		var requestBody postTOTPRecoveryCodesRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		recoveryCodes, err := t.STOTP.RegenerateRecoveryCodes(r.Context(), userAccount.Username, requestBody.Code)
+		switch err {
+		case constants.ErrTOTPNotEnabled:
+			writeResponse(rw, http.StatusNotFound, errorsResponseFromErrors(err))
+			return
+		case constants.ErrInvalidTOTPCode:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrTOTPLocked:
+			writeResponse(rw, http.StatusTooManyRequests, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error regenerating recovery codes")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, postTOTPRecoveryCodesResponse{
+			RecoveryCodes: recoveryCodes,
+		})
+	}
+}
+
+type deleteTOTPRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+func (t *TOTP) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		var requestBody deleteTOTPRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		err = t.STOTP.Disable(r.Context(), userAccount.Username, requestBody.Password, requestBody.Code)
+		switch err {
+		case constants.ErrTOTPNotEnabled:
+			writeResponse(rw, http.StatusNotFound, errorsResponseFromErrors(err))
+			return
+		case constants.ErrIncorrectPassword:
+			writeResponse(rw, http.StatusForbidden, errorsResponseFromErrors(err))
+			return
+		case constants.ErrInvalidTOTPCode:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrTOTPLocked:
+			writeResponse(rw, http.StatusTooManyRequests, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error disabling two-factor authentication")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,155 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPRefusesAPIKeys(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		handlerFunc          func(t *controllers.TOTP) http.HandlerFunc
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "get",
+			endpoint: "/api/v1/user-accounts/2fa/totp",
+			handlerFunc: func(t *controllers.TOTP) http.HandlerFunc {
+				return t.Get()
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+		{
+			name:     "post enrollment",
+			endpoint: "/api/v1/user-accounts/2fa/totp",
+			handlerFunc: func(t *controllers.TOTP) http.HandlerFunc {
+				return t.PostEnrollment()
+			},
+			requestMethod:      http.MethodPost,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+		{
+			name:     "post verification",
+			endpoint: "/api/v1/user-accounts/2fa/totp/verify",
+			handlerFunc: func(t *controllers.TOTP) http.HandlerFunc {
+				return t.PostVerification()
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"code": "123456"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+		{
+			name:     "post recovery codes",
+			endpoint: "/api/v1/user-accounts/2fa/totp/recovery-codes",
+			handlerFunc: func(t *controllers.TOTP) http.HandlerFunc {
+				return t.PostRecoveryCodes()
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"code": "123456"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+		{
+			name:     "delete",
+			endpoint: "/api/v1/user-accounts/2fa/totp",
+			handlerFunc: func(t *controllers.TOTP) http.HandlerFunc {
+				return t.Delete()
+			},
+			requestMethod: http.MethodDelete,
+			requestBody: `{
+				"code": "123456"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "This cannot be done using an API key"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			// the TOTP service must not be reached
+			mockTOTPService := mockservices.NewMockITOTP(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			userExistsCall := mockUserAccountsService.EXPECT().
+				ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+				Times(1).
+				Return(true, nil)
+			mockUserAccountsService.EXPECT().
+				GetInfo(gomock.Any(), gomock.Eq("user_1")).
+				After(userExistsCall).
+				Times(1).
+				Return(&models.UserAccount{
+					ID:           "__uaid_1__",
+					Username:     "user_1",
+					PasswordHash: "__hash__",
+				}, nil)
+
+			totp := &controllers.TOTP{
+				STOTP:         mockTOTPService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+			r = r.WithContext(context.WithValue(r.Context(), constants.APIKeyIDContextKey, "__akid_1__"))
+
+			tt.handlerFunc(totp).ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,527 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Transactions struct {
+	STransactions services.ITransactions
+	SBankAccounts services.IBankAccounts
+	SCategories   services.ICategories
+	SBudgets      services.IBudgets
+	SUserAccounts services.IUserAccounts
+}
+
+type getAllTransactionsResponse struct {
+	Transactions []getAllTransactionsResponseTransaction `json:"transactions"`
+	NextCursor   *string                                 `json:"next_cursor,omitempty"`
+}
+
+type getAllTransactionsResponseTransaction struct {
+	ID         string                     `json:"id"`
+	CategoryID *string                    `json:"category_id,omitempty"`
+	Date       string                     `json:"date"`
+	Amount     int64                      `json:"amount"`
+	Payee      string                     `json:"payee"`
+	Memo       *string                    `json:"memo,omitempty"`
+	Cleared    string                     `json:"cleared"`
+	TransferID *string                    `json:"transfer_id,omitempty"`
+	PayeeID    *string                    `json:"payee_id,omitempty"`
+	Splits     []transactionSplitResponse `json:"splits,omitempty"`
+}
+
+func (t *Transactions) GetAll() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, t.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		page, ok := validatePage(rw, r, []pagination.SortKey{
+			{Name: "date", Type: pagination.SortDate},
+			{Name: "amount", Type: pagination.SortInteger},
+			{Name: "payee", Type: pagination.SortText},
+		}, "date")
+		if !ok {
+			return
+		}
+		filter, ok := validateTransactionFilter(rw, r)
+		if !ok {
+			return
+		}
+
+		transactions, next, err := t.STransactions.GetPage(r.Context(), bankAccountID, filter, page)
+		switch err {
+		case constants.ErrInvalidClearedState, constants.ErrInvalidDateRange, constants.ErrInvalidAmountRange:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error getting all transactions")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		response := getAllTransactionsResponse{
+			Transactions: []getAllTransactionsResponseTransaction{},
+			NextCursor:   encodeCursor(next),
+		}
+		for _, transaction := range transactions {
+			response.Transactions = append(response.Transactions, getAllTransactionsResponseTransaction{
+				ID:         transaction.ID,
+				CategoryID: transaction.CategoryID,
+				Date:       transaction.Date.Format(constants.DateLayout),
+				Amount:     transaction.Amount,
+				Payee:      transaction.Payee,
+				Memo:       transaction.Memo,
+				Cleared:    string(transaction.Cleared),
+				TransferID: transaction.TransferID,
+				PayeeID:    transaction.PayeeID,
+				Splits:     newTransactionSplitsResponse(transaction.Splits),
+			})
+		}
+
+		writeResponse(rw, http.StatusOK, response)
+	}
+}
+
+// validateTransactionFilter reads the query parameters narrowing a list of
+// transactions
+func validateTransactionFilter(rw http.ResponseWriter, r *http.Request) (*models.TransactionFilter, bool) {
+	query := r.URL.Query()
+	filter := &models.TransactionFilter{}
+
+	for key, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(key); value != "" {
+			date, err := time.Parse(constants.DateLayout, value)
+			if err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+				return nil, false
+			}
+			*dst = &date
+		}
+	}
+	for key, dst := range map[string]**int64{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		if value := query.Get(key); value != "" {
+			amount, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidAmount))
+				return nil, false
+			}
+			*dst = &amount
+		}
+	}
+	for key, dst := range map[string]**string{"payee_id": &filter.PayeeID, "category_id": &filter.CategoryID} {
+		if value := query.Get(key); value != "" {
+			if _, err := uuid.Parse(value); err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromMessages(key+" must be a valid ID"))
+				return nil, false
+			}
+			id := value
+			*dst = &id
+		}
+	}
+	if cleared := query.Get("cleared"); cleared != "" {
+		clearedState := models.ClearedState(cleared)
+		filter.Cleared = &clearedState
+	}
+
+	return filter, true
+}
+
+type transactionSplitRequest struct {
+	CategoryID *string `json:"category_id"`
+	Amount     int64   `json:"amount"`
+	Memo       *string `json:"memo"`
+}
+
+type transactionSplitResponse struct {
+	ID         string  `json:"id"`
+	CategoryID *string `json:"category_id,omitempty"`
+	Amount     int64   `json:"amount"`
+	Memo       *string `json:"memo,omitempty"`
+}
+
+type getTransactionResponse struct {
+	ID         string                     `json:"id"`
+	CategoryID *string                    `json:"category_id,omitempty"`
+	Date       string                     `json:"date"`
+	Amount     int64                      `json:"amount"`
+	Payee      string                     `json:"payee"`
+	Memo       *string                    `json:"memo,omitempty"`
+	Cleared    string                     `json:"cleared"`
+	TransferID *string                    `json:"transfer_id,omitempty"`
+	PayeeID    *string                    `json:"payee_id,omitempty"`
+	Splits     []transactionSplitResponse `json:"splits,omitempty"`
+}
+
+func (t *Transactions) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+		transactionID := mux.Vars(r)["transactionID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, t.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+		if !validateTransaction(r.Context(), rw, t.STransactions, bankAccountID, transactionID) {
+			return
+		}
+
+		transaction, err := t.STransactions.GetByID(r.Context(), transactionID)
+		if err != nil {
+			log.WithError(err).Error("Error getting transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, getTransactionResponse{
+			ID:         transaction.ID,
+			CategoryID: transaction.CategoryID,
+			Date:       transaction.Date.Format(constants.DateLayout),
+			Amount:     transaction.Amount,
+			Payee:      transaction.Payee,
+			Memo:       transaction.Memo,
+			Cleared:    string(transaction.Cleared),
+			TransferID: transaction.TransferID,
+			PayeeID:    transaction.PayeeID,
+			Splits:     newTransactionSplitsResponse(transaction.Splits),
+		})
+	}
+}
+
+type postTransactionRequest struct {
+	CategoryID *string                   `json:"category_id"`
+	Date       string                    `json:"date"`
+	Amount     int64                     `json:"amount"`
+	Payee      string                    `json:"payee"`
+	Memo       *string                   `json:"memo"`
+	Cleared    *string                   `json:"cleared"`
+	Splits     []transactionSplitRequest `json:"splits"`
+}
+
+type postTransactionResponse struct {
+	ID         string                     `json:"id"`
+	CategoryID *string                    `json:"category_id,omitempty"`
+	Date       string                     `json:"date"`
+	Amount     int64                      `json:"amount"`
+	Payee      string                     `json:"payee"`
+	Memo       *string                    `json:"memo,omitempty"`
+	Cleared    string                     `json:"cleared"`
+	TransferID *string                    `json:"transfer_id,omitempty"`
+	PayeeID    *string                    `json:"payee_id,omitempty"`
+	Splits     []transactionSplitResponse `json:"splits,omitempty"`
+}
+
+func (t *Transactions) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, t.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+
+		var requestBody postTransactionRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		date, err := time.Parse(constants.DateLayout, requestBody.Date)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+			return
+		}
+		if requestBody.CategoryID != nil && !validateCategory(r.Context(), rw, t.SCategories, budgetID, *requestBody.CategoryID) {
+			return
+		}
+		splits, ok := t.splitsFromRequest(r.Context(), rw, budgetID, requestBody.Splits)
+		if !ok {
+			return
+		}
+		cleared := models.ClearedStateUncleared
+		if requestBody.Cleared != nil {
+			cleared = models.ClearedState(*requestBody.Cleared)
+		}
+
+		createdTransaction, err := t.STransactions.Create(r.Context(), bankAccountID, requestBody.CategoryID, date, requestBody.Amount, requestBody.Payee, requestBody.Memo, cleared, splits)
+		switch err {
+		case constants.ErrInvalidClearedState, constants.ErrInvalidSplits:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, postTransactionResponse{
+			ID:         createdTransaction.ID,
+			CategoryID: createdTransaction.CategoryID,
+			Date:       createdTransaction.Date.Format(constants.DateLayout),
+			Amount:     createdTransaction.Amount,
+			Payee:      createdTransaction.Payee,
+			Memo:       createdTransaction.Memo,
+			Cleared:    string(createdTransaction.Cleared),
+			TransferID: createdTransaction.TransferID,
+			PayeeID:    createdTransaction.PayeeID,
+			Splits:     newTransactionSplitsResponse(createdTransaction.Splits),
+		})
+	}
+}
+
+// patchTransactionRequest treats an empty category_id as a request to
+// remove the transaction's category, and an empty list of splits as a
+// request to remove its splits
+type patchTransactionRequest struct {
+	CategoryID *string                    `json:"category_id"`
+	Date       *string                    `json:"date"`
+	Amount     *int64                     `json:"amount"`
+	Payee      *string                    `json:"payee"`
+	Memo       *string                    `json:"memo"`
+	Cleared    *string                    `json:"cleared"`
+	Splits     *[]transactionSplitRequest `json:"splits"`
+}
+
+type patchTransactionResponse struct {
+	ID         string                     `json:"id"`
+	CategoryID *string                    `json:"category_id,omitempty"`
+	Date       string                     `json:"date"`
+	Amount     int64                      `json:"amount"`
+	Payee      string                     `json:"payee"`
+	Memo       *string                    `json:"memo,omitempty"`
+	Cleared    string                     `json:"cleared"`
+	TransferID *string                    `json:"transfer_id,omitempty"`
+	PayeeID    *string                    `json:"payee_id,omitempty"`
+	Splits     []transactionSplitResponse `json:"splits,omitempty"`
+}
+
+// Patch updates a transaction. Reconciled transactions can only be changed
+// by passing force=true in the query string.
+func (t *Transactions) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+		transactionID := mux.Vars(r)["transactionID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, t.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+		if !validateTransaction(r.Context(), rw, t.STransactions, bankAccountID, transactionID) {
+			return
+		}
+
+		var requestBody patchTransactionRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		transaction, err := t.STransactions.GetByID(r.Context(), transactionID)
+		if err != nil {
+			log.WithError(err).Error("Error getting transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		if requestBody.CategoryID != nil {
+			if *requestBody.CategoryID == "" {
+				transaction.CategoryID = nil
+			} else {
+				if !validateCategory(r.Context(), rw, t.SCategories, budgetID, *requestBody.CategoryID) {
+					return
+				}
+				transaction.CategoryID = requestBody.CategoryID
+				// categorizing the whole transaction replaces any splits
+				if requestBody.Splits == nil {
+					transaction.Splits = []*models.TransactionSplit{}
+				}
+			}
+		}
+		if requestBody.Splits != nil {
+			splits, ok := t.splitsFromRequest(r.Context(), rw, budgetID, *requestBody.Splits)
+			if !ok {
+				return
+			}
+			transaction.Splits = splits
+		}
+		if requestBody.Date != nil {
+			date, err := time.Parse(constants.DateLayout, *requestBody.Date)
+			if err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+				return
+			}
+			transaction.Date = date
+		}
+		if requestBody.Amount != nil {
+			transaction.Amount = *requestBody.Amount
+		}
+		if requestBody.Payee != nil {
+			transaction.Payee = *requestBody.Payee
+		}
+		if requestBody.Memo != nil {
+			transaction.Memo = requestBody.Memo
+		}
+		if requestBody.Cleared != nil {
+			transaction.Cleared = models.ClearedState(*requestBody.Cleared)
+		}
+
+		force := r.URL.Query().Get("force") == "true"
+
+		updatedTransaction, err := t.STransactions.Update(r.Context(), transaction, force)
+		switch err {
+		case constants.ErrInvalidClearedState, constants.ErrInvalidSplits:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrTransactionReconciled:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, patchTransactionResponse{
+			ID:         updatedTransaction.ID,
+			CategoryID: updatedTransaction.CategoryID,
+			Date:       updatedTransaction.Date.Format(constants.DateLayout),
+			Amount:     updatedTransaction.Amount,
+			Payee:      updatedTransaction.Payee,
+			Memo:       updatedTransaction.Memo,
+			Cleared:    string(updatedTransaction.Cleared),
+			TransferID: updatedTransaction.TransferID,
+			PayeeID:    updatedTransaction.PayeeID,
+			Splits:     newTransactionSplitsResponse(updatedTransaction.Splits),
+		})
+	}
+}
+
+// Delete removes a transaction. As with Patch, reconciled transactions need
+// force=true.
+func (t *Transactions) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		bankAccountID := mux.Vars(r)["bankAccountID"]
+		transactionID := mux.Vars(r)["transactionID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateBankAccount(r.Context(), rw, t.SBankAccounts, budgetID, bankAccountID) {
+			return
+		}
+		if !validateTransaction(r.Context(), rw, t.STransactions, bankAccountID, transactionID) {
+			return
+		}
+
+		force := r.URL.Query().Get("force") == "true"
+
+		err := t.STransactions.Delete(r.Context(), transactionID, force)
+		switch err {
+		case constants.ErrTransactionReconciled:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error deleting transaction")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// splitsFromRequest validates the categories of the requested splits. It
+// writes an error response and returns false if any are invalid.
+func (t *Transactions) splitsFromRequest(ctx context.Context, rw http.ResponseWriter, budgetID string, requestSplits []transactionSplitRequest) ([]*models.TransactionSplit, bool) {
+	splits := []*models.TransactionSplit{}
+	for _, requestSplit := range requestSplits {
+		if requestSplit.CategoryID != nil && !validateCategory(ctx, rw, t.SCategories, budgetID, *requestSplit.CategoryID) {
+			return nil, false
+		}
+		splits = append(splits, &models.TransactionSplit{
+			CategoryID: requestSplit.CategoryID,
+			Amount:     requestSplit.Amount,
+			Memo:       requestSplit.Memo,
+		})
+	}
+	return splits, true
+}
+
+func newTransactionSplitsResponse(splits []*models.TransactionSplit) []transactionSplitResponse {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	response := []transactionSplitResponse{}
+	for _, split := range splits {
+		response = append(response, transactionSplitResponse{
+			ID:         split.ID,
+			CategoryID: split.CategoryID,
+			Amount:     split.Amount,
+			Memo:       split.Memo,
+		})
+	}
+	return response
+}
@@ -0,0 +1,871 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionsGetAll(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "get all - success",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					GetPage(gomock.Any(), gomock.Eq("__baid_1__"), gomock.Eq(&models.TransactionFilter{}), gomock.Eq(&pagination.Params{Limit: constants.DefaultPageLimit, Sort: "date"})).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return([]*models.Transaction{
+						{
+							ID:            "__tid_1__",
+							BankAccountID: "__baid_1__",
+							Date:          time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+							Amount:        -4599,
+							Payee:         "payee_1",
+							Memo:          pointerify("memo_1"),
+							Cleared:       models.ClearedStateCleared,
+						},
+						{
+							ID:            "__tid_2__",
+							BankAccountID: "__baid_1__",
+							Date:          time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount:        150000,
+							Payee:         "payee_2",
+							Memo:          nil,
+							Cleared:       models.ClearedStateUncleared,
+						},
+					}, nil, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"transactions": [
+					{
+						"id": "__tid_1__",
+						"date": "2022-03-01",
+						"amount": -4599,
+						"payee": "payee_1",
+						"memo": "memo_1",
+						"cleared": "cleared"
+					},
+					{
+						"id": "__tid_2__",
+						"date": "2022-03-02",
+						"amount": 150000,
+						"payee": "payee_2",
+						"cleared": "uncleared"
+					}
+				]
+			}`,
+		},
+		{
+			name:     "get all - success - filtered page",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions?limit=1&sort=-amount&from=2022-03-01&cleared=cleared",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					GetPage(gomock.Any(),
+						gomock.Eq("__baid_1__"),
+						gomock.Eq(&models.TransactionFilter{
+							From: func() *time.Time {
+								from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+								return &from
+							}(),
+							Cleared: func() *models.ClearedState {
+								cleared := models.ClearedStateCleared
+								return &cleared
+							}(),
+						}),
+						gomock.Eq(&pagination.Params{Limit: 1, Sort: "amount", Descending: true})).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return([]*models.Transaction{
+						{
+							ID:            "__tid_1__",
+							BankAccountID: "__baid_1__",
+							Date:          time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+							Amount:        -4599,
+							Payee:         "payee_1",
+							Cleared:       models.ClearedStateCleared,
+						},
+					}, &pagination.Cursor{Sort: "-amount", Value: "-4599", ID: "__tid_1__"}, nil)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{
+				"transactions": [
+					{
+						"id": "__tid_1__",
+						"date": "2022-03-01",
+						"amount": -4599,
+						"payee": "payee_1",
+						"cleared": "cleared"
+					}
+				],
+				"next_cursor": "` + (&pagination.Cursor{Sort: "-amount", Value: "-4599", ID: "__tid_1__"}).Encode() + `"
+			}`,
+		},
+		{
+			name:     "get all - failure - invalid limit",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions?limit=0",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					GetPage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "limit must be between 1 and 200"
+				}]
+			}`,
+		},
+		{
+			name:     "get all - failure - cursor for another sort",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions?sort=payee&cursor=" + (&pagination.Cursor{Sort: "-amount", Value: "-4599", ID: "__tid_1__"}).Encode(),
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					GetPage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid cursor"
+				}]
+			}`,
+		},
+		{
+			name:     "get all - failure - tampered cursor",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions?sort=-amount&cursor=" + (&pagination.Cursor{Sort: "-amount", Value: "0 OR 1=1", ID: "__tid_1__"}).Encode(),
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					GetPage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid cursor"
+				}]
+			}`,
+		},
+		{
+			name:     "get all - failure - bank account in other budget",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_2__/transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_2__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleViewer)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_2__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_2__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(false, nil)
+
+				mt.EXPECT().
+					GetPage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod:      http.MethodGet,
+			requestBody:        ``,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Bank account does not exist"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactionsService := mockservices.NewMockITransactions(gomock.NewController(t))
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockTransactionsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			tc := &controllers.Transactions{
+				STransactions: mockTransactionsService,
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			tc.GetAll().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestTransactionsPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					Create(gomock.Any(),
+						gomock.Eq("__baid_1__"),
+						gomock.Nil(),
+						gomock.Eq(time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)),
+						gomock.Eq(int64(-4599)),
+						gomock.Eq("payee_1"),
+						gomock.Nil(),
+						gomock.Eq(models.ClearedStateUncleared),
+						gomock.Eq([]*models.TransactionSplit{}),
+					).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(&models.Transaction{
+						ID:            "__tid_1__",
+						BankAccountID: "__baid_1__",
+						Date:          time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+						Amount:        -4599,
+						Payee:         "payee_1",
+						Memo:          nil,
+						Cleared:       models.ClearedStateUncleared,
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"date": "2022-03-01",
+				"amount": -4599,
+				"payee": "payee_1"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__tid_1__",
+				"date": "2022-03-01",
+				"amount": -4599,
+				"payee": "payee_1",
+				"cleared": "uncleared"
+			}`,
+		},
+		{
+			name:     "post - failure - viewer",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(constants.ErrInsufficientBudgetRole)
+
+				mt.EXPECT().
+					Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"date": "2022-03-01",
+				"amount": -4599,
+				"payee": "Grocery Store"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "Budget role editor is required"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - invalid date",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"date": "03/01/2022",
+				"amount": -4599,
+				"payee": "payee_1"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "invalid date"
+				}]
+			}`,
+		},
+		{
+			name:     "post - failure - splits do not sum to amount",
+			endpoint: "/api/v1/budgets/__bid_1__/bank-accounts/__baid_1__/transactions",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID":      "__bid_1__",
+					"bankAccountID": "__baid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransactions, mba *mockservices.MockIBankAccounts, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				bankAccountExistsCall := mba.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__baid_1__")).
+					After(authorizeCall).
+					Times(1).
+					Return(true, nil)
+
+				bankAccountBelongsToCall := mba.EXPECT().
+					BelongsTo(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__")).
+					After(bankAccountExistsCall).
+					Times(1).
+					Return(true, nil)
+
+				mt.EXPECT().
+					Create(gomock.Any(),
+						gomock.Eq("__baid_1__"),
+						gomock.Nil(),
+						gomock.Eq(time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)),
+						gomock.Eq(int64(-4599)),
+						gomock.Eq("payee_1"),
+						gomock.Nil(),
+						gomock.Eq(models.ClearedStateUncleared),
+						gomock.Eq([]*models.TransactionSplit{
+							{Amount: -4000},
+							{Amount: -500},
+						}),
+					).
+					After(bankAccountBelongsToCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidSplits)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"date": "2022-03-01",
+				"amount": -4599,
+				"payee": "payee_1",
+				"splits": [
+					{"amount": -4000},
+					{"amount": -500}
+				]
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: `{
+				"errors": [{
+					"message": "split amounts must sum to the transaction amount"
+				}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactionsService := mockservices.NewMockITransactions(gomock.NewController(t))
+			mockBankAccountsService := mockservices.NewMockIBankAccounts(gomock.NewController(t))
+			mockBudgetsService := mockservices.NewMockIBudgets(gomock.NewController(t))
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockTransactionsService, mockBankAccountsService, mockBudgetsService, mockUserAccountsService)
+
+			tc := &controllers.Transactions{
+				STransactions: mockTransactionsService,
+				SBankAccounts: mockBankAccountsService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			tc.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
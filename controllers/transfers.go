@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Transfers struct {
+	STransfers    services.ITransfers
+	SBudgets      services.IBudgets
+	SUserAccounts services.IUserAccounts
+}
+
+type transferResponse struct {
+	ID                   string  `json:"id"`
+	FromBankAccountID    string  `json:"from_bank_account_id"`
+	ToBankAccountID      string  `json:"to_bank_account_id"`
+	OutflowTransactionID string  `json:"outflow_transaction_id"`
+	InflowTransactionID  string  `json:"inflow_transaction_id"`
+	Date                 string  `json:"date"`
+	Amount               int64   `json:"amount"`
+	Memo                 *string `json:"memo,omitempty"`
+}
+
+func (t *Transfers) Get() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		transferID := mux.Vars(r)["transferID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleViewer) {
+			return
+		}
+		if !validateTransfer(r.Context(), rw, t.STransfers, budgetID, transferID) {
+			return
+		}
+
+		transfer, err := t.STransfers.GetByID(r.Context(), transferID)
+		if err != nil {
+			log.WithError(err).Error("Error getting transfer")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newTransferResponse(transfer))
+	}
+}
+
+type postTransferRequest struct {
+	FromBankAccountID string  `json:"from_bank_account_id"`
+	ToBankAccountID   string  `json:"to_bank_account_id"`
+	Date              string  `json:"date"`
+	Amount            int64   `json:"amount"`
+	Memo              *string `json:"memo"`
+}
+
+func (t *Transfers) Post() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+
+		var requestBody postTransferRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		date, err := time.Parse(constants.DateLayout, requestBody.Date)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+			return
+		}
+
+		createdTransfer, err := t.STransfers.Create(r.Context(), userAccount.ID, budgetID, requestBody.FromBankAccountID, requestBody.ToBankAccountID, date, requestBody.Amount, requestBody.Memo)
+		switch err {
+		case constants.ErrInvalidTransferAmount, constants.ErrInvalidTransferBankAccounts, constants.ErrInvalidTransferCurrencies:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error creating transfer")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusCreated, newTransferResponse(createdTransfer))
+	}
+}
+
+type patchTransferRequest struct {
+	Date   *string `json:"date"`
+	Amount *int64  `json:"amount"`
+	Memo   *string `json:"memo"`
+}
+
+// Patch updates both sides of a transfer. Reconciled transfers can only be
+// changed by passing force=true in the query string.
+func (t *Transfers) Patch() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		transferID := mux.Vars(r)["transferID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateTransfer(r.Context(), rw, t.STransfers, budgetID, transferID) {
+			return
+		}
+
+		var requestBody patchTransferRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		transfer, err := t.STransfers.GetByID(r.Context(), transferID)
+		if err != nil {
+			log.WithError(err).Error("Error getting transfer")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		date := transfer.Inflow.Date
+		amount := transfer.Inflow.Amount
+		memo := transfer.Inflow.Memo
+		if requestBody.Date != nil {
+			date, err = time.Parse(constants.DateLayout, *requestBody.Date)
+			if err != nil {
+				writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(constants.ErrInvalidDate))
+				return
+			}
+		}
+		if requestBody.Amount != nil {
+			amount = *requestBody.Amount
+		}
+		if requestBody.Memo != nil {
+			memo = requestBody.Memo
+		}
+
+		force := r.URL.Query().Get("force") == "true"
+
+		updatedTransfer, err := t.STransfers.Update(r.Context(), transferID, date, amount, memo, force)
+		switch err {
+		case constants.ErrInvalidTransferAmount:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case constants.ErrTransactionReconciled:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error updating transfer")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, newTransferResponse(updatedTransfer))
+	}
+}
+
+// Delete removes both sides of a transfer. As with Patch, reconciled
+// transfers need force=true.
+func (t *Transfers) Delete() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, t.SUserAccounts)
+		if !ok {
+			return
+		}
+
+		budgetID := mux.Vars(r)["budgetID"]
+		transferID := mux.Vars(r)["transferID"]
+
+		if !validateBudget(r.Context(), rw, t.SBudgets, userAccount.ID, budgetID, models.BudgetRoleEditor) {
+			return
+		}
+		if !validateTransfer(r.Context(), rw, t.STransfers, budgetID, transferID) {
+			return
+		}
+
+		force := r.URL.Query().Get("force") == "true"
+
+		err := t.STransfers.Delete(r.Context(), transferID, force)
+		switch err {
+		case constants.ErrTransactionReconciled:
+			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error deleting transfer")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTransferResponse(transfer *models.Transfer) transferResponse {
+	return transferResponse{
+		ID:                   transfer.ID,
+		FromBankAccountID:    transfer.Outflow.BankAccountID,
+		ToBankAccountID:      transfer.Inflow.BankAccountID,
+		OutflowTransactionID: transfer.Outflow.ID,
+		InflowTransactionID:  transfer.Inflow.ID,
+		Date:                 transfer.Inflow.Date.Format(constants.DateLayout),
+		Amount:               transfer.Inflow.Amount,
+		Memo:                 transfer.Inflow.Memo,
+	}
+}
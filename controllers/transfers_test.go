@@ -0,0 +1,207 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/controllers"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransfersPost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(mt *mockservices.MockITransfers, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "post - success",
+			endpoint: "/api/v1/budgets/__bid_1__/transfers",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransfers, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				mt.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__"), gomock.Eq("__baid_2__"), gomock.Eq(time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC)), gomock.Eq(int64(5000)), gomock.Eq(pointerify("Savings"))).
+					After(authorizeCall).
+					Times(1).
+					Return(&models.Transfer{
+						ID: "__trid_1__",
+						Outflow: &models.Transaction{
+							ID:            "__tid_1__",
+							BankAccountID: "__baid_1__",
+							Date:          time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount:        -5000,
+							Payee:         "Transfer to Savings",
+							Memo:          pointerify("Savings"),
+							Cleared:       models.ClearedStateUncleared,
+							TransferID:    pointerify("__trid_1__"),
+						},
+						Inflow: &models.Transaction{
+							ID:            "__tid_2__",
+							BankAccountID: "__baid_2__",
+							Date:          time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+							Amount:        5000,
+							Payee:         "Transfer from Checking",
+							Memo:          pointerify("Savings"),
+							Cleared:       models.ClearedStateUncleared,
+							TransferID:    pointerify("__trid_1__"),
+						},
+					}, nil)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"from_bank_account_id": "__baid_1__",
+				"to_bank_account_id": "__baid_2__",
+				"date": "2022-03-02",
+				"amount": 5000,
+				"memo": "Savings"
+			}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{
+				"id": "__trid_1__",
+				"from_bank_account_id": "__baid_1__",
+				"to_bank_account_id": "__baid_2__",
+				"outflow_transaction_id": "__tid_1__",
+				"inflow_transaction_id": "__tid_2__",
+				"date": "2022-03-02",
+				"amount": 5000,
+				"memo": "Savings"
+			}`,
+		},
+		{
+			name:     "post - failure - bank account in other budget",
+			endpoint: "/api/v1/budgets/__bid_1__/transfers",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = mux.SetURLVars(r, map[string]string{
+					"budgetID": "__bid_1__",
+				})
+				return r
+			},
+			mockSetupFunc: func(mt *mockservices.MockITransfers, mb *mockservices.MockIBudgets, mua *mockservices.MockIUserAccounts) {
+				userExistsCall := mua.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getUserCall := mua.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(userExistsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+						Email:        nil,
+					}, nil)
+
+				budgetExistsCall := mb.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					After(getUserCall).
+					Times(1).
+					Return(true, nil)
+
+				authorizeCall := mb.EXPECT().
+					Authorize(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq(models.BudgetRoleEditor)).
+					After(budgetExistsCall).
+					Times(1).
+					Return(nil)
+
+				mt.EXPECT().
+					Create(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__bid_1__"), gomock.Eq("__baid_1__"), gomock.Eq("__baid_3__"), gomock.Eq(time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC)), gomock.Eq(int64(5000)), gomock.Nil()).
+					After(authorizeCall).
+					Times(1).
+					Return(nil, constants.ErrInvalidTransferBankAccounts)
+			},
+			requestMethod: http.MethodPost,
+			requestBody: `{
+				"from_bank_account_id": "__baid_1__",
+				"to_bank_account_id": "__baid_3__",
+				"date": "2022-03-02",
+				"amount": 5000
+			}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"errors":[{"message":"transfer bank accounts must be two different accounts in the same budget"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockTransfersService := mockservices.NewMockITransfers(mockCtrl)
+			mockBudgetsService := mockservices.NewMockIBudgets(mockCtrl)
+			mockUserAccountsService := mockservices.NewMockIUserAccounts(mockCtrl)
+
+			tt.mockSetupFunc(mockTransfersService, mockBudgetsService, mockUserAccountsService)
+
+			tc := &controllers.Transfers{
+				STransfers:    mockTransfersService,
+				SBudgets:      mockBudgetsService,
+				SUserAccounts: mockUserAccountsService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			tc.Post().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
@@ -54,7 +54,7 @@ func (ua *UserAccounts) Post() http.HandlerFunc {
 			return
 		}
 
-		createdUserAccount, err := ua.Service.Create(requestBody.Username, requestBody.Password, requestBody.Email)
+		createdUserAccount, err := ua.Service.Create(r.Context(), requestBody.Username, requestBody.Password, requestBody.Email)
 		switch err {
 		case constants.ErrUserExists:
 			writeResponse(rw, http.StatusConflict, errorsResponseFromErrors(err))
@@ -81,3 +81,110 @@ func (ua *UserAccounts) Post() http.HandlerFunc {
 		})
 	}
 }
+
+type putPasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (ua *UserAccounts) PutPassword() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		userAccount, ok := validateUser(rw, r, ua.Service)
+		if !ok {
+			return
+		}
+		if !validateNotAPIKey(rw, r) {
+			return
+		}
+
+		sessionID, ok := r.Context().Value(constants.SessionIDContextKey).(string)
+		if !ok {
+			log.Error("Could not retrieve session ID from context")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromMessages("Session not found in provided token. Please contact the site administrator"))
+			return
+		}
+
+		var requestBody putPasswordRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		err = ua.Service.ChangePassword(r.Context(), userAccount.Username, sessionID, requestBody.CurrentPassword, requestBody.NewPassword)
+		switch err {
+		case constants.ErrIncorrectPassword:
+			writeResponse(rw, http.StatusForbidden, errorsResponseFromErrors(err))
+			return
+		case constants.ErrInvalidPassword:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error changing password")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type postPasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+func (ua *UserAccounts) PostPasswordReset() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var requestBody postPasswordResetRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		err = ua.Service.RequestPasswordReset(r.Context(), requestBody.Email)
+		if err != nil {
+			log.WithError(err).Error("Error requesting password reset")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		// always accepted, whether or not the email belongs to a user
+		rw.WriteHeader(http.StatusAccepted)
+	}
+}
+
+type postPasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+func (ua *UserAccounts) PostPasswordResetConfirm() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var requestBody postPasswordResetConfirmRequest
+		err := unmarshalRequestBody(r.Body, &requestBody)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		}
+
+		err = ua.Service.ResetPassword(r.Context(), requestBody.Token, requestBody.NewPassword)
+		switch err {
+		case constants.ErrInvalidResetToken:
+			fallthrough
+		case constants.ErrInvalidPassword:
+			writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+			return
+		case nil:
+			// noop, continue past switch
+		default:
+			log.WithError(err).Error("Error resetting password")
+			writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
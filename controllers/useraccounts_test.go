@@ -42,12 +42,12 @@ func TestUserAccountsGet(t *testing.T) {
 			},
 			mockSetupFunc: func(m *mockservices.MockIUserAccounts) {
 				existsCall := m.EXPECT().
-					ExistsByUsername(gomock.Eq("user_1")).
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
 					Times(1).
 					Return(true, nil)
 
 				m.EXPECT().
-					GetInfo(gomock.Eq("user_1")).
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
 					After(existsCall).
 					Times(1).
 					Return(&models.UserAccount{
@@ -116,7 +116,7 @@ func TestUserAccountsPost(t *testing.T) {
 			},
 			mockSetupFunc: func(m *mockservices.MockIUserAccounts) {
 				m.EXPECT().
-					Create(gomock.Eq("user_1"), gomock.Eq("password"), gomock.Eq(pointerify("user1@testing.com"))).
+					Create(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("password"), gomock.Eq(pointerify("user1@testing.com"))).
 					Times(1).
 					Return(&models.UserAccount{
 						ID:           "__uaid_1__",
@@ -166,3 +166,167 @@ func TestUserAccountsPost(t *testing.T) {
 		})
 	}
 }
+
+func TestUserAccountsPutPassword(t *testing.T) {
+	tests := []struct {
+		name                 string
+		endpoint             string
+		requestSetupFunc     func(r *http.Request) *http.Request
+		mockSetupFunc        func(m *mockservices.MockIUserAccounts)
+		requestMethod        string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "put password - success",
+			endpoint: "/api/v1/user-accounts/password",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.SessionIDContextKey, "__sid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIUserAccounts) {
+				existsCall := m.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getInfoCall := m.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(existsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+					}, nil)
+
+				m.EXPECT().
+					ChangePassword(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("__sid_1__"), gomock.Eq("old password"), gomock.Eq("a much better password")).
+					After(getInfoCall).
+					Times(1).
+					Return(nil)
+			},
+			requestMethod: http.MethodPut,
+			requestBody: `{
+				"current_password": "old password",
+				"new_password": "a much better password"
+			}`,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: ``,
+		},
+		{
+			name:     "put password - incorrect current password",
+			endpoint: "/api/v1/user-accounts/password",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.SessionIDContextKey, "__sid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIUserAccounts) {
+				existsCall := m.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				getInfoCall := m.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(existsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+					}, nil)
+
+				m.EXPECT().
+					ChangePassword(gomock.Any(), gomock.Eq("user_1"), gomock.Eq("__sid_1__"), gomock.Eq("wrong password"), gomock.Eq("a much better password")).
+					After(getInfoCall).
+					Times(1).
+					Return(constants.ErrIncorrectPassword)
+			},
+			requestMethod: http.MethodPut,
+			requestBody: `{
+				"current_password": "wrong password",
+				"new_password": "a much better password"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [
+					{
+						"message": "incorrect password"
+					}
+				]
+			}`,
+		},
+		{
+			name:     "put password - authenticated with api key",
+			endpoint: "/api/v1/user-accounts/password",
+			requestSetupFunc: func(r *http.Request) *http.Request {
+				r = r.WithContext(context.WithValue(r.Context(), constants.UsernameContextKey, "user_1"))
+				r = r.WithContext(context.WithValue(r.Context(), constants.APIKeyIDContextKey, "__akid_1__"))
+				return r
+			},
+			mockSetupFunc: func(m *mockservices.MockIUserAccounts) {
+				existsCall := m.EXPECT().
+					ExistsByUsername(gomock.Any(), gomock.Eq("user_1")).
+					Times(1).
+					Return(true, nil)
+
+				m.EXPECT().
+					GetInfo(gomock.Any(), gomock.Eq("user_1")).
+					After(existsCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:           "__uaid_1__",
+						Username:     "user_1",
+						PasswordHash: "__hash__",
+					}, nil)
+
+				m.EXPECT().
+					ChangePassword(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			requestMethod: http.MethodPut,
+			requestBody: `{
+				"current_password": "old password",
+				"new_password": "a much better password"
+			}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponseBody: `{
+				"errors": [
+					{
+						"message": "This cannot be done using an API key"
+					}
+				]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserAccountService := mockservices.NewMockIUserAccounts(gomock.NewController(t))
+
+			tt.mockSetupFunc(mockUserAccountService)
+
+			ua := &controllers.UserAccounts{
+				Service: mockUserAccountService,
+			}
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.requestMethod, tt.endpoint, strings.NewReader(tt.requestBody))
+			r = tt.requestSetupFunc(r)
+
+			ua.PutPassword().ServeHTTP(rw, r)
+
+			assert.Equal(t, tt.expectedStatusCode, rw.Result().StatusCode)
+			resBody, _ := io.ReadAll(rw.Result().Body)
+			if json.Valid(resBody) && json.Valid([]byte(tt.expectedResponseBody)) {
+				assert.JSONEq(t, tt.expectedResponseBody, string(resBody))
+			} else {
+				assert.Equal(t, tt.expectedResponseBody, string(resBody))
+			}
+		})
+	}
+}
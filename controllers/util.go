@@ -1,13 +1,17 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/paulwrubel/moneybags-server/constants"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/paulwrubel/moneybags-server/services"
 	log "github.com/sirupsen/logrus"
 )
@@ -28,7 +32,7 @@ func validateUser(rw http.ResponseWriter, r *http.Request, uaService services.IU
 		return nil, false
 	}
 
-	userExists, err := uaService.ExistsByUsername(username)
+	userExists, err := uaService.ExistsByUsername(r.Context(), username)
 	if err != nil {
 		log.Errorf("Error checking if user exists: %s", err.Error())
 		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
@@ -39,7 +43,7 @@ func validateUser(rw http.ResponseWriter, r *http.Request, uaService services.IU
 		return nil, false
 	}
 
-	userAccount, err := uaService.GetInfo(username)
+	userAccount, err := uaService.GetInfo(r.Context(), username)
 	if err != nil {
 		log.Errorf("Error getting user account: %s", err.Error())
 		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
@@ -48,6 +52,378 @@ func validateUser(rw http.ResponseWriter, r *http.Request, uaService services.IU
 	return userAccount, true
 }
 
+// validateNotAPIKey refuses requests authenticated with an API key, for
+// actions which need a session, such as logging out, or which a leaked key
+// should not be able to take, such as issuing more keys
+func validateNotAPIKey(rw http.ResponseWriter, r *http.Request) bool {
+	if _, ok := r.Context().Value(constants.APIKeyIDContextKey).(string); ok {
+		writeResponse(rw, http.StatusForbidden, errorsResponseFromMessages("This cannot be done using an API key"))
+		return false
+	}
+	return true
+}
+
+// validateBudget checks that the budget exists and that the user's role on it
+// is at least role. Non-members and members whose role falls short get 403.
+func validateBudget(ctx context.Context, rw http.ResponseWriter, bService services.IBudgets, userAccountID, budgetID string, role models.BudgetRole) bool {
+	exists, err := bService.ExistsByID(ctx, budgetID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if budget exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Budget does not exist"))
+		return false
+	}
+
+	err = bService.Authorize(ctx, userAccountID, budgetID, role)
+	switch err {
+	case constants.ErrNotBudgetMember:
+		writeResponse(rw, http.StatusForbidden, errorsResponseFromMessages("Budget does not belong to user"))
+		return false
+	case constants.ErrInsufficientBudgetRole:
+		writeResponse(rw, http.StatusForbidden, errorsResponseFromMessages(fmt.Sprintf("Budget role %s is required", role)))
+		return false
+	case nil:
+		// noop, continue past switch
+	default:
+		log.WithError(err).Error("Error authorizing budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	return true
+}
+
+func validateBankAccount(ctx context.Context, rw http.ResponseWriter, baService services.IBankAccounts, budgetID, bankAccountID string) bool {
+	exists, err := baService.ExistsByID(ctx, bankAccountID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if bank account exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Bank account does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := baService.BelongsTo(ctx, budgetID, bankAccountID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if bank account belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Bank account does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateTransaction(ctx context.Context, rw http.ResponseWriter, tService services.ITransactions, bankAccountID, transactionID string) bool {
+	exists, err := tService.ExistsByID(ctx, transactionID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if transaction exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Transaction does not exist"))
+		return false
+	}
+
+	belongsToBankAccount, err := tService.BelongsTo(ctx, bankAccountID, transactionID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if transaction belongs to bank account")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBankAccount {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Transaction does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateScheduledTransaction(ctx context.Context, rw http.ResponseWriter, stService services.IScheduledTransactions, bankAccountID, scheduledTransactionID string) bool {
+	exists, err := stService.ExistsByID(ctx, scheduledTransactionID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if scheduled transaction exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Scheduled transaction does not exist"))
+		return false
+	}
+
+	belongsToBankAccount, err := stService.BelongsTo(ctx, bankAccountID, scheduledTransactionID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if scheduled transaction belongs to bank account")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBankAccount {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Scheduled transaction does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateTransfer(ctx context.Context, rw http.ResponseWriter, tService services.ITransfers, budgetID, transferID string) bool {
+	exists, err := tService.ExistsByID(ctx, transferID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if transfer exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Transfer does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := tService.BelongsTo(ctx, budgetID, transferID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if transfer belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Transfer does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateCategoryGroup(ctx context.Context, rw http.ResponseWriter, cgService services.ICategoryGroups, budgetID, categoryGroupID string) bool {
+	exists, err := cgService.ExistsByID(ctx, categoryGroupID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if category group exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Category group does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := cgService.BelongsTo(ctx, budgetID, categoryGroupID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if category group belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Category group does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateCategory(ctx context.Context, rw http.ResponseWriter, cService services.ICategories, budgetID, categoryID string) bool {
+	exists, err := cService.ExistsByID(ctx, categoryID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if category exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Category does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := cService.BelongsTo(ctx, budgetID, categoryID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if category belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Category does not exist"))
+		return false
+	}
+	return true
+}
+
+func validatePayee(ctx context.Context, rw http.ResponseWriter, pService services.IPayees, budgetID, payeeID string) bool {
+	exists, err := pService.ExistsByID(ctx, payeeID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if payee exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Payee does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := pService.BelongsTo(ctx, budgetID, payeeID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if payee belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Payee does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateCategorizationRule(ctx context.Context, rw http.ResponseWriter, crService services.ICategorizationRules, budgetID, ruleID string) bool {
+	exists, err := crService.ExistsByID(ctx, ruleID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if categorization rule exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Categorization rule does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := crService.BelongsTo(ctx, budgetID, ruleID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if categorization rule belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Categorization rule does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateBudgetMembership(ctx context.Context, rw http.ResponseWriter, bmService services.IBudgetMemberships, budgetID, userAccountID string) bool {
+	exists, err := bmService.ExistsByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if budget member exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Budget member does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateBudgetInvitation(ctx context.Context, rw http.ResponseWriter, biService services.IBudgetInvitations, budgetID, invitationID string) bool {
+	exists, err := biService.ExistsByID(ctx, invitationID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if budget invitation exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Invitation does not exist"))
+		return false
+	}
+
+	belongsToBudget, err := biService.BelongsTo(ctx, budgetID, invitationID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if budget invitation belongs to budget")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToBudget {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Invitation does not exist"))
+		return false
+	}
+	return true
+}
+
+// validateReceivedBudgetInvitation checks that the invitation exists and was
+// sent to the user. Invitations sent to other users are reported as missing.
+func validateReceivedBudgetInvitation(ctx context.Context, rw http.ResponseWriter, biService services.IBudgetInvitations, userAccountID, invitationID string) bool {
+	exists, err := biService.ExistsByID(ctx, invitationID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if budget invitation exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Invitation does not exist"))
+		return false
+	}
+
+	isForUser, err := biService.IsFor(ctx, userAccountID, invitationID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if budget invitation is for user")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !isForUser {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("Invitation does not exist"))
+		return false
+	}
+	return true
+}
+
+func validateAPIKey(ctx context.Context, rw http.ResponseWriter, akService services.IAPIKeys, userAccountID, apiKeyID string) bool {
+	exists, err := akService.ExistsByID(ctx, apiKeyID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if api key exists")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !exists {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("API key does not exist"))
+		return false
+	}
+
+	belongsToUser, err := akService.BelongsTo(ctx, userAccountID, apiKeyID)
+	if err != nil {
+		log.WithError(err).Error("Error checking if api key belongs to user")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return false
+	}
+	if !belongsToUser {
+		writeResponse(rw, http.StatusNotFound, errorsResponseFromMessages("API key does not exist"))
+		return false
+	}
+	return true
+}
+
+// validatePage reads the limit, sort and cursor query parameters of a list,
+// sorted by one of sortKeys
+func validatePage(rw http.ResponseWriter, r *http.Request, sortKeys []pagination.SortKey, defaultSort string) (*pagination.Params, bool) {
+	page, err := pagination.ParseParams(r.URL.Query(), sortKeys, defaultSort)
+	switch err {
+	case constants.ErrInvalidPageLimit:
+		writeResponse(rw, http.StatusBadRequest, errorsResponseFromMessages("limit must be between 1 and "+strconv.Itoa(constants.MaxPageLimit)))
+		return nil, false
+	case constants.ErrInvalidSortKey:
+		sortKeyNames := []string{}
+		for _, key := range sortKeys {
+			sortKeyNames = append(sortKeyNames, key.Name)
+		}
+		writeResponse(rw, http.StatusBadRequest, errorsResponseFromMessages("sort must be one of "+strings.Join(sortKeyNames, ", ")+", optionally prefixed by -"))
+		return nil, false
+	case constants.ErrInvalidCursor:
+		writeResponse(rw, http.StatusBadRequest, errorsResponseFromErrors(err))
+		return nil, false
+	case nil:
+		// noop, continue past switch
+	default:
+		log.WithError(err).Error("Error parsing page")
+		writeResponse(rw, http.StatusInternalServerError, errorsResponseFromErrors(err))
+		return nil, false
+	}
+	return page, true
+}
+
+// encodeCursor returns the cursor as it is given to clients, or nil if there
+// is no next page
+func encodeCursor(cursor *pagination.Cursor) *string {
+	if cursor == nil {
+		return nil
+	}
+	encoded := cursor.Encode()
+	return &encoded
+}
+
 func unmarshalRequestBody(body io.Reader, dst interface{}) error {
 	bodyBytes, err := io.ReadAll(body)
 	if err != nil {
@@ -0,0 +1,107 @@
+package currency_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/currency"
+	"github.com/stretchr/testify/assert"
+)
+
+func rat(s string) *big.Rat {
+	r, _ := new(big.Rat).SetString(s)
+	return r
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name           string
+		amount         int64
+		from           string
+		to             string
+		rate           *big.Rat
+		expectedAmount int64
+	}{
+		{
+			name:           "same minor units",
+			amount:         10000,
+			from:           "EUR",
+			to:             "USD",
+			rate:           rat("1.1128"),
+			expectedAmount: 11128,
+		},
+		{
+			name:           "rounds half away from zero",
+			amount:         5,
+			from:           "EUR",
+			to:             "USD",
+			rate:           rat("1.5"),
+			expectedAmount: 8,
+		},
+		{
+			name:           "rounds negative half away from zero",
+			amount:         -5,
+			from:           "EUR",
+			to:             "USD",
+			rate:           rat("1.5"),
+			expectedAmount: -8,
+		},
+		{
+			name:           "rounds down below half",
+			amount:         -4599,
+			from:           "GBP",
+			to:             "USD",
+			rate:           rat("1.31234"),
+			expectedAmount: -6035,
+		},
+		{
+			name:           "into currency without minor units",
+			amount:         4599,
+			from:           "USD",
+			to:             "JPY",
+			rate:           rat("115.27"),
+			expectedAmount: 5301,
+		},
+		{
+			name:           "from currency without minor units",
+			amount:         5301,
+			from:           "JPY",
+			to:             "USD",
+			rate:           rat("0.0086753"),
+			expectedAmount: 4599,
+		},
+		{
+			name:           "into currency with three minor units",
+			amount:         10000,
+			from:           "USD",
+			to:             "KWD",
+			rate:           rat("0.30345"),
+			expectedAmount: 30345,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedAmount, currency.Convert(tt.amount, tt.from, tt.to, tt.rate))
+		})
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := currency.ParseRates(strings.NewReader("date,base,quote,rate\n2022-03-01,eur,USD,1.1128\n2022-03-01, GBP, USD, 1.3312\n"))
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "EUR", rates[0].Base)
+	assert.Equal(t, "USD", rates[0].Quote)
+	assert.Equal(t, time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), rates[0].Date)
+	assert.Equal(t, 0, rates[0].Rate.Cmp(rat("1.1128")))
+	assert.Equal(t, "GBP", rates[1].Base)
+
+	_, err = currency.ParseRates(strings.NewReader("date,base,quote,rate\n2022-03-01,EUR,XXX,1.1\n"))
+	assert.EqualError(t, err, `row 1: invalid currency "XXX"`)
+
+	_, err = currency.ParseRates(strings.NewReader("date,base,quote,rate\n2022-03-01,EUR,USD,-1\n"))
+	assert.EqualError(t, err, `row 1: invalid rate "-1"`)
+}
@@ -0,0 +1,69 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+// ParseRates reads exchange rates from CSV with a header row followed by
+// rows of date, base currency, quote currency and rate, for example:
+//
+//	date,base,quote,rate
+//	2022-03-01,EUR,USD,1.1128
+func ParseRates(r io.Reader) ([]*models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	_, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading header row: %w", err)
+	}
+
+	rates := []*models.ExchangeRate{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading csv: %w", err)
+		}
+
+		date, err := time.Parse(constants.DateLayout, strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q", line, record[0])
+		}
+		base, quote := Normalize(record[1]), Normalize(record[2])
+		if !Valid(base) {
+			return nil, fmt.Errorf("row %d: invalid currency %q", line, record[1])
+		}
+		if !Valid(quote) {
+			return nil, fmt.Errorf("row %d: invalid currency %q", line, record[2])
+		}
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(record[3]))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("row %d: invalid rate %q", line, record[3])
+		}
+
+		rates = append(rates, &models.ExchangeRate{
+			Base:  base,
+			Quote: quote,
+			Date:  date,
+			Rate:  rate,
+		})
+	}
+
+	return rates, nil
+}
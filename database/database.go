@@ -2,8 +2,16 @@ package database
 
 //go:generate mockgen -source=$GOFILE -destination=../mocks/database/mock_$GOFILE -package=mockdatabase
 
-import "github.com/jackc/pgtype/pgxtype"
+import (
+	"context"
 
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+)
+
+// IHandler runs statements against either the pool or a transaction. Begin
+// starts a transaction on the pool, and a savepoint within a transaction.
 type IHandler interface {
 	pgxtype.Querier
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
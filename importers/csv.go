@@ -0,0 +1,258 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type AmountSign string
+
+const (
+	// AmountSignInflowPositive means positive amounts are money coming in,
+	// as exported by most checking and savings accounts
+	AmountSignInflowPositive AmountSign = "inflow_positive"
+	// AmountSignOutflowPositive means positive amounts are money going out,
+	// as exported by many credit card accounts
+	AmountSignOutflowPositive AmountSign = "outflow_positive"
+)
+
+// CSVMapping describes how the columns of a CSV export map onto entries.
+// Columns are referred to by their header. Either AmountColumn, or both
+// DebitColumn and CreditColumn, must be set.
+type CSVMapping struct {
+	Delimiter        string
+	SkipRows         int
+	DateColumn       string
+	DateFormat       string
+	AmountColumn     string
+	AmountSign       AmountSign
+	DebitColumn      string
+	CreditColumn     string
+	PayeeColumn      string
+	MemoColumn       string
+	DecimalSeparator string
+}
+
+// ParseCSV parses a CSV export using the given mapping. Errors refer to rows
+// by their position after the header, counting from 1. Amounts are converted
+// to minorUnits decimal places, those of the currency of the account being
+// imported into.
+func ParseCSV(r io.Reader, mapping CSVMapping, minorUnits int) ([]*Entry, error) {
+	mapping, err := withCSVDefaults(mapping)
+	if err != nil {
+		return nil, err
+	}
+	delimiter, _ := utf8.DecodeRuneInString(mapping.Delimiter)
+	decimalSeparator, _ := utf8.DecodeRuneInString(mapping.DecimalSeparator)
+	dateLayout := dateFormatToLayout(mapping.DateFormat)
+
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	for i := 0; i < mapping.SkipRows; i++ {
+		_, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("error skipping row %d: %w", i+1, err)
+		}
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading header row: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[normalizeColumnName(name)] = i
+	}
+	column := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		index, ok := columns[normalizeColumnName(name)]
+		if !ok {
+			return 0, fmt.Errorf("column %q not found in header", name)
+		}
+		return index, nil
+	}
+
+	dateIndex, err := column(mapping.DateColumn)
+	if err != nil {
+		return nil, err
+	}
+	amountIndex, err := column(mapping.AmountColumn)
+	if err != nil {
+		return nil, err
+	}
+	debitIndex, err := column(mapping.DebitColumn)
+	if err != nil {
+		return nil, err
+	}
+	creditIndex, err := column(mapping.CreditColumn)
+	if err != nil {
+		return nil, err
+	}
+	payeeIndex, err := column(mapping.PayeeColumn)
+	if err != nil {
+		return nil, err
+	}
+	memoIndex, err := column(mapping.MemoColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*Entry{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading csv: %w", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		field := func(index int) string {
+			if index < 0 || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		date, err := time.Parse(dateLayout, field(dateIndex))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q", line, field(dateIndex))
+		}
+
+		var amount int64
+		if amountIndex >= 0 {
+			amount, err = parseAmount(field(amountIndex), decimalSeparator, minorUnits)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", line, err)
+			}
+			if mapping.AmountSign == AmountSignOutflowPositive {
+				amount = -amount
+			}
+		} else {
+			debit, err := parseOptionalAmount(field(debitIndex), decimalSeparator, minorUnits)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", line, err)
+			}
+			credit, err := parseOptionalAmount(field(creditIndex), decimalSeparator, minorUnits)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", line, err)
+			}
+			amount = abs(credit) - abs(debit)
+		}
+
+		var memo *string
+		if memoText := field(memoIndex); memoText != "" {
+			memo = &memoText
+		}
+
+		entries = append(entries, &Entry{
+			Date:   date,
+			Amount: amount,
+			Payee:  field(payeeIndex),
+			Memo:   memo,
+		})
+	}
+
+	return entries, nil
+}
+
+func withCSVDefaults(mapping CSVMapping) (CSVMapping, error) {
+	if mapping.Delimiter == "" {
+		mapping.Delimiter = ","
+	}
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = "YYYY-MM-DD"
+	}
+	if mapping.AmountSign == "" {
+		mapping.AmountSign = AmountSignInflowPositive
+	}
+	if mapping.DecimalSeparator == "" {
+		mapping.DecimalSeparator = "."
+	}
+
+	if utf8.RuneCountInString(mapping.Delimiter) != 1 {
+		return mapping, errors.New("delimiter must be a single character")
+	}
+	if mapping.DecimalSeparator != "." && mapping.DecimalSeparator != "," {
+		return mapping, errors.New("decimal separator must be \".\" or \",\"")
+	}
+	if mapping.SkipRows < 0 {
+		return mapping, errors.New("skip rows must not be negative")
+	}
+	if mapping.DateColumn == "" {
+		return mapping, errors.New("date column is required")
+	}
+	if mapping.PayeeColumn == "" {
+		return mapping, errors.New("payee column is required")
+	}
+	switch mapping.AmountSign {
+	case AmountSignInflowPositive, AmountSignOutflowPositive:
+		// valid
+	default:
+		return mapping, fmt.Errorf("invalid amount sign %q", mapping.AmountSign)
+	}
+	hasAmount := mapping.AmountColumn != ""
+	hasDebitCredit := mapping.DebitColumn != "" && mapping.CreditColumn != ""
+	if hasAmount == hasDebitCredit {
+		return mapping, errors.New("either an amount column, or both debit and credit columns, are required")
+	}
+
+	return mapping, nil
+}
+
+// dateFormatToLayout converts a human date format such as "MM/DD/YYYY" into
+// a Go time layout
+func dateFormatToLayout(format string) string {
+	replacer := strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "01",
+		"DD", "02",
+		"M", "1",
+		"D", "2",
+	)
+	return replacer.Replace(format)
+}
+
+func normalizeColumnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseOptionalAmount(s string, decimalSeparator rune, minorUnits int) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return parseAmount(s, decimalSeparator, minorUnits)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,147 @@
+package importers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/importers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		mapping         importers.CSVMapping
+		minorUnits      int
+		expectedEntries []*importers.Entry
+		expectedError   string
+	}{
+		{
+			name: "single amount column",
+			data: "Date,Payee,Amount\n" +
+				"2022-03-01,Grocery Store,-45.99\n" +
+				"2022-03-02,Employer,\"$1,500\"\n" +
+				"2022-03-03,Refund,(7.5)\n",
+			mapping: importers.CSVMapping{
+				DateColumn:   "Date",
+				AmountColumn: "Amount",
+				PayeeColumn:  "Payee",
+			},
+			minorUnits: 2,
+			expectedEntries: []*importers.Entry{
+				{Date: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: -4599, Payee: "Grocery Store"},
+				{Date: time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC), Amount: 150000, Payee: "Employer"},
+				{Date: time.Date(2022, time.March, 3, 0, 0, 0, 0, time.UTC), Amount: -750, Payee: "Refund"},
+			},
+		},
+		{
+			name: "outflow positive with preamble and european numbers",
+			data: "Account 1234\n" +
+				"\n" +
+				"Buchungstag;Empfänger;Betrag\n" +
+				"01.03.2022;Bäckerei;1.234,50\n" +
+				"02.03.2022;Gutschrift;-20\n",
+			mapping: importers.CSVMapping{
+				Delimiter:        ";",
+				SkipRows:         1,
+				DateColumn:       "buchungstag",
+				DateFormat:       "DD.MM.YYYY",
+				AmountColumn:     "Betrag",
+				AmountSign:       importers.AmountSignOutflowPositive,
+				PayeeColumn:      "Empfänger",
+				DecimalSeparator: ",",
+			},
+			minorUnits: 2,
+			expectedEntries: []*importers.Entry{
+				{Date: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: -123450, Payee: "Bäckerei"},
+				{Date: time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC), Amount: 2000, Payee: "Gutschrift"},
+			},
+		},
+		{
+			name: "currency without minor units",
+			data: "Date,Payee,Amount\n" +
+				"2022-03-01,Ramen Shop,\"-1,500\"\n" +
+				"2022-03-02,Employer,300000.00\n",
+			mapping: importers.CSVMapping{
+				DateColumn:   "Date",
+				AmountColumn: "Amount",
+				PayeeColumn:  "Payee",
+			},
+			minorUnits: 0,
+			expectedEntries: []*importers.Entry{
+				{Date: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: -1500, Payee: "Ramen Shop"},
+				{Date: time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC), Amount: 300000, Payee: "Employer"},
+			},
+		},
+		{
+			name: "currency without minor units - fractional amount",
+			data: "Date,Payee,Amount\n" +
+				"2022-03-01,Ramen Shop,-12.5\n",
+			mapping: importers.CSVMapping{
+				DateColumn:   "Date",
+				AmountColumn: "Amount",
+				PayeeColumn:  "Payee",
+			},
+			minorUnits:    0,
+			expectedError: "row 1: invalid amount \"-12.5\": too many decimal places",
+		},
+		{
+			name: "currency with three minor units",
+			data: "Date,Payee,Debit,Credit\n" +
+				"2022-03-01,Grocery Store,12.345,\n" +
+				"2022-03-02,Employer,,\"1,000.5\"\n",
+			mapping: importers.CSVMapping{
+				DateColumn:   "Date",
+				DebitColumn:  "Debit",
+				CreditColumn: "Credit",
+				PayeeColumn:  "Payee",
+			},
+			minorUnits: 3,
+			expectedEntries: []*importers.Entry{
+				{Date: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: -12345, Payee: "Grocery Store"},
+				{Date: time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC), Amount: 1000500, Payee: "Employer"},
+			},
+		},
+		{
+			name: "invalid date",
+			data: "Date,Payee,Amount\n" +
+				"2022-03-01,Grocery Store,-45.99\n" +
+				"03/02/2022,Employer,1500\n",
+			mapping: importers.CSVMapping{
+				DateColumn:   "Date",
+				AmountColumn: "Amount",
+				PayeeColumn:  "Payee",
+			},
+			minorUnits:    2,
+			expectedError: "row 2: invalid date \"03/02/2022\"",
+		},
+		{
+			name: "amount and debit columns both set",
+			data: "Date,Payee,Amount,Debit,Credit\n",
+			mapping: importers.CSVMapping{
+				DateColumn:   "Date",
+				AmountColumn: "Amount",
+				DebitColumn:  "Debit",
+				CreditColumn: "Credit",
+				PayeeColumn:  "Payee",
+			},
+			minorUnits:    2,
+			expectedError: "either an amount column, or both debit and credit columns, are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := importers.ParseCSV(strings.NewReader(tt.data), tt.mapping, tt.minorUnits)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEntries, entries)
+		})
+	}
+}
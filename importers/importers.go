@@ -0,0 +1,107 @@
+// Package importers parses bank statement exports into entries which can be
+// imported as transactions
+package importers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Entry is a single statement line. Amounts are in minor units, with
+// inflows positive and outflows negative, as for transactions.
+type Entry struct {
+	Date   time.Time
+	Amount int64
+	Payee  string
+	Memo   *string
+	// FITID is the bank's own identifier for the entry, if the format has one
+	FITID *string
+}
+
+// Statement is the result of parsing a statement export
+type Statement struct {
+	Entries []*Entry
+	// LedgerBalance is the closing balance reported by the bank, if the
+	// format includes one
+	LedgerBalance *Balance
+}
+
+type Balance struct {
+	Date   time.Time
+	Amount int64
+}
+
+// parseAmount parses a decimal amount such as "1,234.56", "-12.3", "$5" or
+// "(7.00)" into minor units of a currency with minorUnits decimal places.
+// Decimal places beyond those are only accepted if they are zeros, since
+// some banks pad every amount to two places whatever the currency.
+func parseAmount(s string, decimalSeparator rune, minorUnits int) (int64, error) {
+	original := s
+	s = strings.TrimSpace(s)
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasPrefix(s, "-") {
+		negative = !negative
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, "-") {
+		negative = !negative
+		s = s[:len(s)-1]
+	}
+
+	var whole, fraction int64
+	fractionDigits := -1
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digit := int64(r - '0')
+			if fractionDigits < 0 {
+				whole = whole*10 + digit
+			} else {
+				fractionDigits++
+				if fractionDigits > minorUnits {
+					if digit != 0 {
+						return 0, fmt.Errorf("invalid amount %q: too many decimal places", original)
+					}
+					continue
+				}
+				fraction = fraction*10 + digit
+			}
+		case r == decimalSeparator:
+			if fractionDigits >= 0 {
+				return 0, fmt.Errorf("invalid amount %q", original)
+			}
+			fractionDigits = 0
+		case r == ',' || r == '.' || r == ' ' || r == '\'':
+			// thousands separators
+			if fractionDigits >= 0 {
+				return 0, fmt.Errorf("invalid amount %q", original)
+			}
+		case strings.ContainsRune("$€£¥", r):
+			// currency symbols
+		default:
+			return 0, fmt.Errorf("invalid amount %q", original)
+		}
+	}
+	// pad the fraction out to the currency's decimal places
+	for i := fractionDigits; i < minorUnits; i++ {
+		fraction *= 10
+	}
+
+	amount := whole
+	for i := 0; i < minorUnits; i++ {
+		amount *= 10
+	}
+	amount += fraction
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
@@ -0,0 +1,226 @@
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseOFX parses an OFX or QFX statement download. Both the SGML based
+// OFX 1.x format, in which elements need not be closed, and the XML based
+// OFX 2.x format are supported, for bank and credit card statements.
+// Amounts are converted to minorUnits decimal places, those of the currency
+// of the account being imported into.
+func ParseOFX(r io.Reader, minorUnits int) (*Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ofx: %w", err)
+	}
+
+	tokens, err := tokenizeOFX(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{
+		Entries: []*Entry{},
+	}
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != ofxOpen {
+			continue
+		}
+		switch tokens[i].name {
+		case "STMTTRN":
+			var fields map[string]string
+			fields, i = collectOFXAggregate(tokens, i)
+			entry, err := ofxEntry(fields, minorUnits)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d: %w", len(statement.Entries)+1, err)
+			}
+			statement.Entries = append(statement.Entries, entry)
+		case "LEDGERBAL":
+			var fields map[string]string
+			fields, i = collectOFXAggregate(tokens, i)
+			balance, err := ofxBalance(fields, minorUnits)
+			if err != nil {
+				return nil, fmt.Errorf("ledger balance: %w", err)
+			}
+			statement.LedgerBalance = balance
+		}
+	}
+
+	return statement, nil
+}
+
+type ofxTokenKind int
+
+const (
+	ofxOpen ofxTokenKind = iota
+	ofxClose
+	ofxText
+)
+
+type ofxToken struct {
+	kind ofxTokenKind
+	name string
+	text string
+}
+
+// tokenizeOFX splits the body of an OFX document, starting at the <OFX>
+// element, into open tags, close tags and text
+func tokenizeOFX(data string) ([]ofxToken, error) {
+	start := strings.Index(strings.ToUpper(data), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("missing <OFX> element")
+	}
+	data = data[start:]
+
+	tokens := []ofxToken{}
+	for len(data) > 0 {
+		if data[0] != '<' {
+			end := strings.IndexByte(data, '<')
+			if end < 0 {
+				end = len(data)
+			}
+			text := strings.TrimSpace(data[:end])
+			if text != "" {
+				tokens = append(tokens, ofxToken{kind: ofxText, text: html.UnescapeString(text)})
+			}
+			data = data[end:]
+			continue
+		}
+
+		end := strings.IndexByte(data, '>')
+		if end < 0 {
+			return nil, errors.New("unterminated tag")
+		}
+		tag := strings.TrimSpace(data[1:end])
+		data = data[end+1:]
+
+		switch {
+		case strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+			// processing instructions and comments
+		case strings.HasPrefix(tag, "/"):
+			tokens = append(tokens, ofxToken{kind: ofxClose, name: strings.ToUpper(tag[1:])})
+		default:
+			tokens = append(tokens, ofxToken{kind: ofxOpen, name: strings.ToUpper(tag)})
+		}
+	}
+
+	return tokens, nil
+}
+
+// collectOFXAggregate gathers the leaf elements inside the aggregate opened
+// at tokens[start], keyed by name, and returns the index of its end tag.
+// Only leaves may omit their end tags, even in SGML documents. Leaves of
+// nested aggregates are included, which is enough for the flat structures
+// used in statements.
+func collectOFXAggregate(tokens []ofxToken, start int) (map[string]string, int) {
+	fields := map[string]string{}
+	depth := 0
+
+	i := start + 1
+	for ; i < len(tokens); i++ {
+		token := tokens[i]
+		switch token.kind {
+		case ofxOpen:
+			next := i + 1
+			switch {
+			case next < len(tokens) && tokens[next].kind == ofxText:
+				fields[token.name] = tokens[next].text
+				i = next
+				if i+1 < len(tokens) && tokens[i+1].kind == ofxClose && tokens[i+1].name == token.name {
+					i++
+				}
+			case next < len(tokens) && tokens[next].kind == ofxClose && tokens[next].name == token.name:
+				fields[token.name] = ""
+				i = next
+			default:
+				depth++
+			}
+		case ofxClose:
+			if depth == 0 {
+				return fields, i
+			}
+			depth--
+		}
+	}
+
+	return fields, i
+}
+
+func ofxEntry(fields map[string]string, minorUnits int) (*Entry, error) {
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseOFXAmount(fields["TRNAMT"], minorUnits)
+	if err != nil {
+		return nil, err
+	}
+
+	payee := fields["NAME"]
+	var memo *string
+	if memoText, ok := fields["MEMO"]; ok && memoText != "" {
+		if payee == "" {
+			payee = memoText
+		} else {
+			memo = &memoText
+		}
+	}
+
+	var fitID *string
+	if fitIDText, ok := fields["FITID"]; ok && fitIDText != "" {
+		fitID = &fitIDText
+	}
+
+	return &Entry{
+		Date:   date,
+		Amount: amount,
+		Payee:  payee,
+		Memo:   memo,
+		FITID:  fitID,
+	}, nil
+}
+
+func ofxBalance(fields map[string]string, minorUnits int) (*Balance, error) {
+	date, err := parseOFXDate(fields["DTASOF"])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseOFXAmount(fields["BALAMT"], minorUnits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balance{
+		Date:   date,
+		Amount: amount,
+	}, nil
+}
+
+// parseOFXDate parses the date part of an OFX datetime such as
+// "20220301120000.000[-5:EST]". The time and zone are ignored, as
+// transactions are dated by calendar day.
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	date, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return date, nil
+}
+
+// parseOFXAmount parses an OFX amount, which uses a period as the decimal
+// separator, although some banks use a comma instead
+func parseOFXAmount(s string, minorUnits int) (int64, error) {
+	if strings.Contains(s, ",") && !strings.Contains(s, ".") {
+		return parseAmount(s, ',', minorUnits)
+	}
+	return parseAmount(s, '.', minorUnits)
+}
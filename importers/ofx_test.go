@@ -0,0 +1,129 @@
+package importers_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/importers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		name              string
+		file              string
+		minorUnits        int
+		expectedStatement *importers.Statement
+	}{
+		{
+			name:       "sgml bank statement",
+			file:       "testdata/checking.ofx",
+			minorUnits: 2,
+			expectedStatement: &importers.Statement{
+				Entries: []*importers.Entry{
+					{
+						Date:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+						Amount: -4599,
+						Payee:  "GROCERY STORE #42",
+						Memo:   pointerify("POS PURCHASE"),
+						FITID:  pointerify("2022030101"),
+					},
+					{
+						Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+						Amount: 150000,
+						Payee:  "ACME CORP PAYROLL",
+						FITID:  pointerify("2022030201"),
+					},
+					{
+						Date:   time.Date(2022, time.March, 4, 0, 0, 0, 0, time.UTC),
+						Amount: -12000,
+						Payee:  "SMITH & SONS PLUMBING",
+						FITID:  pointerify("2022030401"),
+					},
+				},
+				LedgerBalance: &importers.Balance{
+					Date:   time.Date(2022, time.March, 5, 0, 0, 0, 0, time.UTC),
+					Amount: 233401,
+				},
+			},
+		},
+		{
+			name:       "xml credit card statement",
+			file:       "testdata/creditcard.qfx",
+			minorUnits: 2,
+			expectedStatement: &importers.Statement{
+				Entries: []*importers.Entry{
+					{
+						Date:   time.Date(2022, time.March, 3, 0, 0, 0, 0, time.UTC),
+						Amount: -1250,
+						Payee:  "COFFEE SHOP",
+						FITID:  pointerify("320220303"),
+					},
+					{
+						Date:   time.Date(2022, time.March, 8, 0, 0, 0, 0, time.UTC),
+						Amount: 20000,
+						Payee:  "PAYMENT - THANK YOU",
+						FITID:  pointerify("320220308"),
+					},
+				},
+				LedgerBalance: &importers.Balance{
+					Date:   time.Date(2022, time.March, 10, 0, 0, 0, 0, time.UTC),
+					Amount: -31250,
+				},
+			},
+		},
+		{
+			name:       "currency without minor units",
+			file:       "testdata/yen.ofx",
+			minorUnits: 0,
+			expectedStatement: &importers.Statement{
+				Entries: []*importers.Entry{
+					{
+						Date:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+						Amount: -1500,
+						Payee:  "RAMEN SHOP",
+						FITID:  pointerify("2022030101"),
+					},
+					{
+						Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+						Amount: 300000,
+						Payee:  "ACME KK PAYROLL",
+						FITID:  pointerify("2022030201"),
+					},
+				},
+				LedgerBalance: &importers.Balance{
+					Date:   time.Date(2022, time.March, 5, 0, 0, 0, 0, time.UTC),
+					Amount: 1234567,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Open(tt.file)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer file.Close()
+
+			statement, err := importers.ParseOFX(file, tt.minorUnits)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatement, statement)
+		})
+	}
+}
+
+func TestParseOFXInvalid(t *testing.T) {
+	_, err := importers.ParseOFX(strings.NewReader("OFXHEADER:100\r\n\r\n<OFX><STMTTRN><DTPOSTED>2022<TRNAMT>1.00</STMTTRN></OFX>"), 2)
+
+	assert.EqualError(t, err, "transaction 1: invalid date \"2022\"")
+}
+
+func pointerify(s string) *string {
+	p := s
+	return &p
+}
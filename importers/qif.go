@@ -0,0 +1,176 @@
+package importers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type QIFDateOrder string
+
+const (
+	QIFDateOrderMonthFirst QIFDateOrder = "month_first"
+	QIFDateOrderDayFirst   QIFDateOrder = "day_first"
+)
+
+// qifTransactionHeaders are the section headers which start a list of
+// transactions that can be imported
+var qifTransactionHeaders = map[string]bool{
+	"!type:bank":  true,
+	"!type:cash":  true,
+	"!type:ccard": true,
+	"!type:oth a": true,
+	"!type:oth l": true,
+}
+
+// ParseQIF parses a QIF export of a bank, cash or credit card account.
+// QIF dates don't say which order they are in, so it must be given.
+// Investment and list sections, such as categories, are skipped. Amounts are
+// converted to minorUnits decimal places, those of the currency of the
+// account being imported into.
+func ParseQIF(r io.Reader, dateOrder QIFDateOrder, minorUnits int) (*Statement, error) {
+	switch dateOrder {
+	case "":
+		dateOrder = QIFDateOrderMonthFirst
+	case QIFDateOrderMonthFirst, QIFDateOrderDayFirst:
+		// valid
+	default:
+		return nil, fmt.Errorf("invalid date order %q", dateOrder)
+	}
+
+	statement := &Statement{
+		Entries: []*Entry{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	inTransactions := false
+	fields := map[byte]string{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "!") {
+			inTransactions = qifTransactionHeaders[strings.ToLower(strings.TrimSpace(text))]
+			continue
+		}
+		if !inTransactions {
+			continue
+		}
+
+		if text[0] == '^' {
+			if len(fields) > 0 {
+				entry, err := qifEntry(fields, dateOrder, minorUnits)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				statement.Entries = append(statement.Entries, entry)
+			}
+			fields = map[byte]string{}
+			continue
+		}
+
+		code := text[0]
+		if _, ok := fields[code]; !ok {
+			// split lines repeat their codes, only the first is kept
+			fields[code] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading qif: %w", err)
+	}
+	if len(fields) > 0 {
+		return nil, errors.New("last transaction is not terminated with ^")
+	}
+
+	return statement, nil
+}
+
+func qifEntry(fields map[byte]string, dateOrder QIFDateOrder, minorUnits int) (*Entry, error) {
+	date, err := parseQIFDate(fields['D'], dateOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	amountText, ok := fields['T']
+	if !ok {
+		amountText = fields['U']
+	}
+	amount, err := parseAmount(amountText, '.', minorUnits)
+	if err != nil {
+		return nil, err
+	}
+
+	payee := fields['P']
+	var memo *string
+	if memoText, ok := fields['M']; ok && memoText != "" {
+		if payee == "" {
+			payee = memoText
+		} else {
+			memo = &memoText
+		}
+	}
+
+	return &Entry{
+		Date:   date,
+		Amount: amount,
+		Payee:  payee,
+		Memo:   memo,
+	}, nil
+}
+
+// parseQIFDate parses the many date styles found in QIF files, such as
+// "3/1/2022", "03/01/22", "3/ 1'22" and "01.03.2022". An apostrophe before
+// a two digit year means the year is in the 2000s.
+func parseQIFDate(s string, dateOrder QIFDateOrder) (time.Time, error) {
+	original := s
+	s = strings.ReplaceAll(s, " ", "")
+	apostrophe := strings.Contains(s, "'")
+
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\''
+	})
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", original)
+	}
+	numbers := [3]int{}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", original)
+		}
+		numbers[i] = n
+	}
+
+	var year, month, day int
+	if len(parts[0]) == 4 {
+		year, month, day = numbers[0], numbers[1], numbers[2]
+	} else if dateOrder == QIFDateOrderDayFirst {
+		day, month, year = numbers[0], numbers[1], numbers[2]
+	} else {
+		month, day, year = numbers[0], numbers[1], numbers[2]
+	}
+	if len(parts[2]) <= 2 && len(parts[0]) != 4 {
+		if apostrophe || year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", original)
+	}
+	return date, nil
+}
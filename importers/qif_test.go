@@ -0,0 +1,92 @@
+package importers_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/importers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQIF(t *testing.T) {
+	file, err := os.Open("testdata/checking.qif")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+
+	statement, err := importers.ParseQIF(file, importers.QIFDateOrderMonthFirst, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &importers.Statement{
+		Entries: []*importers.Entry{
+			{
+				Date:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Amount: -4599,
+				Payee:  "Grocery Store",
+				Memo:   pointerify("Weekly shop"),
+			},
+			{
+				Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+				Amount: 150000,
+				Payee:  "Acme Corp Payroll",
+			},
+			{
+				Date:   time.Date(2022, time.March, 4, 0, 0, 0, 0, time.UTC),
+				Amount: -12000,
+				Payee:  "Smith & Sons Plumbing",
+			},
+		},
+	}, statement)
+}
+
+func TestParseQIFDayFirst(t *testing.T) {
+	data := "!Type:CCard\nD01.03.2022\nT-9.99\nPStreaming Service\n^\nD31/12'21\nT-5\nPBakery\n^\n"
+
+	statement, err := importers.ParseQIF(strings.NewReader(data), importers.QIFDateOrderDayFirst, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &importers.Statement{
+		Entries: []*importers.Entry{
+			{
+				Date:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Amount: -999,
+				Payee:  "Streaming Service",
+			},
+			{
+				Date:   time.Date(2021, time.December, 31, 0, 0, 0, 0, time.UTC),
+				Amount: -500,
+				Payee:  "Bakery",
+			},
+		},
+	}, statement)
+}
+
+func TestParseQIFThreeMinorUnits(t *testing.T) {
+	file, err := os.Open("testdata/dinar.qif")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+
+	statement, err := importers.ParseQIF(file, importers.QIFDateOrderMonthFirst, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &importers.Statement{
+		Entries: []*importers.Entry{
+			{
+				Date:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Amount: -12345,
+				Payee:  "Grocery Store",
+			},
+			{
+				Date:   time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+				Amount: 1250500,
+				Payee:  "Employer",
+				Memo:   pointerify("salary"),
+			},
+		},
+	}, statement)
+}
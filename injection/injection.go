@@ -1,20 +1,44 @@
 package injection
 
 import (
+	"time"
+
 	"github.com/paulwrubel/moneybags-server/config"
 	"github.com/paulwrubel/moneybags-server/controllers"
+	"github.com/paulwrubel/moneybags-server/metrics"
+	"github.com/paulwrubel/moneybags-server/openapi"
 	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/paulwrubel/moneybags-server/scheduler"
 	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type IInjector interface {
 	InjectAuthService() *services.Auth
+	InjectRequestTimeout() time.Duration
+	InjectMetricsAddress() string
 
 	InjectHealthController() *controllers.Health
+	InjectOpenAPIController() *controllers.OpenAPI
+	InjectMetricsController() *controllers.Metrics
 	InjectAuthController(service services.IAuth) *controllers.Auth
 	InjectUserAccountsController() *controllers.UserAccounts
+	InjectTOTPController() *controllers.TOTP
+	InjectAPIKeysController() *controllers.APIKeys
 	InjectBudgetsController() *controllers.Budgets
+	InjectBudgetMembershipsController() *controllers.BudgetMemberships
+	InjectBudgetInvitationsController() *controllers.BudgetInvitations
 	InjectBankAccountsController() *controllers.BankAccounts
+	InjectTransactionsController() *controllers.Transactions
+	InjectImportsController() *controllers.Imports
+	InjectReconciliationsController() *controllers.Reconciliations
+	InjectTransfersController() *controllers.Transfers
+	InjectScheduledTransactionsController() *controllers.ScheduledTransactions
+	InjectScheduler() *scheduler.Worker
+	InjectCategoriesController() *controllers.Categories
+	InjectPayeesController() *controllers.Payees
+	InjectCategorizationRulesController() *controllers.CategorizationRules
+	InjectMonthsController() *controllers.Months
 }
 
 type Injector struct {
@@ -29,22 +53,103 @@ func (i *Injector) InjectAuthService() *services.Auth {
 		UserAccounts: &repositories.UserAccounts{
 			DB: i.AppInfo.DB,
 		},
+		Sessions: &repositories.Sessions{
+			DB: i.AppInfo.DB,
+		},
+		APIKeys: &repositories.APIKeys{
+			DB: i.AppInfo.DB,
+		},
 	}
 }
 
+func (i *Injector) InjectRequestTimeout() time.Duration {
+	return i.AppInfo.RequestTimeout
+}
+
+func (i *Injector) InjectMetricsAddress() string {
+	return i.AppInfo.MetricsAddress
+}
+
 func (i *Injector) InjectHealthController() *controllers.Health {
-	return &controllers.Health{}
+	return &controllers.Health{
+		SHealth: &services.Health{
+			RHealth: &repositories.Health{
+				DB: i.AppInfo.DB,
+			},
+			PrivateKey: i.AppInfo.AuthInfo.PrivateKey,
+			IsDraining: i.AppInfo.Lifecycle.IsDraining,
+		},
+	}
+}
+
+func (i *Injector) InjectOpenAPIController() *controllers.OpenAPI {
+	return &controllers.OpenAPI{
+		Document: openapi.Document,
+	}
+}
+
+func (i *Injector) InjectMetricsController() *controllers.Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(&metrics.DatabaseCollector{
+		RHealth: &repositories.Health{
+			DB: i.AppInfo.DB,
+		},
+		RUserAccounts: &repositories.UserAccounts{
+			DB: i.AppInfo.DB,
+		},
+		RBudgets: &repositories.Budgets{
+			DB: i.AppInfo.DB,
+		},
+	})
+	return &controllers.Metrics{
+		Gatherer: prometheus.Gatherers{metrics.Registry, registry},
+	}
 }
 
 func (i *Injector) InjectAuthController(service services.IAuth) *controllers.Auth {
 	return &controllers.Auth{
 		Service: service,
+		STOTP:   i.injectTOTPService(),
 	}
 }
 
 func (i *Injector) InjectUserAccountsController() *controllers.UserAccounts {
 	return &controllers.UserAccounts{
 		Service: &services.UserAccounts{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+			PasswordResetTokens: &repositories.PasswordResetTokens{
+				DB: i.AppInfo.DB,
+			},
+			Mailer: i.AppInfo.Mailer,
+		},
+	}
+}
+
+func (i *Injector) InjectTOTPController() *controllers.TOTP {
+	return &controllers.TOTP{
+		STOTP: i.injectTOTPService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectAPIKeysController() *controllers.APIKeys {
+	return &controllers.APIKeys{
+		SAPIKeys: &services.APIKeys{
+			RAPIKeys: &repositories.APIKeys{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
 			Repository: &repositories.UserAccounts{
 				DB: i.AppInfo.DB,
 			},
@@ -54,11 +159,45 @@ func (i *Injector) InjectUserAccountsController() *controllers.UserAccounts {
 
 func (i *Injector) InjectBudgetsController() *controllers.Budgets {
 	return &controllers.Budgets{
-		SBudgets: &services.Budgets{
-			RBudgets: &repositories.Budgets{
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectBudgetMembershipsController() *controllers.BudgetMemberships {
+	return &controllers.BudgetMemberships{
+		SBudgetMemberships: &services.BudgetMemberships{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RBudgetMemberships: &repositories.BudgetMemberships{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectBudgetInvitationsController() *controllers.BudgetInvitations {
+	return &controllers.BudgetInvitations{
+		SBudgetInvitations: &services.BudgetInvitations{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RBudgetInvitations: &repositories.BudgetInvitations{
 				DB: i.AppInfo.DB,
 			},
 		},
+		SBudgets: i.injectBudgetsService(),
 		SUserAccounts: &services.UserAccounts{
 			Repository: &repositories.UserAccounts{
 				DB: i.AppInfo.DB,
@@ -69,16 +208,296 @@ func (i *Injector) InjectBudgetsController() *controllers.Budgets {
 
 func (i *Injector) InjectBankAccountsController() *controllers.BankAccounts {
 	return &controllers.BankAccounts{
+		SBankAccounts: &services.BankAccounts{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			Repository: &repositories.BankAccounts{
+				DB: i.AppInfo.DB,
+			},
+			RBudgets: &repositories.Budgets{
+				DB: i.AppInfo.DB,
+			},
+			RTransactions: &repositories.Transactions{
+				DB: i.AppInfo.DB,
+			},
+			SExchangeRates: &services.ExchangeRates{
+				RExchangeRates: &repositories.ExchangeRates{
+					DB: i.AppInfo.DB,
+				},
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectTransactionsController() *controllers.Transactions {
+	return &controllers.Transactions{
+		STransactions: &services.Transactions{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			Repository: &repositories.Transactions{
+				DB: i.AppInfo.DB,
+			},
+			RTransactionSplits: &repositories.TransactionSplits{
+				DB: i.AppInfo.DB,
+			},
+		},
 		SBankAccounts: &services.BankAccounts{
 			Repository: &repositories.BankAccounts{
 				DB: i.AppInfo.DB,
 			},
 		},
-		SBudgets: &services.Budgets{
+		SCategories: &services.Categories{
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryGroups: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectImportsController() *controllers.Imports {
+	return &controllers.Imports{
+		SImports: &services.Imports{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RTransactions: &repositories.Transactions{
+				DB: i.AppInfo.DB,
+			},
+			RStatementBalances: &repositories.StatementBalances{
+				DB: i.AppInfo.DB,
+			},
+			RBankAccounts: &repositories.BankAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBankAccounts: &services.BankAccounts{
+			Repository: &repositories.BankAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectReconciliationsController() *controllers.Reconciliations {
+	return &controllers.Reconciliations{
+		SReconciliations: &services.Reconciliations{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RTransactions: &repositories.Transactions{
+				DB: i.AppInfo.DB,
+			},
+			RStatementBalances: &repositories.StatementBalances{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBankAccounts: &services.BankAccounts{
+			Repository: &repositories.BankAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectScheduledTransactionsController() *controllers.ScheduledTransactions {
+	return &controllers.ScheduledTransactions{
+		SScheduledTransactions: &services.ScheduledTransactions{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RScheduledTransactions: &repositories.ScheduledTransactions{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBankAccounts: &services.BankAccounts{
+			Repository: &repositories.BankAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SCategories: &services.Categories{
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryGroups: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectScheduler() *scheduler.Worker {
+	return &scheduler.Worker{
+		SScheduledTransactions: &services.ScheduledTransactions{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RScheduledTransactions: &repositories.ScheduledTransactions{
+				DB: i.AppInfo.DB,
+			},
+		},
+		Interval: i.AppInfo.SchedulerInterval,
+	}
+}
+
+func (i *Injector) InjectTransfersController() *controllers.Transfers {
+	budgetsService := i.injectBudgetsService()
+	return &controllers.Transfers{
+		STransfers: &services.Transfers{
+			UnitOfWork: &repositories.UnitOfWork{
+				DB: i.AppInfo.DB,
+			},
+			RTransactions: &repositories.Transactions{
+				DB: i.AppInfo.DB,
+			},
+			SBankAccounts: &services.BankAccounts{
+				Repository: &repositories.BankAccounts{
+					DB: i.AppInfo.DB,
+				},
+			},
+			SBudgets: budgetsService,
+		},
+		SBudgets: budgetsService,
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectCategoriesController() *controllers.Categories {
+	return &controllers.Categories{
+		SCategories: &services.Categories{
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryGroups: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SCategoryGroups: &services.CategoryGroups{
+			Repository: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectPayeesController() *controllers.Payees {
+	return &controllers.Payees{
+		SPayees: i.injectPayeesService(),
+		SCategories: &services.Categories{
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryGroups: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectCategorizationRulesController() *controllers.CategorizationRules {
+	return &controllers.CategorizationRules{
+		SCategorizationRules: &services.CategorizationRules{
+			RCategorizationRules: &repositories.CategorizationRules{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SCategories: &services.Categories{
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryGroups: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
+		},
+		SBudgets: i.injectBudgetsService(),
+		SUserAccounts: &services.UserAccounts{
+			Repository: &repositories.UserAccounts{
+				DB: i.AppInfo.DB,
+			},
+		},
+	}
+}
+
+func (i *Injector) InjectMonthsController() *controllers.Months {
+	return &controllers.Months{
+		SMonths: &services.Months{
 			RBudgets: &repositories.Budgets{
 				DB: i.AppInfo.DB,
 			},
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryAllocations: &repositories.CategoryAllocations{
+				DB: i.AppInfo.DB,
+			},
+			RTransactions: &repositories.Transactions{
+				DB: i.AppInfo.DB,
+			},
+			SExchangeRates: &services.ExchangeRates{
+				RExchangeRates: &repositories.ExchangeRates{
+					DB: i.AppInfo.DB,
+				},
+			},
+		},
+		SCategories: &services.Categories{
+			RCategories: &repositories.Categories{
+				DB: i.AppInfo.DB,
+			},
+			RCategoryGroups: &repositories.CategoryGroups{
+				DB: i.AppInfo.DB,
+			},
 		},
+		SBudgets: i.injectBudgetsService(),
 		SUserAccounts: &services.UserAccounts{
 			Repository: &repositories.UserAccounts{
 				DB: i.AppInfo.DB,
@@ -86,3 +505,42 @@ func (i *Injector) InjectBankAccountsController() *controllers.BankAccounts {
 		},
 	}
 }
+
+func (i *Injector) injectBudgetsService() *services.Budgets {
+	return &services.Budgets{
+		UnitOfWork: &repositories.UnitOfWork{
+			DB: i.AppInfo.DB,
+		},
+		RBudgets: &repositories.Budgets{
+			DB: i.AppInfo.DB,
+		},
+		RBudgetMemberships: &repositories.BudgetMemberships{
+			DB: i.AppInfo.DB,
+		},
+	}
+}
+
+func (i *Injector) injectPayeesService() *services.Payees {
+	return &services.Payees{
+		RPayees: &repositories.Payees{
+			DB: i.AppInfo.DB,
+		},
+	}
+}
+
+func (i *Injector) injectTOTPService() *services.TOTP {
+	return &services.TOTP{
+		UnitOfWork: &repositories.UnitOfWork{
+			DB: i.AppInfo.DB,
+		},
+		UserAccounts: &repositories.UserAccounts{
+			DB: i.AppInfo.DB,
+		},
+		TOTPCredentials: &repositories.TOTPCredentials{
+			DB: i.AppInfo.DB,
+		},
+		RecoveryCodes: &repositories.RecoveryCodes{
+			DB: i.AppInfo.DB,
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File is a mailer which writes each message as an .eml file in Directory,
+// so that messages can be inspected locally without an SMTP server
+type File struct {
+	Directory string
+	From      string
+}
+
+func (f *File) Send(to, subject, body string) error {
+	err := os.MkdirAll(f.Directory, 0o755)
+	if err != nil {
+		return fmt.Errorf("error creating mail directory: %w", err)
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		f.From,
+		to,
+		subject,
+		time.Now().Format(time.RFC1123Z),
+		body)
+
+	filename := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.NewString())
+	err = os.WriteFile(filepath.Join(f.Directory, filename), []byte(message), 0o644)
+	if err != nil {
+		return fmt.Errorf("error writing mail file: %w", err)
+	}
+	return nil
+}
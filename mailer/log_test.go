@@ -0,0 +1,32 @@
+package mailer_test
+
+import (
+	"testing"
+
+	"github.com/paulwrubel/moneybags-server/mailer"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogSendLeavesOutBody(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(level)
+
+	err := (&mailer.Log{}).Send("user@example.com", "Reset your password", "Your reset token is __secret_token__")
+	assert.NoError(t, err)
+
+	if assert.Len(t, hook.AllEntries(), 1) {
+		entry := hook.LastEntry()
+		assert.Equal(t, log.DebugLevel, entry.Level)
+		assert.Equal(t, "user@example.com", entry.Data["to"])
+		assert.Equal(t, "Reset your password", entry.Data["subject"])
+		assert.NotContains(t, entry.Message, "__secret_token__")
+		for _, value := range entry.Data {
+			assert.NotContains(t, value, "__secret_token__")
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package mailer
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/mailer/mock_$GOFILE -package=mockmailer
+
+type IMailer interface {
+	Send(to, subject, body string) error
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "conns"),
+		"Connections in the database pool, by state.",
+		[]string{"state"}, nil,
+	)
+	poolTotalConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "total_conns"),
+		"Connections in the database pool, including those being opened.",
+		nil, nil,
+	)
+	poolMaxConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "max_conns"),
+		"Most connections the database pool will open.",
+		nil, nil,
+	)
+	poolAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "acquires_total"),
+		"Connections acquired from the database pool.",
+		nil, nil,
+	)
+	poolEmptyAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "empty_acquires_total"),
+		"Connections acquired from the database pool which had to wait for one.",
+		nil, nil,
+	)
+	userAccountsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "user_accounts"),
+		"User accounts registered.",
+		nil, nil,
+	)
+	budgetsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "budgets"),
+		"Budgets created.",
+		nil, nil,
+	)
+)
+
+// DatabaseCollector reports the database pool and the number of user
+// accounts and budgets, reading them afresh on each scrape
+type DatabaseCollector struct {
+	RHealth       repositories.IHealth
+	RUserAccounts repositories.IUserAccounts
+	RBudgets      repositories.IBudgets
+}
+
+func (dc *DatabaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolConnsDesc
+	ch <- poolTotalConnsDesc
+	ch <- poolMaxConnsDesc
+	ch <- poolAcquiresDesc
+	ch <- poolEmptyAcquiresDesc
+	ch <- userAccountsDesc
+	ch <- budgetsDesc
+}
+
+// Collect reports the counts it fails to read as invalid, without holding
+// back the rest
+func (dc *DatabaseCollector) Collect(ch chan<- prometheus.Metric) {
+	pool := dc.RHealth.GetPool()
+	ch <- prometheus.MustNewConstMetric(poolConnsDesc, prometheus.GaugeValue, float64(pool.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(poolConnsDesc, prometheus.GaugeValue, float64(pool.AcquiredConns), "acquired")
+	ch <- prometheus.MustNewConstMetric(poolTotalConnsDesc, prometheus.GaugeValue, float64(pool.TotalConns))
+	ch <- prometheus.MustNewConstMetric(poolMaxConnsDesc, prometheus.GaugeValue, float64(pool.MaxConns))
+	ch <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(pool.AcquireCount))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquiresDesc, prometheus.CounterValue, float64(pool.EmptyAcquireCount))
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.MetricsQueryTimeout)
+	defer cancel()
+
+	userAccounts, err := dc.RUserAccounts.Count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(userAccountsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(userAccountsDesc, prometheus.GaugeValue, float64(userAccounts))
+	}
+	budgets, err := dc.RBudgets.Count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(budgetsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(budgetsDesc, prometheus.GaugeValue, float64(budgets))
+	}
+}
@@ -0,0 +1,102 @@
+package metrics_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/metrics"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseCollector(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockHealthRepository := mockrepositories.NewMockIHealth(mockCtrl)
+	mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+	mockBudgetsRepository := mockrepositories.NewMockIBudgets(mockCtrl)
+
+	mockHealthRepository.EXPECT().
+		GetPool().
+		Times(1).
+		Return(&models.DatabasePool{
+			TotalConns:        4,
+			IdleConns:         3,
+			AcquiredConns:     1,
+			MaxConns:          8,
+			AcquireCount:      120,
+			EmptyAcquireCount: 2,
+		})
+	mockUserAccountsRepository.EXPECT().
+		Count(gomock.Any()).
+		Times(1).
+		Return(5, nil)
+	mockBudgetsRepository.EXPECT().
+		Count(gomock.Any()).
+		Times(1).
+		Return(7, nil)
+
+	dc := &metrics.DatabaseCollector{
+		RHealth:       mockHealthRepository,
+		RUserAccounts: mockUserAccountsRepository,
+		RBudgets:      mockBudgetsRepository,
+	}
+
+	err := testutil.CollectAndCompare(dc, strings.NewReader(`
+# HELP moneybags_budgets Budgets created.
+# TYPE moneybags_budgets gauge
+moneybags_budgets 7
+# HELP moneybags_db_pool_acquires_total Connections acquired from the database pool.
+# TYPE moneybags_db_pool_acquires_total counter
+moneybags_db_pool_acquires_total 120
+# HELP moneybags_db_pool_conns Connections in the database pool, by state.
+# TYPE moneybags_db_pool_conns gauge
+moneybags_db_pool_conns{state="acquired"} 1
+moneybags_db_pool_conns{state="idle"} 3
+# HELP moneybags_db_pool_empty_acquires_total Connections acquired from the database pool which had to wait for one.
+# TYPE moneybags_db_pool_empty_acquires_total counter
+moneybags_db_pool_empty_acquires_total 2
+# HELP moneybags_db_pool_max_conns Most connections the database pool will open.
+# TYPE moneybags_db_pool_max_conns gauge
+moneybags_db_pool_max_conns 8
+# HELP moneybags_db_pool_total_conns Connections in the database pool, including those being opened.
+# TYPE moneybags_db_pool_total_conns gauge
+moneybags_db_pool_total_conns 4
+# HELP moneybags_user_accounts User accounts registered.
+# TYPE moneybags_user_accounts gauge
+moneybags_user_accounts 5
+`))
+	assert.NoError(t, err)
+}
+
+func TestDatabaseCollectorCountError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockHealthRepository := mockrepositories.NewMockIHealth(mockCtrl)
+	mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+	mockBudgetsRepository := mockrepositories.NewMockIBudgets(mockCtrl)
+
+	mockHealthRepository.EXPECT().
+		GetPool().
+		Times(1).
+		Return(&models.DatabasePool{})
+	mockUserAccountsRepository.EXPECT().
+		Count(gomock.Any()).
+		Times(1).
+		Return(0, errors.New("connection refused"))
+	mockBudgetsRepository.EXPECT().
+		Count(gomock.Any()).
+		Times(1).
+		Return(7, nil)
+
+	dc := &metrics.DatabaseCollector{
+		RHealth:       mockHealthRepository,
+		RUserAccounts: mockUserAccountsRepository,
+		RBudgets:      mockBudgetsRepository,
+	}
+
+	_, err := testutil.CollectAndLint(dc)
+	assert.Error(t, err)
+}
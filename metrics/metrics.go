@@ -0,0 +1,72 @@
+// Package metrics defines the Prometheus metrics served at /metrics on the
+// metrics listener
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "moneybags"
+
+// the ways of authenticating counted by AuthAttempts
+const (
+	AuthMethodPassword = "password"
+	AuthMethodTOTP     = "totp"
+	AuthMethodRefresh  = "refresh"
+	AuthMethodSession  = "session"
+	AuthMethodAPIKey   = "api_key"
+)
+
+// the results counted by AuthAttempts
+const (
+	AuthResultSuccess = "success"
+	AuthResultFailure = "failure"
+)
+
+// Registry holds the metrics which don't need the database, along with the
+// standard Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RequestsTotal counts requests by route template, rather than path, so
+	// that IDs in paths don't create a series each
+	RequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Requests handled, by route template, method and status.",
+	}, []string{"route", "method", "status"})
+
+	RequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to handle requests, by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	AuthAttempts = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "attempts_total",
+		Help:      "Attempts to authenticate, by method and result.",
+	}, []string{"method", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RecordAuthAttempt counts an attempt to authenticate with method
+func RecordAuthAttempt(method string, succeeded bool) {
+	result := AuthResultFailure
+	if succeeded {
+		result = AuthResultSuccess
+	}
+	AuthAttempts.WithLabelValues(method, result).Inc()
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/metrics"
+)
+
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (mrw *metricsResponseWriter) WriteHeader(statusCode int) {
+	mrw.ResponseWriter.WriteHeader(statusCode)
+	mrw.status = statusCode
+}
+
+// Metrics counts and times requests by the template of the route they
+// matched, such as /api/v1/budgets/{budgetID}
+func Metrics() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// handlers which never set a status respond 200
+			mrw := &metricsResponseWriter{ResponseWriter: rw, status: http.StatusOK}
+			next.ServeHTTP(mrw, r)
+
+			route := "unknown"
+			if currentRoute := mux.CurrentRoute(r); currentRoute != nil {
+				template, err := currentRoute.GetPathTemplate()
+				if err == nil {
+					route = template
+				}
+			}
+			status := strconv.Itoa(mrw.status)
+			metrics.RequestsTotal.WithLabelValues(route, r.Method, status).Inc()
+			metrics.RequestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -8,10 +8,15 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/gorilla/mux"
 	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/metrics"
+	"github.com/paulwrubel/moneybags-server/models"
 	"github.com/paulwrubel/moneybags-server/services"
 	log "github.com/sirupsen/logrus"
 )
 
+// SessionValidation authenticates requests by the bearer token in their
+// Authorization header, which is either an access token or an API key. The
+// username of the authenticated user is put into the request context.
 func SessionValidation(authService services.IAuth) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -31,12 +36,39 @@ func SessionValidation(authService services.IAuth) mux.MiddlewareFunc {
 			}
 
 			tokenString := authHeaderParts[1]
-			token, err := authService.ValidateSession(tokenString)
+			if strings.HasPrefix(tokenString, constants.APIKeyPrefix) {
+				username, apiKey, err := authService.ValidateAPIKey(r.Context(), tokenString)
+				if err != nil {
+					log.WithError(err).Error("Error validating api key")
+					metrics.RecordAuthAttempt(metrics.AuthMethodAPIKey, false)
+					rw.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				metrics.RecordAuthAttempt(metrics.AuthMethodAPIKey, true)
+				if !apiKeyAllows(apiKey, r) {
+					log.WithField("api_key_id", apiKey.ID).Debug("Request outside of api key scope")
+					rw.WriteHeader(http.StatusForbidden)
+					return
+				}
+				log.Debug("API key validated")
+
+				ctx := context.WithValue(r.Context(), constants.UsernameContextKey, username)
+				ctx = context.WithValue(ctx, constants.APIKeyIDContextKey, apiKey.ID)
+
+				log.WithField("username", username).Debug("Context set")
+
+				next.ServeHTTP(rw, r.WithContext(ctx))
+				return
+			}
+
+			token, err := authService.ValidateSession(r.Context(), tokenString)
 			if err != nil {
 				log.WithError(err).Error("Error validating session")
+				metrics.RecordAuthAttempt(metrics.AuthMethodSession, false)
 				rw.WriteHeader(http.StatusUnauthorized)
 				return
 			}
+			metrics.RecordAuthAttempt(metrics.AuthMethodSession, true)
 			log.Debug("Session validated")
 
 			// set context
@@ -44,6 +76,9 @@ func SessionValidation(authService services.IAuth) mux.MiddlewareFunc {
 			if usernameClaim, ok := token.Claims.(jwt.MapClaims)["sub"].(string); ok {
 				ctx = context.WithValue(ctx, constants.UsernameContextKey, usernameClaim)
 			}
+			if sessionIDClaim, ok := token.Claims.(jwt.MapClaims)["jti"].(string); ok {
+				ctx = context.WithValue(ctx, constants.SessionIDContextKey, sessionIDClaim)
+			}
 
 			log.WithField("username", ctx.Value(constants.UsernameContextKey)).Debug("Context set")
 
@@ -51,3 +86,16 @@ func SessionValidation(authService services.IAuth) mux.MiddlewareFunc {
 		})
 	}
 }
+
+// apiKeyAllows reports whether the request falls within the API key's
+// scopes. Read-only keys may only make safe requests, and keys scoped to a
+// budget may only reach that budget's routes.
+func apiKeyAllows(apiKey *models.APIKey, r *http.Request) bool {
+	if apiKey.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if apiKey.BudgetID != nil && mux.Vars(r)["budgetID"] != *apiKey.BudgetID {
+		return false
+	}
+	return true
+}
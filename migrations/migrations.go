@@ -0,0 +1,262 @@
+// Package migrations applies the versioned database schema migrations which
+// are embedded in the binary
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// advisoryLockKey identifies the lock held while migrating, so that several
+// replicas starting at once apply each migration exactly once
+const advisoryLockKey int64 = 0x6d6f6e6579626167
+
+var filenamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads the embedded migrations, sorted by version. Every migration
+// must have both an up and a down file.
+func Load() ([]*Migration, error) {
+	return load(files)
+}
+
+// load reads the migrations in the sql directory of fsys
+func load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		matches := filenamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration filename: %s", entry.Name())
+		}
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		contents, err := fs.ReadFile(fsys, "sql/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrationsByVersion[version]
+		if !ok {
+			migration = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+			migrationsByVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("conflicting names for migration %d: %s and %s", version, migration.Name, matches[2])
+		}
+		switch matches[3] {
+		case "up":
+			if migration.Up != "" {
+				return nil, fmt.Errorf("duplicate up files for migration %d", version)
+			}
+			migration.Up = string(contents)
+		case "down":
+			if migration.Down != "" {
+				return nil, fmt.Errorf("duplicate down files for migration %d", version)
+			}
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := []*Migration{}
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d is missing its up or down file", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest returns the version of the newest embedded migration
+func Latest() (int, error) {
+	return latest(files)
+}
+
+func latest(fsys fs.FS) (int, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].Version, nil
+}
+
+type Migrator struct {
+	DB *pgxpool.Pool
+}
+
+// Version returns the version of the newest migration applied to the
+// database, or 0 if none have been applied
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		var err error
+		version, err = currentVersion(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// Up applies every migration newer than the database's current version.
+// Each migration runs in its own transaction.
+func (m *Migrator) Up(ctx context.Context) error {
+	migrations, err := Load()
+	if err != nil {
+		return fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if migration.Version <= version {
+				continue
+			}
+
+			log.WithField("version", migration.Version).Infof("applying migration %s", migration.Name)
+			err := conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, migration.Up)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(ctx, `
+					INSERT INTO schema_migrations (version, name)
+					VALUES ($1, $2)`, migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error applying migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the given number of applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	migrations, err := Load()
+	if err != nil {
+		return fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := migrations[i]
+			if migration.Version > version {
+				continue
+			}
+
+			log.WithField("version", migration.Version).Infof("reverting migration %s", migration.Name)
+			err := conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, migration.Down)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(ctx, `
+					DELETE FROM schema_migrations
+					WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// withLock runs fn on a single connection while holding the migrations
+// advisory lock, creating the schema_migrations table first if needed
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.DB.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring connection: %w", err)
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey)
+	if err != nil {
+		return fmt.Errorf("error acquiring migrations lock: %w", err)
+	}
+	defer func() {
+		// use a fresh context so the lock is released even if ctx is done
+		_, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey)
+		if err != nil {
+			log.WithError(err).Error("error releasing migrations lock")
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func currentVersion(ctx context.Context, conn *pgxpool.Conn) (int, error) {
+	var version int
+	err := conn.QueryRow(ctx, `
+		SELECT coalesce(max(version), 0)
+		FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error getting current migration version: %w", err)
+	}
+
+	return version, nil
+}
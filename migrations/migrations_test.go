@@ -0,0 +1,202 @@
+package migrations
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mapFS(filenames ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, filename := range filenames {
+		fsys["sql/"+filename] = &fstest.MapFile{Data: []byte("-- " + filename)}
+	}
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name               string
+		fsys               fstest.MapFS
+		expectedMigrations []*Migration
+		expectedErr        string
+	}{
+		{
+			name: "parses versions and names",
+			fsys: mapFS(
+				"0001_initial.up.sql",
+				"0001_initial.down.sql",
+				"0002_add_widgets.up.sql",
+				"0002_add_widgets.down.sql",
+			),
+			expectedMigrations: []*Migration{
+				{
+					Version: 1,
+					Name:    "initial",
+					Up:      "-- 0001_initial.up.sql",
+					Down:    "-- 0001_initial.down.sql",
+				},
+				{
+					Version: 2,
+					Name:    "add_widgets",
+					Up:      "-- 0002_add_widgets.up.sql",
+					Down:    "-- 0002_add_widgets.down.sql",
+				},
+			},
+		},
+		{
+			name: "sorts by version, not by name",
+			fsys: mapFS(
+				"0010_tenth.up.sql",
+				"0010_tenth.down.sql",
+				"0002_second.up.sql",
+				"0002_second.down.sql",
+				"0009_ninth.up.sql",
+				"0009_ninth.down.sql",
+			),
+			expectedMigrations: []*Migration{
+				{
+					Version: 2,
+					Name:    "second",
+					Up:      "-- 0002_second.up.sql",
+					Down:    "-- 0002_second.down.sql",
+				},
+				{
+					Version: 9,
+					Name:    "ninth",
+					Up:      "-- 0009_ninth.up.sql",
+					Down:    "-- 0009_ninth.down.sql",
+				},
+				{
+					Version: 10,
+					Name:    "tenth",
+					Up:      "-- 0010_tenth.up.sql",
+					Down:    "-- 0010_tenth.down.sql",
+				},
+			},
+		},
+		{
+			name:               "no migrations",
+			fsys:               fstest.MapFS{"sql": &fstest.MapFile{Mode: fs.ModeDir}},
+			expectedMigrations: []*Migration{},
+		},
+		{
+			name: "missing down file",
+			fsys: mapFS(
+				"0001_initial.up.sql",
+				"0001_initial.down.sql",
+				"0002_add_widgets.up.sql",
+			),
+			expectedErr: "migration 2 is missing its up or down file",
+		},
+		{
+			name: "missing up file",
+			fsys: mapFS(
+				"0001_initial.down.sql",
+			),
+			expectedErr: "migration 1 is missing its up or down file",
+		},
+		{
+			name: "duplicate versions with different names",
+			fsys: mapFS(
+				"0001_initial.up.sql",
+				"0001_initial.down.sql",
+				"0001_other.up.sql",
+				"0001_other.down.sql",
+			),
+			expectedErr: "conflicting names for migration 1: initial and other",
+		},
+		{
+			name: "duplicate versions with the same name",
+			fsys: mapFS(
+				"0001_initial.up.sql",
+				"0001_initial.down.sql",
+				"1_initial.up.sql",
+				"1_initial.down.sql",
+			),
+			expectedErr: "duplicate down files for migration 1",
+		},
+		{
+			name: "invalid filename",
+			fsys: mapFS(
+				"0001_initial.up.sql",
+				"0001_initial.down.sql",
+				"initial.sql",
+			),
+			expectedErr: "invalid migration filename: initial.sql",
+		},
+		{
+			name: "invalid direction",
+			fsys: mapFS(
+				"0001_initial.sideways.sql",
+			),
+			expectedErr: "invalid migration filename: 0001_initial.sideways.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.fsys)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMigrations, migrations)
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	tests := []struct {
+		name            string
+		fsys            fstest.MapFS
+		expectedVersion int
+		expectedErr     string
+	}{
+		{
+			name: "newest version",
+			fsys: mapFS(
+				"0010_tenth.up.sql",
+				"0010_tenth.down.sql",
+				"0002_second.up.sql",
+				"0002_second.down.sql",
+			),
+			expectedVersion: 10,
+		},
+		{
+			name:            "no migrations",
+			fsys:            fstest.MapFS{"sql": &fstest.MapFile{Mode: fs.ModeDir}},
+			expectedVersion: 0,
+		},
+		{
+			name: "invalid migrations",
+			fsys: mapFS(
+				"0001_initial.up.sql",
+			),
+			expectedErr: "migration 1 is missing its up or down file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := latest(tt.fsys)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, version)
+		})
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	assert.NoError(t, err)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "migrations should be numbered without gaps")
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE bank_accounts;
+DROP TABLE budgets;
+DROP TABLE user_accounts;
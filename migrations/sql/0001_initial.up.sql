@@ -1,20 +1,23 @@
-CREATE TABLE user_accounts (
+-- IF NOT EXISTS lets databases created from the old schema.sql init script
+-- adopt the migrations without being recreated
+
+CREATE TABLE IF NOT EXISTS user_accounts (
   id UUID PRIMARY KEY,
   username TEXT UNIQUE NOT NULL,
   password_hash TEXT NOT NULL,
   email TEXT UNIQUE
 );
 
-CREATE TABLE budgets (
+CREATE TABLE IF NOT EXISTS budgets (
   id UUID PRIMARY KEY,
   user_account_id UUID NOT NULL REFERENCES user_accounts(id),
   name TEXT NOT NULL,
   UNIQUE (user_account_id, name)
 );
 
-CREATE TABLE bank_accounts (
+CREATE TABLE IF NOT EXISTS bank_accounts (
   id UUID PRIMARY KEY,
   budget_id UUID NOT NULL REFERENCES budgets(id),
   name TEXT NOT NULL,
   UNIQUE (budget_id, name)
-);
\ No newline at end of file
+);
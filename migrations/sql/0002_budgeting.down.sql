@@ -0,0 +1,13 @@
+DROP TABLE transactions;
+DROP TABLE category_allocations;
+DROP TABLE categories;
+DROP TABLE category_groups;
+
+ALTER TABLE bank_accounts
+  DROP COLUMN closed,
+  DROP COLUMN type,
+  DROP CONSTRAINT bank_accounts_budget_id_fkey,
+  ADD CONSTRAINT bank_accounts_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES budgets(id);
+
+ALTER TABLE budgets
+  DROP COLUMN archived;
@@ -0,0 +1,40 @@
+ALTER TABLE budgets
+  ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE bank_accounts
+  DROP CONSTRAINT bank_accounts_budget_id_fkey,
+  ADD CONSTRAINT bank_accounts_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE,
+  ADD COLUMN type TEXT NOT NULL DEFAULT 'checking' CHECK (type IN ('checking', 'savings', 'credit_card', 'cash', 'other')),
+  ADD COLUMN closed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE category_groups (
+  id UUID PRIMARY KEY,
+  budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  UNIQUE (budget_id, name)
+);
+
+CREATE TABLE categories (
+  id UUID PRIMARY KEY,
+  category_group_id UUID NOT NULL REFERENCES category_groups(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  UNIQUE (category_group_id, name)
+);
+
+CREATE TABLE category_allocations (
+  category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  month DATE NOT NULL CHECK (EXTRACT(DAY FROM month) = 1),
+  assigned BIGINT NOT NULL,
+  PRIMARY KEY (category_id, month)
+);
+
+CREATE TABLE transactions (
+  id UUID PRIMARY KEY,
+  bank_account_id UUID NOT NULL REFERENCES bank_accounts(id) ON DELETE CASCADE,
+  category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+  date DATE NOT NULL,
+  amount BIGINT NOT NULL,
+  payee TEXT NOT NULL,
+  memo TEXT,
+  cleared TEXT NOT NULL DEFAULT 'uncleared' CHECK (cleared IN ('uncleared', 'cleared'))
+);
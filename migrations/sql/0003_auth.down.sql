@@ -0,0 +1,4 @@
+DROP TABLE recovery_codes;
+DROP TABLE totp_credentials;
+DROP TABLE password_reset_tokens;
+DROP TABLE sessions;
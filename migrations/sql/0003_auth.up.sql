@@ -0,0 +1,31 @@
+CREATE TABLE sessions (
+  id UUID PRIMARY KEY,
+  user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  refresh_token_hash TEXT UNIQUE NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ
+);
+
+CREATE TABLE password_reset_tokens (
+  id UUID PRIMARY KEY,
+  user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  token_hash TEXT UNIQUE NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ
+);
+
+CREATE TABLE totp_credentials (
+  user_account_id UUID PRIMARY KEY REFERENCES user_accounts(id) ON DELETE CASCADE,
+  secret TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT FALSE,
+  last_used_step BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE recovery_codes (
+  id UUID PRIMARY KEY,
+  user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  code_hash TEXT NOT NULL,
+  used_at TIMESTAMPTZ,
+  UNIQUE (user_account_id, code_hash)
+);
@@ -0,0 +1,6 @@
+DROP TABLE statement_balances;
+
+DROP INDEX transactions_bank_account_id_fitid_key;
+
+ALTER TABLE transactions
+  DROP COLUMN fitid;
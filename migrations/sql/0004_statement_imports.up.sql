@@ -0,0 +1,14 @@
+ALTER TABLE transactions
+  ADD COLUMN fitid TEXT;
+
+CREATE UNIQUE INDEX transactions_bank_account_id_fitid_key
+  ON transactions (bank_account_id, fitid)
+  WHERE fitid IS NOT NULL;
+
+CREATE TABLE statement_balances (
+  id UUID PRIMARY KEY,
+  bank_account_id UUID NOT NULL REFERENCES bank_accounts(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  balance BIGINT NOT NULL,
+  UNIQUE (bank_account_id, date)
+);
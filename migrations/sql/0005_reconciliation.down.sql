@@ -0,0 +1,4 @@
+UPDATE transactions SET cleared = 'cleared' WHERE cleared = 'reconciled';
+ALTER TABLE transactions DROP CONSTRAINT transactions_cleared_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_cleared_check
+  CHECK (cleared IN ('uncleared', 'cleared'));
@@ -0,0 +1,5 @@
+-- reconciled transactions have been matched against a bank statement and
+-- are locked against accidental edits
+ALTER TABLE transactions DROP CONSTRAINT transactions_cleared_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_cleared_check
+  CHECK (cleared IN ('uncleared', 'cleared', 'reconciled'));
@@ -0,0 +1,4 @@
+DROP INDEX transactions_transfer_id_idx;
+
+ALTER TABLE transactions
+  DROP COLUMN transfer_id;
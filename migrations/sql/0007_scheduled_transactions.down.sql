@@ -0,0 +1,3 @@
+DROP TABLE scheduled_transaction_occurrences;
+
+DROP TABLE scheduled_transactions;
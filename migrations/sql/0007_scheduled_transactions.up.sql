@@ -0,0 +1,27 @@
+CREATE TABLE scheduled_transactions (
+  id UUID PRIMARY KEY,
+  bank_account_id UUID NOT NULL REFERENCES bank_accounts(id) ON DELETE CASCADE,
+  category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+  amount BIGINT NOT NULL,
+  payee TEXT NOT NULL,
+  memo TEXT,
+  frequency TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'last_business_day', 'yearly')),
+  repeat_interval INTEGER NOT NULL DEFAULT 1 CHECK (repeat_interval > 0),
+  day_of_month INTEGER CHECK (day_of_month BETWEEN 1 AND 31),
+  start_date DATE NOT NULL,
+  end_date DATE,
+  next_date DATE
+);
+
+CREATE INDEX scheduled_transactions_next_date_idx
+  ON scheduled_transactions (next_date)
+  WHERE next_date IS NOT NULL;
+
+-- one row per materialized occurrence, so that an occurrence is never
+-- created twice, nor recreated after its transaction has been deleted
+CREATE TABLE scheduled_transaction_occurrences (
+  scheduled_transaction_id UUID NOT NULL REFERENCES scheduled_transactions(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+  PRIMARY KEY (scheduled_transaction_id, date)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE transaction_splits (
+  id UUID PRIMARY KEY,
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+  amount BIGINT NOT NULL,
+  memo TEXT
+);
+
+CREATE INDEX transaction_splits_transaction_id_idx
+  ON transaction_splits (transaction_id);
@@ -0,0 +1,8 @@
+DROP TABLE categorization_rules;
+
+DROP INDEX transactions_payee_id_idx;
+
+ALTER TABLE transactions
+  DROP COLUMN payee_id;
+
+DROP TABLE payees;
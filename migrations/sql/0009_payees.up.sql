@@ -0,0 +1,31 @@
+CREATE TABLE payees (
+  id UUID PRIMARY KEY,
+  budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  default_category_id UUID REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX payees_budget_id_name_idx
+  ON payees (budget_id, lower(name));
+
+ALTER TABLE transactions
+  ADD COLUMN payee_id UUID REFERENCES payees(id) ON DELETE SET NULL;
+
+CREATE INDEX transactions_payee_id_idx
+  ON transactions (payee_id)
+  WHERE payee_id IS NOT NULL;
+
+CREATE TABLE categorization_rules (
+  id UUID PRIMARY KEY,
+  budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+  category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  payee_contains TEXT,
+  amount_min BIGINT,
+  amount_max BIGINT,
+  priority INTEGER NOT NULL DEFAULT 0,
+  CHECK (payee_contains IS NOT NULL OR amount_min IS NOT NULL OR amount_max IS NOT NULL),
+  CHECK (amount_min IS NULL OR amount_max IS NULL OR amount_min <= amount_max)
+);
+
+CREATE INDEX categorization_rules_budget_id_idx
+  ON categorization_rules (budget_id);
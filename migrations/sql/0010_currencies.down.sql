@@ -0,0 +1,7 @@
+DROP TABLE exchange_rates;
+
+ALTER TABLE bank_accounts
+  DROP COLUMN currency;
+
+ALTER TABLE budgets
+  DROP COLUMN currency;
@@ -0,0 +1,13 @@
+ALTER TABLE budgets
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE bank_accounts
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE TABLE exchange_rates (
+  base TEXT NOT NULL CHECK (base ~ '^[A-Z]{3}$'),
+  quote TEXT NOT NULL CHECK (quote ~ '^[A-Z]{3}$'),
+  date DATE NOT NULL,
+  rate NUMERIC NOT NULL CHECK (rate > 0),
+  PRIMARY KEY (base, quote, date)
+);
@@ -0,0 +1,3 @@
+DROP TABLE budget_invitations;
+
+DROP TABLE budget_memberships;
@@ -0,0 +1,27 @@
+CREATE TABLE budget_memberships (
+  budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+  user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+  PRIMARY KEY (budget_id, user_account_id)
+);
+
+CREATE INDEX budget_memberships_user_account_id_idx
+  ON budget_memberships (user_account_id);
+
+-- every existing budget is owned by the user who created it
+INSERT INTO budget_memberships (budget_id, user_account_id, role)
+SELECT id, user_account_id, 'owner'
+FROM budgets;
+
+CREATE TABLE budget_invitations (
+  id UUID PRIMARY KEY,
+  budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+  user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  invited_by_user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (budget_id, user_account_id)
+);
+
+CREATE INDEX budget_invitations_user_account_id_idx
+  ON budget_invitations (user_account_id);
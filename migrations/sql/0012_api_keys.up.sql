@@ -0,0 +1,15 @@
+CREATE TABLE api_keys (
+  id UUID PRIMARY KEY,
+  user_account_id UUID NOT NULL REFERENCES user_accounts(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  key_hash TEXT UNIQUE NOT NULL,
+  key_prefix TEXT NOT NULL,
+  read_only BOOLEAN NOT NULL DEFAULT FALSE,
+  budget_id UUID REFERENCES budgets(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX api_keys_user_account_id_idx
+  ON api_keys (user_account_id);
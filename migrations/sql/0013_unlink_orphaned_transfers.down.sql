@@ -0,0 +1,2 @@
+-- the transactions that were unlinked can't be told apart from any other, so
+-- there is nothing to undo
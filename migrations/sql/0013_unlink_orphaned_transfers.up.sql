@@ -0,0 +1,11 @@
+-- deleting one bank account of a transfer used to leave the other side
+-- pointing at a transfer that no longer had two sides
+UPDATE transactions
+SET transfer_id = NULL
+WHERE transfer_id IN (
+  SELECT transfer_id
+  FROM transactions
+  WHERE transfer_id IS NOT NULL
+  GROUP BY transfer_id
+  HAVING count(*) = 1
+);
@@ -0,0 +1,3 @@
+ALTER TABLE totp_credentials
+  ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN locked_until TIMESTAMPTZ;
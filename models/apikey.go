@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// APIKey lets scripts act as a user without holding their password. The key
+// itself is only shown when it is created; KeyPrefix is kept so that the
+// user can tell their keys apart.
+type APIKey struct {
+	ID            string
+	UserAccountID string
+	Name          string
+	KeyHash       string
+	KeyPrefix     string
+	// ReadOnly keys may only make requests which change nothing
+	ReadOnly bool
+	// BudgetID, when set, restricts the key to the routes of a single budget
+	BudgetID  *string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
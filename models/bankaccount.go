@@ -1,7 +1,32 @@
 package models
 
+type BankAccountType string
+
+const (
+	BankAccountTypeChecking   BankAccountType = "checking"
+	BankAccountTypeSavings    BankAccountType = "savings"
+	BankAccountTypeCreditCard BankAccountType = "credit_card"
+	BankAccountTypeCash       BankAccountType = "cash"
+	BankAccountTypeOther      BankAccountType = "other"
+)
+
 type BankAccount struct {
 	ID       string
 	BudgetID string
 	Name     string
+	Type     BankAccountType
+	Closed   bool
+	// Currency is the ISO 4217 code of the currency the account holds,
+	// which may differ from its budget's
+	Currency string
+}
+
+// BankAccountBalance is the balance of a bank account in its own currency,
+// along with the same balance converted into its budget's currency
+type BankAccountBalance struct {
+	BankAccountID  string
+	Currency       string
+	Balance        int64
+	BudgetCurrency string
+	BudgetBalance  int64
 }
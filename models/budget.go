@@ -1,7 +1,12 @@
 package models
 
 type Budget struct {
-	ID            string
+	ID string
+	// UserAccountID is the user who created the budget. Access to the budget
+	// is governed by its memberships instead.
 	UserAccountID string
 	Name          string
+	Archived      bool
+	// Currency is the ISO 4217 code of the currency the budget is kept in
+	Currency string
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type BudgetRole string
+
+const (
+	// BudgetRoleOwner can do everything an editor can, and also manage the
+	// budget's settings, members and invitations
+	BudgetRoleOwner BudgetRole = "owner"
+	// BudgetRoleEditor can read and change everything in the budget
+	BudgetRoleEditor BudgetRole = "editor"
+	// BudgetRoleViewer can only read the budget
+	BudgetRoleViewer BudgetRole = "viewer"
+)
+
+type BudgetMembership struct {
+	BudgetID      string
+	UserAccountID string
+	Username      string
+	Role          BudgetRole
+}
+
+// BudgetInvitation invites a user to become a member of a budget with a role.
+// The invited user becomes a member by accepting it.
+type BudgetInvitation struct {
+	ID                     string
+	BudgetID               string
+	BudgetName             string
+	UserAccountID          string
+	Username               string
+	InvitedByUserAccountID string
+	Role                   BudgetRole
+	CreatedAt              time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+// CategorizationRule files new transactions matching all of its conditions
+// under a category. PayeeContains matches case-insensitively, and the amount
+// bounds are inclusive. Rules are tried in order of ascending priority.
+type CategorizationRule struct {
+	ID            string
+	BudgetID      string
+	CategoryID    string
+	PayeeContains *string
+	AmountMin     *int64
+	AmountMax     *int64
+	Priority      int
+}
@@ -0,0 +1,13 @@
+package models
+
+type CategoryGroup struct {
+	ID       string
+	BudgetID string
+	Name     string
+}
+
+type Category struct {
+	ID              string
+	CategoryGroupID string
+	Name            string
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"math/big"
+	"time"
+)
+
+// ExchangeRate is the price of one unit of the base currency in units of the
+// quote currency on a date
+type ExchangeRate struct {
+	Base  string
+	Quote string
+	Date  time.Time
+	Rate  *big.Rat
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+// Health is the result of checking whether the server is ready for traffic.
+// Its status is only up when every component's is.
+type Health struct {
+	Status     HealthStatus
+	Draining   bool
+	Database   *DatabaseHealth
+	JWTKey     *JWTKeyHealth
+	Migrations *MigrationsHealth
+}
+
+type DatabaseHealth struct {
+	Status  HealthStatus
+	Latency time.Duration
+	Error   *string
+	Pool    *DatabasePool
+}
+
+// DatabasePool is a snapshot of the database connection pool
+type DatabasePool struct {
+	TotalConns    int32
+	IdleConns     int32
+	AcquiredConns int32
+	MaxConns      int32
+	AcquireCount  int64
+	// EmptyAcquireCount counts acquires which had to wait for a connection
+	EmptyAcquireCount int64
+}
+
+type JWTKeyHealth struct {
+	Status HealthStatus
+}
+
+// MigrationsHealth compares the version of the newest migration applied to
+// the database with that of the newest migration the server knows of
+type MigrationsHealth struct {
+	Status         HealthStatus
+	CurrentVersion int
+	LatestVersion  int
+	Error          *string
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type CategoryAllocation struct {
+	CategoryID string
+	Month      time.Time
+	Assigned   int64
+}
+
+type BudgetMonth struct {
+	BudgetID          string
+	Month             time.Time
+	AvailableToBudget int64
+	Categories        []*CategoryMonth
+}
+
+type CategoryMonth struct {
+	CategoryID string
+	Assigned   int64
+	Activity   int64
+	Available  int64
+}
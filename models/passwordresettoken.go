@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type PasswordResetToken struct {
+	ID            string
+	UserAccountID string
+	TokenHash     string
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+}
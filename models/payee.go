@@ -0,0 +1,11 @@
+package models
+
+// Payee is someone a budget's transactions are paid to or received from.
+// Transactions from a payee without a category are filed under its default
+// category.
+type Payee struct {
+	ID                string
+	BudgetID          string
+	Name              string
+	DefaultCategoryID *string
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Reconciliation compares a bank account's cleared balance to the balance
+// shown on a bank statement
+type Reconciliation struct {
+	BankAccountID    string
+	StatementDate    time.Time
+	StatementBalance int64
+	ClearedBalance   int64
+	// Difference is the statement balance less the cleared balance
+	Difference int64
+	// Transactions are the cleared transactions which are, or would be,
+	// locked by the reconciliation
+	Transactions []*Transaction
+	// Adjustment is the transaction created to make up the difference, if any
+	Adjustment *Transaction
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceFrequencyDaily   RecurrenceFrequency = "daily"
+	RecurrenceFrequencyWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceFrequencyMonthly RecurrenceFrequency = "monthly"
+	// RecurrenceFrequencyLastBusinessDay falls on the last weekday of the month
+	RecurrenceFrequencyLastBusinessDay RecurrenceFrequency = "last_business_day"
+	RecurrenceFrequencyYearly          RecurrenceFrequency = "yearly"
+)
+
+// ScheduledTransaction is a template for a transaction which recurs. Each
+// occurrence is materialized as a real transaction once it falls due.
+type ScheduledTransaction struct {
+	ID            string
+	BankAccountID string
+	CategoryID    *string
+	Amount        int64
+	Payee         string
+	Memo          *string
+	Frequency     RecurrenceFrequency
+	// Interval is the number of days, weeks, months or years between
+	// occurrences, so every other week is a weekly schedule with interval 2
+	Interval int
+	// DayOfMonth is the day on which a monthly schedule falls
+	DayOfMonth *int
+	StartDate  time.Time
+	EndDate    *time.Time
+	// NextDate is the earliest occurrence not yet materialized, or nil once
+	// the schedule has ended
+	NextDate *time.Time
+}
+
+// ScheduledOccurrence is a single date on which a scheduled transaction falls
+type ScheduledOccurrence struct {
+	ScheduledTransaction *ScheduledTransaction
+	Date                 time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Session struct {
+	ID               string
+	UserAccountID    string
+	RefreshTokenHash string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// StatementBalance is the ledger balance a bank reported for an account as
+// of a date, as picked up from an imported statement
+type StatementBalance struct {
+	ID            string
+	BankAccountID string
+	Date          time.Time
+	Balance       int64
+}
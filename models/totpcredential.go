@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type TOTPCredential struct {
+	UserAccountID string
+	Secret        string
+	Enabled       bool
+	LastUsedStep  int64
+	// FailedAttempts counts wrong codes since the last correct one, and
+	// LockedUntil is set once there have been too many
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+type RecoveryCode struct {
+	ID            string
+	UserAccountID string
+	CodeHash      string
+	UsedAt        *time.Time
+}
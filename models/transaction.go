@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+type ClearedState string
+
+const (
+	ClearedStateUncleared ClearedState = "uncleared"
+	ClearedStateCleared   ClearedState = "cleared"
+	// ClearedStateReconciled is only set by reconciling the bank account
+	ClearedStateReconciled ClearedState = "reconciled"
+)
+
+type Transaction struct {
+	ID            string
+	BankAccountID string
+	CategoryID    *string
+	Date          time.Time
+	Amount        int64
+	Payee         string
+	Memo          *string
+	Cleared       ClearedState
+	// FITID is the bank's identifier for an imported transaction
+	FITID *string
+	// TransferID links the two sides of a transfer between bank accounts
+	TransferID *string
+	// PayeeID links the transaction to the budget's payee of the same name
+	PayeeID *string
+	// Splits divide the transaction between categories, in which case the
+	// transaction itself has no category. They are loaded by the service
+	// layer rather than the transactions repository.
+	Splits []*TransactionSplit
+}
+
+// TransactionFilter narrows a list of transactions to those matching every
+// field that is set. Dates and amounts are inclusive bounds, and a category
+// matches a transaction either categorized as it or with a split that is.
+type TransactionFilter struct {
+	From       *time.Time
+	To         *time.Time
+	MinAmount  *int64
+	MaxAmount  *int64
+	PayeeID    *string
+	CategoryID *string
+	Cleared    *ClearedState
+}
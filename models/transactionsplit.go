@@ -0,0 +1,11 @@
+package models
+
+// TransactionSplit is one line of a transaction which is divided between
+// several categories
+type TransactionSplit struct {
+	ID            string
+	TransactionID string
+	CategoryID    *string
+	Amount        int64
+	Memo          *string
+}
@@ -0,0 +1,9 @@
+package models
+
+// Transfer moves money between two bank accounts in the same budget. It is
+// stored as a linked pair of transactions sharing the transfer's ID.
+type Transfer struct {
+	ID      string
+	Outflow *Transaction
+	Inflow  *Transaction
+}
@@ -0,0 +1,14 @@
+// Package openapi holds the OpenAPI 3 document describing the API, which is
+// embedded in the binary and served at /api/v1/openapi.json
+package openapi
+
+import (
+	_ "embed"
+)
+
+// Document is the OpenAPI document as JSON. It is written by hand, so any
+// change to a route, or to a request or response body, must be made to it
+// too. The routing and controllers tests fail when they disagree.
+//
+//go:embed openapi.json
+var Document []byte
@@ -0,0 +1,157 @@
+// Package pagination pages through lists with opaque cursors. Each page
+// resumes after the last item of the page before it, by the value that the
+// list is sorted on and then by ID, so that items added or removed between
+// requests neither repeat nor go missing.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+)
+
+// SortType is the type of the values a list is sorted by
+type SortType int
+
+const (
+	SortText SortType = iota
+	// SortDate values are dates in constants.DateLayout
+	SortDate
+	SortInteger
+)
+
+// SortKey is a key a list can be sorted on. Cursors are checked against
+// its type, since the values in them come back from the client.
+type SortKey struct {
+	Name string
+	Type SortType
+}
+
+// Params describes the page of a list to get
+type Params struct {
+	Limit int
+	// Sort is the key the list is sorted on
+	Sort       string
+	Descending bool
+	// After is the cursor of the last item of the previous page, or nil
+	// for the first page
+	After *Cursor
+}
+
+// Cursor marks an item of a sorted list
+type Cursor struct {
+	// Sort is the sort parameter of the list, including its direction, so
+	// that a cursor cannot be used to resume a list sorted differently
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"i"`
+}
+
+// Encode returns the cursor as an opaque, URL safe string
+func (c *Cursor) Encode() string {
+	cursorJSON, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, constants.ErrInvalidCursor
+	}
+	cursor := &Cursor{}
+	err = json.Unmarshal(cursorJSON, cursor)
+	if err != nil || cursor.Sort == "" || cursor.ID == "" {
+		return nil, constants.ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// ParseParams reads the limit, sort and cursor query parameters. sort names
+// one of sortKeys, prefixed by "-" to sort descending, and defaults to
+// defaultSort. A cursor is only valid for the sort it was issued for, and
+// only if its ID and value are of the types the list is sorted by.
+func ParseParams(query url.Values, sortKeys []SortKey, defaultSort string) (*Params, error) {
+	params := &Params{
+		Limit: constants.DefaultPageLimit,
+		Sort:  defaultSort,
+	}
+
+	if limitString := query.Get("limit"); limitString != "" {
+		limit, err := strconv.Atoi(limitString)
+		if err != nil || limit < 1 || limit > constants.MaxPageLimit {
+			return nil, constants.ErrInvalidPageLimit
+		}
+		params.Limit = limit
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		params.Descending = strings.HasPrefix(sort, "-")
+		params.Sort = strings.TrimPrefix(sort, "-")
+	}
+	var sortType SortType
+	valid := false
+	for _, key := range sortKeys {
+		if params.Sort == key.Name {
+			sortType = key.Type
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, constants.ErrInvalidSortKey
+	}
+
+	if cursorString := query.Get("cursor"); cursorString != "" {
+		cursor, err := DecodeCursor(cursorString)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != params.sortParam() || !validCursorValues(cursor, sortType) {
+			return nil, constants.ErrInvalidCursor
+		}
+		params.After = cursor
+	}
+
+	return params, nil
+}
+
+// validCursorValues checks that a cursor's value is of the sort's type and
+// that its ID is a UUID, as the database would otherwise fail to cast them
+func validCursorValues(cursor *Cursor, sortType SortType) bool {
+	_, err := uuid.Parse(cursor.ID)
+	if err != nil {
+		return false
+	}
+
+	switch sortType {
+	case SortDate:
+		_, err = time.Parse(constants.DateLayout, cursor.Value)
+	case SortInteger:
+		_, err = strconv.ParseInt(cursor.Value, 10, 64)
+	}
+	return err == nil
+}
+
+// Next returns the cursor of an item on the page, given the value it is
+// sorted by and its ID
+func (p *Params) Next(value, id string) *Cursor {
+	return &Cursor{
+		Sort:  p.sortParam(),
+		Value: value,
+		ID:    id,
+	}
+}
+
+func (p *Params) sortParam() string {
+	if p.Descending {
+		return "-" + p.Sort
+	}
+	return p.Sort
+}
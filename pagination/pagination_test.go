@@ -0,0 +1,100 @@
+package pagination_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParams(t *testing.T) {
+	sortKeys := []pagination.SortKey{
+		{Name: "date", Type: pagination.SortDate},
+		{Name: "amount", Type: pagination.SortInteger},
+		{Name: "payee", Type: pagination.SortText},
+	}
+	cursor := &pagination.Cursor{Sort: "-amount", Value: "-4599", ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedParams *pagination.Params
+		expectedErr    error
+	}{
+		{
+			name:           "defaults",
+			query:          "",
+			expectedParams: &pagination.Params{Limit: constants.DefaultPageLimit, Sort: "date"},
+		},
+		{
+			name:           "limit and descending sort",
+			query:          "limit=10&sort=-amount",
+			expectedParams: &pagination.Params{Limit: 10, Sort: "amount", Descending: true},
+		},
+		{
+			name:           "cursor",
+			query:          "sort=-amount&cursor=" + cursor.Encode(),
+			expectedParams: &pagination.Params{Limit: constants.DefaultPageLimit, Sort: "amount", Descending: true, After: cursor},
+		},
+		{
+			name:        "limit too large",
+			query:       "limit=201",
+			expectedErr: constants.ErrInvalidPageLimit,
+		},
+		{
+			name:        "limit not a number",
+			query:       "limit=ten",
+			expectedErr: constants.ErrInvalidPageLimit,
+		},
+		{
+			name:        "unknown sort key",
+			query:       "sort=category",
+			expectedErr: constants.ErrInvalidSortKey,
+		},
+		{
+			name:        "cursor for another sort direction",
+			query:       "sort=amount&cursor=" + cursor.Encode(),
+			expectedErr: constants.ErrInvalidCursor,
+		},
+		{
+			name:        "malformed cursor",
+			query:       "cursor=not-a-cursor",
+			expectedErr: constants.ErrInvalidCursor,
+		},
+		{
+			name:        "cursor with an ID which is not a UUID",
+			query:       "sort=-amount&cursor=" + (&pagination.Cursor{Sort: "-amount", Value: "-4599", ID: "1; DROP TABLE transactions"}).Encode(),
+			expectedErr: constants.ErrInvalidCursor,
+		},
+		{
+			name:        "cursor with a value which is not an integer",
+			query:       "sort=-amount&cursor=" + (&pagination.Cursor{Sort: "-amount", Value: "lots", ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"}).Encode(),
+			expectedErr: constants.ErrInvalidCursor,
+		},
+		{
+			name:        "cursor with a value which is not a date",
+			query:       "cursor=" + (&pagination.Cursor{Sort: "date", Value: "2022-13-45", ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"}).Encode(),
+			expectedErr: constants.ErrInvalidCursor,
+		},
+		{
+			name:  "cursor with any text value",
+			query: "sort=payee&cursor=" + (&pagination.Cursor{Sort: "payee", Value: "'); --", ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"}).Encode(),
+			expectedParams: &pagination.Params{
+				Limit: constants.DefaultPageLimit,
+				Sort:  "payee",
+				After: &pagination.Cursor{Sort: "payee", Value: "'); --", ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			params, err := pagination.ParseParams(query, sortKeys, "date")
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedParams, params)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package recurrence calculates the dates on which scheduled transactions
+// fall due. All dates are calendar dates at midnight UTC.
+package recurrence
+
+import (
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+// Rule describes when a scheduled transaction recurs. Occurrences are counted
+// from Start, every Interval days, weeks, months or years depending on
+// Frequency, and stop after End if it is set.
+type Rule struct {
+	Frequency models.RecurrenceFrequency
+	Interval  int
+	// DayOfMonth is only used by monthly rules. Months which are too short
+	// fall on their last day instead.
+	DayOfMonth int
+	Start      time.Time
+	End        *time.Time
+}
+
+// Valid reports whether the rule can produce occurrences
+func (r *Rule) Valid() bool {
+	if r.Interval < 1 {
+		return false
+	}
+	if r.End != nil && r.End.Before(r.Start) {
+		return false
+	}
+
+	switch r.Frequency {
+	case models.RecurrenceFrequencyMonthly:
+		return r.DayOfMonth >= 1 && r.DayOfMonth <= 31
+	case models.RecurrenceFrequencyDaily,
+		models.RecurrenceFrequencyWeekly,
+		models.RecurrenceFrequencyLastBusinessDay,
+		models.RecurrenceFrequencyYearly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next returns the earliest occurrence strictly after the given date, and
+// false if the rule has ended by then
+func (r *Rule) Next(after time.Time) (time.Time, bool) {
+	for n := r.estimate(after); ; n++ {
+		date := r.occurrence(n)
+		if date.Before(r.Start) || !date.After(after) {
+			continue
+		}
+		if r.End != nil && date.After(*r.End) {
+			return time.Time{}, false
+		}
+		return date, true
+	}
+}
+
+// Between returns every occurrence within the closed range [from, to]
+func (r *Rule) Between(from, to time.Time) []time.Time {
+	dates := []time.Time{}
+	date, ok := r.Next(from.AddDate(0, 0, -1))
+	for ok && !date.After(to) {
+		dates = append(dates, date)
+		date, ok = r.Next(date)
+	}
+	return dates
+}
+
+// occurrence returns the nth date of the rule, counting from 0. Occurrences
+// are always increasing in n, but for monthly rules the first may fall
+// before Start.
+func (r *Rule) occurrence(n int) time.Time {
+	switch r.Frequency {
+	case models.RecurrenceFrequencyDaily:
+		return r.Start.AddDate(0, 0, n*r.Interval)
+	case models.RecurrenceFrequencyWeekly:
+		return r.Start.AddDate(0, 0, 7*n*r.Interval)
+	case models.RecurrenceFrequencyMonthly:
+		year, month := addMonths(r.Start, n*r.Interval)
+		return date(year, month, minInt(r.DayOfMonth, daysIn(year, month)))
+	case models.RecurrenceFrequencyLastBusinessDay:
+		year, month := addMonths(r.Start, n*r.Interval)
+		day := date(year, month, daysIn(year, month))
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day
+	case models.RecurrenceFrequencyYearly:
+		year := r.Start.Year() + n*r.Interval
+		month := r.Start.Month()
+		return date(year, month, minInt(r.Start.Day(), daysIn(year, month)))
+	default:
+		panic("recurrence: unknown frequency " + string(r.Frequency))
+	}
+}
+
+// estimate returns an n whose occurrence is no later than after, so that
+// Next doesn't have to count up from the start of long-running rules
+func (r *Rule) estimate(after time.Time) int {
+	var units int
+	switch r.Frequency {
+	case models.RecurrenceFrequencyDaily:
+		units = int(after.Sub(r.Start).Hours() / 24)
+	case models.RecurrenceFrequencyWeekly:
+		units = int(after.Sub(r.Start).Hours() / (24 * 7))
+	case models.RecurrenceFrequencyMonthly, models.RecurrenceFrequencyLastBusinessDay:
+		units = (after.Year()-r.Start.Year())*12 + int(after.Month()-r.Start.Month())
+	case models.RecurrenceFrequencyYearly:
+		units = after.Year() - r.Start.Year()
+	}
+
+	n := units/r.Interval - 1
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func addMonths(start time.Time, months int) (int, time.Month) {
+	first := date(start.Year(), start.Month()+time.Month(months), 1)
+	return first.Year(), first.Month()
+}
+
+func daysIn(year int, month time.Month) int {
+	return date(year, month+1, 0).Day()
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,164 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/recurrence"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRuleBetween(t *testing.T) {
+	end := date(2022, time.March, 20)
+	tests := []struct {
+		name          string
+		rule          recurrence.Rule
+		from          time.Time
+		to            time.Time
+		expectedDates []time.Time
+	}{
+		{
+			name: "daily",
+			rule: recurrence.Rule{
+				Frequency: models.RecurrenceFrequencyDaily,
+				Interval:  3,
+				Start:     date(2022, time.January, 1),
+			},
+			from: date(2022, time.January, 5),
+			to:   date(2022, time.January, 13),
+			expectedDates: []time.Time{
+				date(2022, time.January, 7),
+				date(2022, time.January, 10),
+				date(2022, time.January, 13),
+			},
+		},
+		{
+			name: "every other week",
+			rule: recurrence.Rule{
+				Frequency: models.RecurrenceFrequencyWeekly,
+				Interval:  2,
+				Start:     date(2022, time.January, 7),
+			},
+			from: date(2022, time.January, 1),
+			to:   date(2022, time.February, 28),
+			expectedDates: []time.Time{
+				date(2022, time.January, 7),
+				date(2022, time.January, 21),
+				date(2022, time.February, 4),
+				date(2022, time.February, 18),
+			},
+		},
+		{
+			name: "monthly - short months use their last day",
+			rule: recurrence.Rule{
+				Frequency:  models.RecurrenceFrequencyMonthly,
+				Interval:   1,
+				DayOfMonth: 31,
+				Start:      date(2022, time.January, 15),
+			},
+			from: date(2022, time.January, 1),
+			to:   date(2022, time.April, 30),
+			expectedDates: []time.Time{
+				date(2022, time.January, 31),
+				date(2022, time.February, 28),
+				date(2022, time.March, 31),
+				date(2022, time.April, 30),
+			},
+		},
+		{
+			name: "monthly - day before start falls the next month",
+			rule: recurrence.Rule{
+				Frequency:  models.RecurrenceFrequencyMonthly,
+				Interval:   1,
+				DayOfMonth: 1,
+				Start:      date(2022, time.January, 15),
+			},
+			from: date(2022, time.January, 1),
+			to:   date(2022, time.March, 1),
+			expectedDates: []time.Time{
+				date(2022, time.February, 1),
+				date(2022, time.March, 1),
+			},
+		},
+		{
+			name: "last business day",
+			rule: recurrence.Rule{
+				Frequency: models.RecurrenceFrequencyLastBusinessDay,
+				Interval:  1,
+				Start:     date(2022, time.April, 1),
+			},
+			from: date(2022, time.April, 1),
+			to:   date(2022, time.July, 31),
+			expectedDates: []time.Time{
+				date(2022, time.April, 29),
+				date(2022, time.May, 31),
+				date(2022, time.June, 30),
+				date(2022, time.July, 29),
+			},
+		},
+		{
+			name: "yearly - leap day",
+			rule: recurrence.Rule{
+				Frequency: models.RecurrenceFrequencyYearly,
+				Interval:  1,
+				Start:     date(2020, time.February, 29),
+			},
+			from: date(2020, time.January, 1),
+			to:   date(2024, time.December, 31),
+			expectedDates: []time.Time{
+				date(2020, time.February, 29),
+				date(2021, time.February, 28),
+				date(2022, time.February, 28),
+				date(2023, time.February, 28),
+				date(2024, time.February, 29),
+			},
+		},
+		{
+			name: "stops at end date",
+			rule: recurrence.Rule{
+				Frequency: models.RecurrenceFrequencyWeekly,
+				Interval:  1,
+				Start:     date(2022, time.March, 1),
+				End:       &end,
+			},
+			from: date(2022, time.March, 1),
+			to:   date(2022, time.April, 30),
+			expectedDates: []time.Time{
+				date(2022, time.March, 1),
+				date(2022, time.March, 8),
+				date(2022, time.March, 15),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.rule.Valid())
+			assert.Equal(t, tt.expectedDates, tt.rule.Between(tt.from, tt.to))
+		})
+	}
+}
+
+func TestRuleNextAfterLongGap(t *testing.T) {
+	rule := recurrence.Rule{
+		Frequency: models.RecurrenceFrequencyDaily,
+		Interval:  1,
+		Start:     date(2000, time.January, 1),
+	}
+
+	next, ok := rule.Next(date(2022, time.March, 31))
+
+	assert.True(t, ok)
+	assert.Equal(t, date(2022, time.April, 1), next)
+}
+
+func TestRuleValid(t *testing.T) {
+	assert.False(t, (&recurrence.Rule{Frequency: models.RecurrenceFrequencyDaily, Interval: 0}).Valid())
+	assert.False(t, (&recurrence.Rule{Frequency: models.RecurrenceFrequencyMonthly, Interval: 1, DayOfMonth: 32}).Valid())
+	assert.False(t, (&recurrence.Rule{Frequency: "hourly", Interval: 1}).Valid())
+}
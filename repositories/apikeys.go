@@ -0,0 +1,222 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IAPIKeys interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByKeyHash(ctx context.Context, keyHash string) (bool, error)
+	GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.APIKey, error)
+	GetByID(ctx context.Context, id string) (*models.APIKey, error)
+	GetByKeyHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Create(ctx context.Context, apiKey *models.APIKey) error
+	Update(ctx context.Context, apiKey *models.APIKey) error
+}
+
+type APIKeys struct {
+	DB database.IHandler
+}
+
+func (ak *APIKeys) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := ak.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM api_keys
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (ak *APIKeys) ExistsByKeyHash(ctx context.Context, keyHash string) (bool, error) {
+	var count int
+	err := ak.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM api_keys
+		WHERE key_hash = $1`, keyHash).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (ak *APIKeys) GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.APIKey, error) {
+	rows, err := ak.DB.Query(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			name,
+			key_hash,
+			key_prefix,
+			read_only,
+			budget_id,
+			created_at,
+			expires_at,
+			revoked_at
+		FROM api_keys
+		WHERE user_account_id = $1
+		ORDER BY created_at, id`, userAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []*models.APIKey{}
+	for rows.Next() {
+		apiKey := &models.APIKey{}
+		err := rows.Scan(
+			&apiKey.ID,
+			&apiKey.UserAccountID,
+			&apiKey.Name,
+			&apiKey.KeyHash,
+			&apiKey.KeyPrefix,
+			&apiKey.ReadOnly,
+			&apiKey.BudgetID,
+			&apiKey.CreatedAt,
+			&apiKey.ExpiresAt,
+			&apiKey.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, nil
+}
+
+func (ak *APIKeys) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	apiKey := &models.APIKey{}
+	err := ak.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			name,
+			key_hash,
+			key_prefix,
+			read_only,
+			budget_id,
+			created_at,
+			expires_at,
+			revoked_at
+		FROM api_keys
+		WHERE id = $1`, id).Scan(
+		&apiKey.ID,
+		&apiKey.UserAccountID,
+		&apiKey.Name,
+		&apiKey.KeyHash,
+		&apiKey.KeyPrefix,
+		&apiKey.ReadOnly,
+		&apiKey.BudgetID,
+		&apiKey.CreatedAt,
+		&apiKey.ExpiresAt,
+		&apiKey.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (ak *APIKeys) GetByKeyHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	apiKey := &models.APIKey{}
+	err := ak.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			name,
+			key_hash,
+			key_prefix,
+			read_only,
+			budget_id,
+			created_at,
+			expires_at,
+			revoked_at
+		FROM api_keys
+		WHERE key_hash = $1`, keyHash).Scan(
+		&apiKey.ID,
+		&apiKey.UserAccountID,
+		&apiKey.Name,
+		&apiKey.KeyHash,
+		&apiKey.KeyPrefix,
+		&apiKey.ReadOnly,
+		&apiKey.BudgetID,
+		&apiKey.CreatedAt,
+		&apiKey.ExpiresAt,
+		&apiKey.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (ak *APIKeys) Create(ctx context.Context, apiKey *models.APIKey) error {
+	tag, err := ak.DB.Exec(ctx, `
+		INSERT INTO api_keys (
+			id,
+			user_account_id,
+			name,
+			key_hash,
+			key_prefix,
+			read_only,
+			budget_id,
+			created_at,
+			expires_at,
+			revoked_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		apiKey.ID,
+		apiKey.UserAccountID,
+		apiKey.Name,
+		apiKey.KeyHash,
+		apiKey.KeyPrefix,
+		apiKey.ReadOnly,
+		apiKey.BudgetID,
+		apiKey.CreatedAt,
+		apiKey.ExpiresAt,
+		apiKey.RevokedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create api key: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (ak *APIKeys) Update(ctx context.Context, apiKey *models.APIKey) error {
+	tag, err := ak.DB.Exec(ctx, `
+		UPDATE api_keys
+		SET
+			name = $2,
+			read_only = $3,
+			budget_id = $4,
+			expires_at = $5,
+			revoked_at = $6
+		WHERE id = $1`,
+		apiKey.ID,
+		apiKey.Name,
+		apiKey.ReadOnly,
+		apiKey.BudgetID,
+		apiKey.ExpiresAt,
+		apiKey.RevokedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update api key: unexpected number of rows affected")
+	}
+
+	return nil
+}
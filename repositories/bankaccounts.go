@@ -8,24 +8,27 @@ import (
 
 	"github.com/paulwrubel/moneybags-server/database"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 )
 
 type IBankAccounts interface {
-	ExistsByID(id string) (bool, error)
-	GetAllByBudgetID(budgetID string) ([]*models.BankAccount, error)
-	GetByID(id string) (*models.BankAccount, error)
-	Create(bankAccount *models.BankAccount) error
-	DeleteByID(id string) error
-	Update(bankAccount *models.BankAccount) error
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.BankAccount, error)
+	GetPageByBudgetID(ctx context.Context, budgetID string, page *pagination.Params) ([]*models.BankAccount, *pagination.Cursor, error)
+	GetByID(ctx context.Context, id string) (*models.BankAccount, error)
+	Create(ctx context.Context, bankAccount *models.BankAccount) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, bankAccount *models.BankAccount) error
 }
 
 type BankAccounts struct {
 	DB database.IHandler
 }
 
-func (ba *BankAccounts) ExistsByID(id string) (bool, error) {
+func (ba *BankAccounts) ExistsByID(ctx context.Context, id string) (bool, error) {
 	var count int
-	err := ba.DB.QueryRow(context.Background(), `
+	err := ba.DB.QueryRow(ctx, `
 		SELECT count(*) 
 		FROM bank_accounts 
 		WHERE id = $1`, id).Scan(&count)
@@ -36,11 +39,29 @@ func (ba *BankAccounts) ExistsByID(id string) (bool, error) {
 	return count == 1, nil
 }
 
-func (ba *BankAccounts) GetAllByBudgetID(budgetID string) ([]*models.BankAccount, error) {
-	rows, err := ba.DB.Query(context.Background(), `
-		SELECT id, budget_id, name
+func (ba *BankAccounts) ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error) {
+	var count int
+	err := ba.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM bank_accounts
+		WHERE
+			budget_id = $1 AND
+			name = $2`,
+		budgetID,
+		name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (ba *BankAccounts) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.BankAccount, error) {
+	rows, err := ba.DB.Query(ctx, `
+		SELECT id, budget_id, name, type, closed, currency
 		FROM bank_accounts
-		WHERE budget_id = $1`, budgetID)
+		WHERE budget_id = $1
+		ORDER BY name, id`, budgetID)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +70,7 @@ func (ba *BankAccounts) GetAllByBudgetID(budgetID string) ([]*models.BankAccount
 	accounts := []*models.BankAccount{}
 	for rows.Next() {
 		account := &models.BankAccount{}
-		err := rows.Scan(&account.ID, &account.BudgetID, &account.Name)
+		err := rows.Scan(&account.ID, &account.BudgetID, &account.Name, &account.Type, &account.Closed, &account.Currency)
 		if err != nil {
 			return nil, err
 		}
@@ -60,12 +81,57 @@ func (ba *BankAccounts) GetAllByBudgetID(budgetID string) ([]*models.BankAccount
 	return accounts, nil
 }
 
-func (ba *BankAccounts) GetByID(id string) (*models.BankAccount, error) {
+// bankAccountSortColumns maps the keys bank accounts can be sorted by to
+// their columns
+var bankAccountSortColumns = map[string]sortColumn{
+	"name": {expression: "name", cast: "TEXT"},
+}
+
+// GetPageByBudgetID gets a page of the bank accounts in the budget, and the
+// cursor of its last bank account if there is another page
+func (ba *BankAccounts) GetPageByBudgetID(ctx context.Context, budgetID string, page *pagination.Params) ([]*models.BankAccount, *pagination.Cursor, error) {
+	after, orderLimit, args, err := pageClauses(page, bankAccountSortColumns, "id", []interface{}{budgetID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := ba.DB.Query(ctx, `
+		SELECT id, budget_id, name, type, closed, currency
+		FROM bank_accounts
+		WHERE
+			budget_id = $1 AND
+			`+after+`
+		`+orderLimit, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*models.BankAccount{}
+	for rows.Next() {
+		account := &models.BankAccount{}
+		err := rows.Scan(&account.ID, &account.BudgetID, &account.Name, &account.Type, &account.Closed, &account.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	if len(accounts) <= page.Limit {
+		return accounts, nil, nil
+	}
+	accounts = accounts[:page.Limit]
+	last := accounts[len(accounts)-1]
+	return accounts, page.Next(last.Name, last.ID), nil
+}
+
+func (ba *BankAccounts) GetByID(ctx context.Context, id string) (*models.BankAccount, error) {
 	account := &models.BankAccount{}
-	err := ba.DB.QueryRow(context.Background(), `
-		SELECT id, budget_id, name
+	err := ba.DB.QueryRow(ctx, `
+		SELECT id, budget_id, name, type, closed, currency
 		FROM bank_accounts 
-		WHERE id = $1`, id).Scan(&account.ID, &account.BudgetID, &account.Name)
+		WHERE id = $1`, id).Scan(&account.ID, &account.BudgetID, &account.Name, &account.Type, &account.Closed, &account.Currency)
 	if err != nil {
 		return nil, err
 	}
@@ -73,10 +139,10 @@ func (ba *BankAccounts) GetByID(id string) (*models.BankAccount, error) {
 	return account, nil
 }
 
-func (ba *BankAccounts) Create(account *models.BankAccount) error {
-	tag, err := ba.DB.Exec(context.Background(), `
-		INSERT INTO bank_accounts (id, budget_id, name)
-		VALUES ($1, $2, $3)`, account.ID, account.BudgetID, account.Name)
+func (ba *BankAccounts) Create(ctx context.Context, account *models.BankAccount) error {
+	tag, err := ba.DB.Exec(ctx, `
+		INSERT INTO bank_accounts (id, budget_id, name, type, closed, currency)
+		VALUES ($1, $2, $3, $4, $5, $6)`, account.ID, account.BudgetID, account.Name, account.Type, account.Closed, account.Currency)
 	if err != nil {
 		return err
 	}
@@ -87,8 +153,8 @@ func (ba *BankAccounts) Create(account *models.BankAccount) error {
 	return nil
 }
 
-func (ba *BankAccounts) DeleteByID(id string) error {
-	tag, err := ba.DB.Exec(context.Background(), `
+func (ba *BankAccounts) DeleteByID(ctx context.Context, id string) error {
+	tag, err := ba.DB.Exec(ctx, `
 		DELETE FROM bank_accounts
 		WHERE id = $1`, id)
 	if err != nil {
@@ -101,11 +167,16 @@ func (ba *BankAccounts) DeleteByID(id string) error {
 	return nil
 }
 
-func (ba *BankAccounts) Update(account *models.BankAccount) error {
-	tag, err := ba.DB.Exec(context.Background(), `
+func (ba *BankAccounts) Update(ctx context.Context, account *models.BankAccount) error {
+	tag, err := ba.DB.Exec(ctx, `
 		UPDATE bank_accounts 
-		SET budget_id = $2, name = $3 
-		WHERE id = $1`, account.ID, account.Name, account.BudgetID)
+		SET
+			budget_id = $2,
+			name = $3,
+			type = $4,
+			closed = $5,
+			currency = $6
+		WHERE id = $1`, account.ID, account.BudgetID, account.Name, account.Type, account.Closed, account.Currency)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,232 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IBudgetInvitations interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.BudgetInvitation, error)
+	GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.BudgetInvitation, error)
+	GetByID(ctx context.Context, id string) (*models.BudgetInvitation, error)
+	Create(ctx context.Context, invitation *models.BudgetInvitation) error
+	DeleteByID(ctx context.Context, id string) error
+	Accept(ctx context.Context, id string) error
+}
+
+type BudgetInvitations struct {
+	DB database.IHandler
+}
+
+func (bi *BudgetInvitations) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := bi.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM budget_invitations
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (bi *BudgetInvitations) ExistsByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (bool, error) {
+	var count int
+	err := bi.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM budget_invitations
+		WHERE
+			budget_id = $1 AND
+			user_account_id = $2`,
+		budgetID,
+		userAccountID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (bi *BudgetInvitations) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.BudgetInvitation, error) {
+	rows, err := bi.DB.Query(ctx, `
+		SELECT
+			bi.id,
+			bi.budget_id,
+			b.name,
+			bi.user_account_id,
+			ua.username,
+			bi.invited_by_user_account_id,
+			bi.role,
+			bi.created_at
+		FROM budget_invitations bi
+		JOIN budgets b ON b.id = bi.budget_id
+		JOIN user_accounts ua ON ua.id = bi.user_account_id
+		WHERE bi.budget_id = $1
+		ORDER BY bi.created_at, bi.id`, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.BudgetInvitation{}
+	for rows.Next() {
+		invitation := &models.BudgetInvitation{}
+		err := rows.Scan(
+			&invitation.ID,
+			&invitation.BudgetID,
+			&invitation.BudgetName,
+			&invitation.UserAccountID,
+			&invitation.Username,
+			&invitation.InvitedByUserAccountID,
+			&invitation.Role,
+			&invitation.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+func (bi *BudgetInvitations) GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.BudgetInvitation, error) {
+	rows, err := bi.DB.Query(ctx, `
+		SELECT
+			bi.id,
+			bi.budget_id,
+			b.name,
+			bi.user_account_id,
+			ua.username,
+			bi.invited_by_user_account_id,
+			bi.role,
+			bi.created_at
+		FROM budget_invitations bi
+		JOIN budgets b ON b.id = bi.budget_id
+		JOIN user_accounts ua ON ua.id = bi.user_account_id
+		WHERE bi.user_account_id = $1
+		ORDER BY bi.created_at, bi.id`, userAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.BudgetInvitation{}
+	for rows.Next() {
+		invitation := &models.BudgetInvitation{}
+		err := rows.Scan(
+			&invitation.ID,
+			&invitation.BudgetID,
+			&invitation.BudgetName,
+			&invitation.UserAccountID,
+			&invitation.Username,
+			&invitation.InvitedByUserAccountID,
+			&invitation.Role,
+			&invitation.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+func (bi *BudgetInvitations) GetByID(ctx context.Context, id string) (*models.BudgetInvitation, error) {
+	invitation := &models.BudgetInvitation{}
+	err := bi.DB.QueryRow(ctx, `
+		SELECT
+			bi.id,
+			bi.budget_id,
+			b.name,
+			bi.user_account_id,
+			ua.username,
+			bi.invited_by_user_account_id,
+			bi.role,
+			bi.created_at
+		FROM budget_invitations bi
+		JOIN budgets b ON b.id = bi.budget_id
+		JOIN user_accounts ua ON ua.id = bi.user_account_id
+		WHERE bi.id = $1`, id).Scan(
+		&invitation.ID,
+		&invitation.BudgetID,
+		&invitation.BudgetName,
+		&invitation.UserAccountID,
+		&invitation.Username,
+		&invitation.InvitedByUserAccountID,
+		&invitation.Role,
+		&invitation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (bi *BudgetInvitations) Create(ctx context.Context, invitation *models.BudgetInvitation) error {
+	tag, err := bi.DB.Exec(ctx, `
+		INSERT INTO budget_invitations (
+			id,
+			budget_id,
+			user_account_id,
+			invited_by_user_account_id,
+			role
+		)
+		VALUES ($1, $2, $3, $4, $5)`,
+		invitation.ID,
+		invitation.BudgetID,
+		invitation.UserAccountID,
+		invitation.InvitedByUserAccountID,
+		invitation.Role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create budget invitation: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (bi *BudgetInvitations) DeleteByID(ctx context.Context, id string) error {
+	tag, err := bi.DB.Exec(ctx, `
+		DELETE FROM budget_invitations
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete budget invitation: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// Accept turns the invitation into a membership of its budget in a single
+// statement, so that an invitation is never both accepted and left pending
+func (bi *BudgetInvitations) Accept(ctx context.Context, id string) error {
+	tag, err := bi.DB.Exec(ctx, `
+		WITH accepted_invitation AS (
+			DELETE FROM budget_invitations
+			WHERE id = $1
+			RETURNING budget_id, user_account_id, role
+		)
+		INSERT INTO budget_memberships (budget_id, user_account_id, role)
+		SELECT budget_id, user_account_id, role
+		FROM accepted_invitation`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to accept budget invitation: unexpected number of rows affected")
+	}
+
+	return nil
+}
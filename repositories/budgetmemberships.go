@@ -0,0 +1,171 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IBudgetMemberships interface {
+	ExistsByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.BudgetMembership, error)
+	GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.BudgetMembership, error)
+	GetByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (*models.BudgetMembership, error)
+	DeleteByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) error
+	Update(ctx context.Context, membership *models.BudgetMembership) error
+}
+
+type BudgetMemberships struct {
+	DB database.IHandler
+}
+
+func (bm *BudgetMemberships) ExistsByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (bool, error) {
+	var count int
+	err := bm.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM budget_memberships
+		WHERE
+			budget_id = $1 AND
+			user_account_id = $2`,
+		budgetID,
+		userAccountID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (bm *BudgetMemberships) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.BudgetMembership, error) {
+	rows, err := bm.DB.Query(ctx, `
+		SELECT
+			bm.budget_id,
+			bm.user_account_id,
+			ua.username,
+			bm.role
+		FROM budget_memberships bm
+		JOIN user_accounts ua ON ua.id = bm.user_account_id
+		WHERE bm.budget_id = $1
+		ORDER BY ua.username`, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []*models.BudgetMembership{}
+	for rows.Next() {
+		membership := &models.BudgetMembership{}
+		err := rows.Scan(
+			&membership.BudgetID,
+			&membership.UserAccountID,
+			&membership.Username,
+			&membership.Role)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, nil
+}
+
+func (bm *BudgetMemberships) GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.BudgetMembership, error) {
+	rows, err := bm.DB.Query(ctx, `
+		SELECT
+			bm.budget_id,
+			bm.user_account_id,
+			ua.username,
+			bm.role
+		FROM budget_memberships bm
+		JOIN user_accounts ua ON ua.id = bm.user_account_id
+		WHERE bm.user_account_id = $1
+		ORDER BY bm.budget_id`, userAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []*models.BudgetMembership{}
+	for rows.Next() {
+		membership := &models.BudgetMembership{}
+		err := rows.Scan(
+			&membership.BudgetID,
+			&membership.UserAccountID,
+			&membership.Username,
+			&membership.Role)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, nil
+}
+
+func (bm *BudgetMemberships) GetByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (*models.BudgetMembership, error) {
+	membership := &models.BudgetMembership{}
+	err := bm.DB.QueryRow(ctx, `
+		SELECT
+			bm.budget_id,
+			bm.user_account_id,
+			ua.username,
+			bm.role
+		FROM budget_memberships bm
+		JOIN user_accounts ua ON ua.id = bm.user_account_id
+		WHERE
+			bm.budget_id = $1 AND
+			bm.user_account_id = $2`,
+		budgetID,
+		userAccountID).Scan(
+		&membership.BudgetID,
+		&membership.UserAccountID,
+		&membership.Username,
+		&membership.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+func (bm *BudgetMemberships) DeleteByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) error {
+	tag, err := bm.DB.Exec(ctx, `
+		DELETE FROM budget_memberships
+		WHERE
+			budget_id = $1 AND
+			user_account_id = $2`,
+		budgetID,
+		userAccountID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete budget membership: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (bm *BudgetMemberships) Update(ctx context.Context, membership *models.BudgetMembership) error {
+	tag, err := bm.DB.Exec(ctx, `
+		UPDATE budget_memberships
+		SET role = $3
+		WHERE
+			budget_id = $1 AND
+			user_account_id = $2`,
+		membership.BudgetID,
+		membership.UserAccountID,
+		membership.Role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update budget membership: unexpected number of rows affected")
+	}
+
+	return nil
+}
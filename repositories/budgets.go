@@ -8,26 +8,42 @@ import (
 
 	"github.com/paulwrubel/moneybags-server/database"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 )
 
 type IBudgets interface {
-	GetAllByUserAccountID(userAccountID string) ([]*models.Budget, error)
-	ExistsByID(id string) (bool, error)
-	ExistsByUserIDAndName(userID, name string) (bool, error)
-	GetByID(id string) (*models.Budget, error)
-	GetByUserIDAndName(userAccountID, name string) (*models.Budget, error)
-	Create(budget *models.Budget) error
-	DeleteByID(id string) error
-	Update(budget *models.Budget) error
+	Count(ctx context.Context) (int, error)
+	GetPageByUserAccountID(ctx context.Context, userAccountID string, page *pagination.Params) ([]*models.Budget, *pagination.Cursor, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByUserIDAndName(ctx context.Context, userID, name string) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.Budget, error)
+	GetByUserIDAndName(ctx context.Context, userAccountID, name string) (*models.Budget, error)
+	Create(ctx context.Context, budget *models.Budget) error
+	DeleteByID(ctx context.Context, id string) error
+	DeleteAllByUserAccountID(ctx context.Context, userAccountID string) error
+	HandOverByUserAccountID(ctx context.Context, userAccountID string) error
+	Update(ctx context.Context, budget *models.Budget) error
 }
 
 type Budgets struct {
 	DB database.IHandler
 }
 
-func (b *Budgets) ExistsByID(id string) (bool, error) {
+func (b *Budgets) Count(ctx context.Context) (int, error) {
 	var count int
-	err := b.DB.QueryRow(context.Background(), `
+	err := b.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM budgets`).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (b *Budgets) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := b.DB.QueryRow(ctx, `
 		SELECT count(*) 
 		FROM budgets 
 		WHERE id = $1`, id).Scan(&count)
@@ -38,9 +54,9 @@ func (b *Budgets) ExistsByID(id string) (bool, error) {
 	return count == 1, nil
 }
 
-func (b *Budgets) ExistsByUserIDAndName(userID, name string) (bool, error) {
+func (b *Budgets) ExistsByUserIDAndName(ctx context.Context, userID, name string) (bool, error) {
 	var count int
-	err := b.DB.QueryRow(context.Background(), `
+	err := b.DB.QueryRow(ctx, `
 		SELECT count(*) 
 		FROM budgets 
 		WHERE 
@@ -55,35 +71,56 @@ func (b *Budgets) ExistsByUserIDAndName(userID, name string) (bool, error) {
 	return count == 1, nil
 }
 
-func (b *Budgets) GetAllByUserAccountID(userAccountID string) ([]*models.Budget, error) {
-	rows, err := b.DB.Query(context.Background(), `
-		SELECT id, user_account_id, name 
-		FROM budgets
-		WHERE user_account_id = $1`, userAccountID)
+// budgetSortColumns maps the keys budgets can be sorted by to their columns
+var budgetSortColumns = map[string]sortColumn{
+	"name": {expression: "b.name", cast: "TEXT"},
+}
+
+// GetPageByUserAccountID gets a page of the budgets the user is a member of,
+// and the cursor of its last budget if there is another page
+func (b *Budgets) GetPageByUserAccountID(ctx context.Context, userAccountID string, page *pagination.Params) ([]*models.Budget, *pagination.Cursor, error) {
+	after, orderLimit, args, err := pageClauses(page, budgetSortColumns, "b.id", []interface{}{userAccountID})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	rows, err := b.DB.Query(ctx, `
+		SELECT b.id, b.user_account_id, b.name, b.archived, b.currency
+		FROM budgets b
+		JOIN budget_memberships bm ON bm.budget_id = b.id
+		WHERE
+			bm.user_account_id = $1 AND
+			`+after+`
+		`+orderLimit, args...)
+	if err != nil {
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	budgets := []*models.Budget{}
 	for rows.Next() {
 		budget := &models.Budget{}
-		err := rows.Scan(&budget.ID, &budget.UserAccountID, &budget.Name)
+		err := rows.Scan(&budget.ID, &budget.UserAccountID, &budget.Name, &budget.Archived, &budget.Currency)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		budgets = append(budgets, budget)
 	}
 
-	return budgets, nil
+	if len(budgets) <= page.Limit {
+		return budgets, nil, nil
+	}
+	budgets = budgets[:page.Limit]
+	last := budgets[len(budgets)-1]
+	return budgets, page.Next(last.Name, last.ID), nil
 }
 
-func (b *Budgets) GetByID(id string) (*models.Budget, error) {
+func (b *Budgets) GetByID(ctx context.Context, id string) (*models.Budget, error) {
 	budget := &models.Budget{}
-	err := b.DB.QueryRow(context.Background(), `
-		SELECT id, user_account_id, name 
+	err := b.DB.QueryRow(ctx, `
+		SELECT id, user_account_id, name, archived, currency
 		FROM budgets 
-		WHERE id = $1`, id).Scan(&budget.ID, &budget.UserAccountID, &budget.Name)
+		WHERE id = $1`, id).Scan(&budget.ID, &budget.UserAccountID, &budget.Name, &budget.Archived, &budget.Currency)
 	if err != nil {
 		return nil, err
 	}
@@ -91,17 +128,17 @@ func (b *Budgets) GetByID(id string) (*models.Budget, error) {
 	return budget, nil
 }
 
-func (b *Budgets) GetByUserIDAndName(userAccountID, name string) (*models.Budget, error) {
+func (b *Budgets) GetByUserIDAndName(ctx context.Context, userAccountID, name string) (*models.Budget, error) {
 	budget := &models.Budget{}
-	err := b.DB.QueryRow(context.Background(), `
-		SELECT id, user_account_id, name 
+	err := b.DB.QueryRow(ctx, `
+		SELECT id, user_account_id, name, archived, currency
 		FROM budgets 
 		WHERE 
 			user_account_id = $1 AND
 			name = $2`,
 		userAccountID,
 		name,
-	).Scan(&budget.ID, &budget.Name)
+	).Scan(&budget.ID, &budget.UserAccountID, &budget.Name, &budget.Archived, &budget.Currency)
 	if err != nil {
 		return nil, err
 	}
@@ -109,10 +146,17 @@ func (b *Budgets) GetByUserIDAndName(userAccountID, name string) (*models.Budget
 	return budget, nil
 }
 
-func (b *Budgets) Create(budget *models.Budget) error {
-	tag, err := b.DB.Exec(context.Background(), `
-		INSERT INTO budgets (id, user_account_id, name)
-		VALUES ($1, $2, $3)`, budget.ID, budget.UserAccountID, budget.Name)
+// Create records the budget along with its creator's owner membership
+func (b *Budgets) Create(ctx context.Context, budget *models.Budget) error {
+	tag, err := b.DB.Exec(ctx, `
+		WITH created_budget AS (
+			INSERT INTO budgets (id, user_account_id, name, archived, currency)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, user_account_id
+		)
+		INSERT INTO budget_memberships (budget_id, user_account_id, role)
+		SELECT id, user_account_id, 'owner'
+		FROM created_budget`, budget.ID, budget.UserAccountID, budget.Name, budget.Archived, budget.Currency)
 	if err != nil {
 		return err
 	}
@@ -123,8 +167,8 @@ func (b *Budgets) Create(budget *models.Budget) error {
 	return nil
 }
 
-func (b *Budgets) DeleteByID(id string) error {
-	tag, err := b.DB.Exec(context.Background(), `
+func (b *Budgets) DeleteByID(ctx context.Context, id string) error {
+	tag, err := b.DB.Exec(ctx, `
 		DELETE FROM budgets
 		WHERE id = $1`, id)
 	if err != nil {
@@ -137,13 +181,54 @@ func (b *Budgets) DeleteByID(id string) error {
 	return nil
 }
 
-func (b *Budgets) Update(budget *models.Budget) error {
-	tag, err := b.DB.Exec(context.Background(), `
+// DeleteAllByUserAccountID removes every budget recorded as created by the
+// user. Budgets which others still use should be handed over to them with
+// HandOverByUserAccountID first.
+func (b *Budgets) DeleteAllByUserAccountID(ctx context.Context, userAccountID string) error {
+	_, err := b.DB.Exec(ctx, `
+		DELETE FROM budgets
+		WHERE user_account_id = $1`, userAccountID)
+	return err
+}
+
+// HandOverByUserAccountID records one of the other owners of each budget
+// created by the user as its creator instead. Budgets without another owner
+// are left alone.
+func (b *Budgets) HandOverByUserAccountID(ctx context.Context, userAccountID string) error {
+	_, err := b.DB.Exec(ctx, `
+		UPDATE budgets b
+		SET user_account_id = (
+			SELECT bm.user_account_id
+			FROM budget_memberships bm
+			WHERE
+				bm.budget_id = b.id AND
+				bm.user_account_id <> $1 AND
+				bm.role = 'owner'
+			ORDER BY bm.user_account_id
+			LIMIT 1
+		)
+		WHERE
+			b.user_account_id = $1 AND
+			EXISTS (
+				SELECT 1
+				FROM budget_memberships bm
+				WHERE
+					bm.budget_id = b.id AND
+					bm.user_account_id <> $1 AND
+					bm.role = 'owner'
+			)`, userAccountID)
+	return err
+}
+
+func (b *Budgets) Update(ctx context.Context, budget *models.Budget) error {
+	tag, err := b.DB.Exec(ctx, `
 		UPDATE budgets
 		SET 
 			user_account_id = $2,
-			name = $3
-		WHERE id = $1`, budget.ID, budget.UserAccountID, budget.Name)
+			name = $3,
+			archived = $4,
+			currency = $5
+		WHERE id = $1`, budget.ID, budget.UserAccountID, budget.Name, budget.Archived, budget.Currency)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,139 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ICategories interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByCategoryGroupIDAndName(ctx context.Context, categoryGroupID, name string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.Category, error)
+	GetByID(ctx context.Context, id string) (*models.Category, error)
+	Create(ctx context.Context, category *models.Category) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, category *models.Category) error
+}
+
+type Categories struct {
+	DB database.IHandler
+}
+
+func (c *Categories) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := c.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM categories
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (c *Categories) ExistsByCategoryGroupIDAndName(ctx context.Context, categoryGroupID, name string) (bool, error) {
+	var count int
+	err := c.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM categories
+		WHERE
+			category_group_id = $1 AND
+			name = $2`,
+		categoryGroupID,
+		name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (c *Categories) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.Category, error) {
+	rows, err := c.DB.Query(ctx, `
+		SELECT c.id, c.category_group_id, c.name
+		FROM categories c
+		JOIN category_groups cg ON cg.id = c.category_group_id
+		WHERE cg.budget_id = $1
+		ORDER BY c.name, c.id`, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*models.Category{}
+	for rows.Next() {
+		category := &models.Category{}
+		err := rows.Scan(&category.ID, &category.CategoryGroupID, &category.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+func (c *Categories) GetByID(ctx context.Context, id string) (*models.Category, error) {
+	category := &models.Category{}
+	err := c.DB.QueryRow(ctx, `
+		SELECT id, category_group_id, name
+		FROM categories
+		WHERE id = $1`, id).Scan(&category.ID, &category.CategoryGroupID, &category.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (c *Categories) Create(ctx context.Context, category *models.Category) error {
+	tag, err := c.DB.Exec(ctx, `
+		INSERT INTO categories (id, category_group_id, name)
+		VALUES ($1, $2, $3)`, category.ID, category.CategoryGroupID, category.Name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create category: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (c *Categories) DeleteByID(ctx context.Context, id string) error {
+	tag, err := c.DB.Exec(ctx, `
+		DELETE FROM categories
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete category: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (c *Categories) Update(ctx context.Context, category *models.Category) error {
+	tag, err := c.DB.Exec(ctx, `
+		UPDATE categories
+		SET
+			category_group_id = $2,
+			name = $3
+		WHERE id = $1`, category.ID, category.CategoryGroupID, category.Name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update category: unexpected number of rows affected")
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ICategorizationRules interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.CategorizationRule, error)
+	GetByID(ctx context.Context, id string) (*models.CategorizationRule, error)
+	Create(ctx context.Context, rule *models.CategorizationRule) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, rule *models.CategorizationRule) error
+}
+
+type CategorizationRules struct {
+	DB database.IHandler
+}
+
+func (cr *CategorizationRules) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := cr.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM categorization_rules
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+// GetAllByBudgetID gets the budget's rules in the order they are tried
+func (cr *CategorizationRules) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.CategorizationRule, error) {
+	rows, err := cr.DB.Query(ctx, `
+		SELECT
+			id,
+			budget_id,
+			category_id,
+			payee_contains,
+			amount_min,
+			amount_max,
+			priority
+		FROM categorization_rules
+		WHERE budget_id = $1
+		ORDER BY priority, id`, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*models.CategorizationRule{}
+	for rows.Next() {
+		rule := &models.CategorizationRule{}
+		err := rows.Scan(
+			&rule.ID,
+			&rule.BudgetID,
+			&rule.CategoryID,
+			&rule.PayeeContains,
+			&rule.AmountMin,
+			&rule.AmountMax,
+			&rule.Priority)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (cr *CategorizationRules) GetByID(ctx context.Context, id string) (*models.CategorizationRule, error) {
+	rule := &models.CategorizationRule{}
+	err := cr.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			budget_id,
+			category_id,
+			payee_contains,
+			amount_min,
+			amount_max,
+			priority
+		FROM categorization_rules
+		WHERE id = $1`, id).Scan(
+		&rule.ID,
+		&rule.BudgetID,
+		&rule.CategoryID,
+		&rule.PayeeContains,
+		&rule.AmountMin,
+		&rule.AmountMax,
+		&rule.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (cr *CategorizationRules) Create(ctx context.Context, rule *models.CategorizationRule) error {
+	tag, err := cr.DB.Exec(ctx, `
+		INSERT INTO categorization_rules (
+			id,
+			budget_id,
+			category_id,
+			payee_contains,
+			amount_min,
+			amount_max,
+			priority
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)`,
+		rule.ID,
+		rule.BudgetID,
+		rule.CategoryID,
+		rule.PayeeContains,
+		rule.AmountMin,
+		rule.AmountMax,
+		rule.Priority)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create categorization rule: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (cr *CategorizationRules) DeleteByID(ctx context.Context, id string) error {
+	tag, err := cr.DB.Exec(ctx, `
+		DELETE FROM categorization_rules
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete categorization rule: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (cr *CategorizationRules) Update(ctx context.Context, rule *models.CategorizationRule) error {
+	tag, err := cr.DB.Exec(ctx, `
+		UPDATE categorization_rules
+		SET
+			category_id = $2,
+			payee_contains = $3,
+			amount_min = $4,
+			amount_max = $5,
+			priority = $6
+		WHERE id = $1`,
+		rule.ID,
+		rule.CategoryID,
+		rule.PayeeContains,
+		rule.AmountMin,
+		rule.AmountMax,
+		rule.Priority)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update categorization rule: unexpected number of rows affected")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ICategoryAllocations interface {
+	ExistsByBudgetID(ctx context.Context, budgetID string) (bool, error)
+	GetAssignedByBudgetID(ctx context.Context, budgetID string, from, to time.Time) (map[string]int64, error)
+	Upsert(ctx context.Context, allocation *models.CategoryAllocation) error
+}
+
+type CategoryAllocations struct {
+	DB database.IHandler
+}
+
+// ExistsByBudgetID reports whether any category in the budget has had an
+// amount assigned in any month
+func (ca *CategoryAllocations) ExistsByBudgetID(ctx context.Context, budgetID string) (bool, error) {
+	var count int
+	err := ca.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM category_allocations ca
+		JOIN categories c ON c.id = ca.category_id
+		JOIN category_groups cg ON cg.id = c.category_group_id
+		WHERE cg.budget_id = $1`, budgetID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetAssignedByBudgetID sums the assigned amounts of every category in the budget
+// for the months in the half-open range [from, to), keyed by category ID
+func (ca *CategoryAllocations) GetAssignedByBudgetID(ctx context.Context, budgetID string, from, to time.Time) (map[string]int64, error) {
+	rows, err := ca.DB.Query(ctx, `
+		SELECT ca.category_id, sum(ca.assigned)::BIGINT
+		FROM category_allocations ca
+		JOIN categories c ON c.id = ca.category_id
+		JOIN category_groups cg ON cg.id = c.category_group_id
+		WHERE
+			cg.budget_id = $1 AND
+			ca.month >= $2 AND
+			ca.month < $3
+		GROUP BY ca.category_id`, budgetID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assigned := map[string]int64{}
+	for rows.Next() {
+		var categoryID string
+		var amount int64
+		err := rows.Scan(&categoryID, &amount)
+		if err != nil {
+			return nil, err
+		}
+
+		assigned[categoryID] = amount
+	}
+
+	return assigned, nil
+}
+
+func (ca *CategoryAllocations) Upsert(ctx context.Context, allocation *models.CategoryAllocation) error {
+	tag, err := ca.DB.Exec(ctx, `
+		INSERT INTO category_allocations (category_id, month, assigned)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (category_id, month) DO UPDATE
+		SET assigned = EXCLUDED.assigned`,
+		allocation.CategoryID,
+		allocation.Month,
+		allocation.Assigned)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to upsert category allocation: unexpected number of rows affected")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ICategoryGroups interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.CategoryGroup, error)
+	GetByID(ctx context.Context, id string) (*models.CategoryGroup, error)
+	Create(ctx context.Context, categoryGroup *models.CategoryGroup) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, categoryGroup *models.CategoryGroup) error
+}
+
+type CategoryGroups struct {
+	DB database.IHandler
+}
+
+func (cg *CategoryGroups) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := cg.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM category_groups
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (cg *CategoryGroups) ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error) {
+	var count int
+	err := cg.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM category_groups
+		WHERE
+			budget_id = $1 AND
+			name = $2`,
+		budgetID,
+		name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (cg *CategoryGroups) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.CategoryGroup, error) {
+	rows, err := cg.DB.Query(ctx, `
+		SELECT id, budget_id, name
+		FROM category_groups
+		WHERE budget_id = $1
+		ORDER BY name, id`, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categoryGroups := []*models.CategoryGroup{}
+	for rows.Next() {
+		categoryGroup := &models.CategoryGroup{}
+		err := rows.Scan(&categoryGroup.ID, &categoryGroup.BudgetID, &categoryGroup.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		categoryGroups = append(categoryGroups, categoryGroup)
+	}
+
+	return categoryGroups, nil
+}
+
+func (cg *CategoryGroups) GetByID(ctx context.Context, id string) (*models.CategoryGroup, error) {
+	categoryGroup := &models.CategoryGroup{}
+	err := cg.DB.QueryRow(ctx, `
+		SELECT id, budget_id, name
+		FROM category_groups
+		WHERE id = $1`, id).Scan(&categoryGroup.ID, &categoryGroup.BudgetID, &categoryGroup.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryGroup, nil
+}
+
+func (cg *CategoryGroups) Create(ctx context.Context, categoryGroup *models.CategoryGroup) error {
+	tag, err := cg.DB.Exec(ctx, `
+		INSERT INTO category_groups (id, budget_id, name)
+		VALUES ($1, $2, $3)`, categoryGroup.ID, categoryGroup.BudgetID, categoryGroup.Name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create category group: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (cg *CategoryGroups) DeleteByID(ctx context.Context, id string) error {
+	tag, err := cg.DB.Exec(ctx, `
+		DELETE FROM category_groups
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete category group: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (cg *CategoryGroups) Update(ctx context.Context, categoryGroup *models.CategoryGroup) error {
+	tag, err := cg.DB.Exec(ctx, `
+		UPDATE category_groups
+		SET
+			budget_id = $2,
+			name = $3
+		WHERE id = $1`, categoryGroup.ID, categoryGroup.BudgetID, categoryGroup.Name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update category group: unexpected number of rows affected")
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IExchangeRates interface {
+	ExistsByCurrencies(ctx context.Context, base, quote string, asOf time.Time) (bool, error)
+	GetLatestByCurrencies(ctx context.Context, base, quote string, asOf time.Time) (*models.ExchangeRate, error)
+	UpsertAll(ctx context.Context, rates []*models.ExchangeRate) error
+}
+
+type ExchangeRates struct {
+	DB database.IHandler
+}
+
+// ExistsByCurrencies reports whether there is a rate between the currencies,
+// in either direction, dated on or before asOf
+func (er *ExchangeRates) ExistsByCurrencies(ctx context.Context, base, quote string, asOf time.Time) (bool, error) {
+	var count int
+	err := er.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM exchange_rates
+		WHERE
+			((base = $1 AND quote = $2) OR (base = $2 AND quote = $1)) AND
+			date <= $3`, base, quote, asOf).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetLatestByCurrencies gets the most recent rate between the currencies
+// dated on or before asOf. The rate may be quoted in either direction,
+// preferring base to quote when both are quoted on the same date.
+func (er *ExchangeRates) GetLatestByCurrencies(ctx context.Context, base, quote string, asOf time.Time) (*models.ExchangeRate, error) {
+	rate := &models.ExchangeRate{}
+	var rateText string
+	err := er.DB.QueryRow(ctx, `
+		SELECT
+			base,
+			quote,
+			date,
+			rate::TEXT
+		FROM exchange_rates
+		WHERE
+			((base = $1 AND quote = $2) OR (base = $2 AND quote = $1)) AND
+			date <= $3
+		ORDER BY date DESC, base = $1 DESC
+		LIMIT 1`, base, quote, asOf).Scan(
+		&rate.Base,
+		&rate.Quote,
+		&rate.Date,
+		&rateText)
+	if err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	rate.Rate, ok = new(big.Rat).SetString(rateText)
+	if !ok {
+		return nil, fmt.Errorf("failed to get exchange rate: invalid rate %q", rateText)
+	}
+
+	return rate, nil
+}
+
+// UpsertAll records the rates in a single statement, replacing any rates
+// already recorded for the same currencies and dates
+func (er *ExchangeRates) UpsertAll(ctx context.Context, rates []*models.ExchangeRate) error {
+	bases := []string{}
+	quotes := []string{}
+	dates := []time.Time{}
+	values := []string{}
+	for _, rate := range rates {
+		bases = append(bases, rate.Base)
+		quotes = append(quotes, rate.Quote)
+		dates = append(dates, rate.Date)
+		values = append(values, rate.Rate.FloatString(12))
+	}
+
+	_, err := er.DB.Exec(ctx, `
+		INSERT INTO exchange_rates (
+			base,
+			quote,
+			date,
+			rate
+		)
+		SELECT r.base, r.quote, r.date, r.rate::NUMERIC
+		FROM unnest($1::TEXT[], $2::TEXT[], $3::DATE[], $4::TEXT[]) AS r (base, quote, date, rate)
+		ON CONFLICT (base, quote, date) DO UPDATE
+		SET rate = EXCLUDED.rate`,
+		bases,
+		quotes,
+		dates,
+		values)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IHealth interface {
+	Ping(ctx context.Context) error
+	GetPool() *models.DatabasePool
+	GetMigrationVersion(ctx context.Context) (int, error)
+}
+
+type Health struct {
+	DB *pgxpool.Pool
+}
+
+func (h *Health) Ping(ctx context.Context) error {
+	return h.DB.Ping(ctx)
+}
+
+func (h *Health) GetPool() *models.DatabasePool {
+	stat := h.DB.Stat()
+	return &models.DatabasePool{
+		TotalConns:        stat.TotalConns(),
+		IdleConns:         stat.IdleConns(),
+		AcquiredConns:     stat.AcquiredConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+	}
+}
+
+// GetMigrationVersion gets the version of the newest migration applied to
+// the database. Unlike the migrator, it doesn't wait for migrations in
+// progress to finish.
+func (h *Health) GetMigrationVersion(ctx context.Context) (int, error) {
+	var version int
+	err := h.DB.QueryRow(ctx, `
+		SELECT coalesce(max(version), 0)
+		FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/paulwrubel/moneybags-server/pagination"
+)
+
+// sortColumn is the expression a list is sorted by for a sort key, and the
+// type its cursor values are cast to
+type sortColumn struct {
+	expression string
+	cast       string
+}
+
+// pageClauses returns the condition selecting the rows after the page's
+// cursor, which is always true on the first page, and the ORDER BY and LIMIT
+// clauses following the WHERE clause. One row more than the limit is asked
+// for, so that the caller can tell whether there is another page. The
+// clauses' parameters are numbered after, and appended to, args.
+func pageClauses(page *pagination.Params, columns map[string]sortColumn, idColumn string, args []interface{}) (string, string, []interface{}, error) {
+	column, ok := columns[page.Sort]
+	if !ok {
+		return "", "", nil, fmt.Errorf("unknown sort key %q", page.Sort)
+	}
+
+	comparison := ">"
+	direction := "ASC"
+	if page.Descending {
+		comparison = "<"
+		direction = "DESC"
+	}
+
+	condition := "TRUE"
+	if page.After != nil {
+		args = append(args, page.After.Value, page.After.ID)
+		condition = fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d::UUID)",
+			column.expression, idColumn, comparison, len(args)-1, column.cast, len(args))
+	}
+
+	args = append(args, page.Limit+1)
+	orderLimit := fmt.Sprintf("ORDER BY %s %s, %s %s LIMIT $%d",
+		column.expression, direction, idColumn, direction, len(args))
+
+	return condition, orderLimit, args, nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IPasswordResetTokens interface {
+	ExistsByTokenHash(ctx context.Context, tokenHash string) (bool, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	Create(ctx context.Context, passwordResetToken *models.PasswordResetToken) error
+	Update(ctx context.Context, passwordResetToken *models.PasswordResetToken) error
+}
+
+type PasswordResetTokens struct {
+	DB database.IHandler
+}
+
+func (prt *PasswordResetTokens) ExistsByTokenHash(ctx context.Context, tokenHash string) (bool, error) {
+	var count int
+	err := prt.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM password_reset_tokens
+		WHERE token_hash = $1`, tokenHash).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (prt *PasswordResetTokens) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	passwordResetToken := &models.PasswordResetToken{}
+	err := prt.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			token_hash,
+			expires_at,
+			used_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1`, tokenHash).Scan(
+		&passwordResetToken.ID,
+		&passwordResetToken.UserAccountID,
+		&passwordResetToken.TokenHash,
+		&passwordResetToken.ExpiresAt,
+		&passwordResetToken.UsedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return passwordResetToken, nil
+}
+
+func (prt *PasswordResetTokens) Create(ctx context.Context, passwordResetToken *models.PasswordResetToken) error {
+	tag, err := prt.DB.Exec(ctx, `
+		INSERT INTO password_reset_tokens (
+			id,
+			user_account_id,
+			token_hash,
+			expires_at,
+			used_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)`,
+		passwordResetToken.ID,
+		passwordResetToken.UserAccountID,
+		passwordResetToken.TokenHash,
+		passwordResetToken.ExpiresAt,
+		passwordResetToken.UsedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create password reset token: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (prt *PasswordResetTokens) Update(ctx context.Context, passwordResetToken *models.PasswordResetToken) error {
+	tag, err := prt.DB.Exec(ctx, `
+		UPDATE password_reset_tokens
+		SET
+			user_account_id = $2,
+			token_hash = $3,
+			expires_at = $4,
+			used_at = $5
+		WHERE id = $1`,
+		passwordResetToken.ID,
+		passwordResetToken.UserAccountID,
+		passwordResetToken.TokenHash,
+		passwordResetToken.ExpiresAt,
+		passwordResetToken.UsedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update password reset token: unexpected number of rows affected")
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IPayees interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error)
+	GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.Payee, error)
+	GetByID(ctx context.Context, id string) (*models.Payee, error)
+	GetOrCreateByBudgetIDAndName(ctx context.Context, payee *models.Payee) (*models.Payee, error)
+	Create(ctx context.Context, payee *models.Payee) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, payee *models.Payee) error
+	Merge(ctx context.Context, sourceID, targetID string) error
+}
+
+type Payees struct {
+	DB database.IHandler
+}
+
+func (p *Payees) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := p.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM payees
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+// ExistsByBudgetIDAndName matches names case-insensitively, as payee names
+// are unique within a budget regardless of case
+func (p *Payees) ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error) {
+	var count int
+	err := p.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM payees
+		WHERE
+			budget_id = $1 AND
+			lower(name) = lower($2)`,
+		budgetID,
+		name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (p *Payees) GetAllByBudgetID(ctx context.Context, budgetID string) ([]*models.Payee, error) {
+	rows, err := p.DB.Query(ctx, `
+		SELECT id, budget_id, name, default_category_id
+		FROM payees
+		WHERE budget_id = $1
+		ORDER BY lower(name)`, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payees := []*models.Payee{}
+	for rows.Next() {
+		payee := &models.Payee{}
+		err := rows.Scan(&payee.ID, &payee.BudgetID, &payee.Name, &payee.DefaultCategoryID)
+		if err != nil {
+			return nil, err
+		}
+
+		payees = append(payees, payee)
+	}
+
+	return payees, nil
+}
+
+func (p *Payees) GetByID(ctx context.Context, id string) (*models.Payee, error) {
+	payee := &models.Payee{}
+	err := p.DB.QueryRow(ctx, `
+		SELECT id, budget_id, name, default_category_id
+		FROM payees
+		WHERE id = $1`, id).Scan(&payee.ID, &payee.BudgetID, &payee.Name, &payee.DefaultCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return payee, nil
+}
+
+// GetOrCreateByBudgetIDAndName gets the budget's payee with the same name as
+// payee, creating payee if there is none
+func (p *Payees) GetOrCreateByBudgetIDAndName(ctx context.Context, payee *models.Payee) (*models.Payee, error) {
+	existingPayee := &models.Payee{}
+	err := p.DB.QueryRow(ctx, `
+		WITH created AS (
+			INSERT INTO payees (id, budget_id, name, default_category_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (budget_id, lower(name)) DO NOTHING
+			RETURNING id, budget_id, name, default_category_id
+		)
+		SELECT id, budget_id, name, default_category_id
+		FROM created
+		UNION ALL
+		SELECT id, budget_id, name, default_category_id
+		FROM payees
+		WHERE
+			budget_id = $2 AND
+			lower(name) = lower($3)
+		LIMIT 1`,
+		payee.ID,
+		payee.BudgetID,
+		payee.Name,
+		payee.DefaultCategoryID).Scan(
+		&existingPayee.ID,
+		&existingPayee.BudgetID,
+		&existingPayee.Name,
+		&existingPayee.DefaultCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingPayee, nil
+}
+
+func (p *Payees) Create(ctx context.Context, payee *models.Payee) error {
+	tag, err := p.DB.Exec(ctx, `
+		INSERT INTO payees (id, budget_id, name, default_category_id)
+		VALUES ($1, $2, $3, $4)`, payee.ID, payee.BudgetID, payee.Name, payee.DefaultCategoryID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create payee: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (p *Payees) DeleteByID(ctx context.Context, id string) error {
+	tag, err := p.DB.Exec(ctx, `
+		DELETE FROM payees
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete payee: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// Update saves changes to a payee and, in the same statement, renames its
+// transactions to match
+func (p *Payees) Update(ctx context.Context, payee *models.Payee) error {
+	tag, err := p.DB.Exec(ctx, `
+		WITH renamed AS (
+			UPDATE transactions
+			SET payee = $2
+			WHERE payee_id = $1
+		)
+		UPDATE payees
+		SET
+			name = $2,
+			default_category_id = $3
+		WHERE id = $1`, payee.ID, payee.Name, payee.DefaultCategoryID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update payee: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// Merge moves the transactions of the source payee to the target payee and
+// deletes the source, all in a single statement. The target keeps its own
+// default category, taking the source's only if it has none.
+func (p *Payees) Merge(ctx context.Context, sourceID, targetID string) error {
+	tag, err := p.DB.Exec(ctx, `
+		WITH source AS (
+			SELECT default_category_id
+			FROM payees
+			WHERE id = $1
+		), target AS (
+			UPDATE payees
+			SET default_category_id = coalesce(default_category_id, (SELECT default_category_id FROM source))
+			WHERE id = $2
+			RETURNING name
+		), repointed AS (
+			UPDATE transactions
+			SET
+				payee_id = $2,
+				payee = (SELECT name FROM target)
+			WHERE payee_id = $1
+		)
+		DELETE FROM payees
+		WHERE id = $1`, sourceID, targetID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to merge payee: unexpected number of rows affected")
+	}
+
+	return nil
+}
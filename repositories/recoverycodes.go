@@ -0,0 +1,112 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IRecoveryCodes interface {
+	ExistsByUserAccountIDAndCodeHash(ctx context.Context, userAccountID, codeHash string) (bool, error)
+	GetByUserAccountIDAndCodeHash(ctx context.Context, userAccountID, codeHash string) (*models.RecoveryCode, error)
+	Create(ctx context.Context, recoveryCode *models.RecoveryCode) error
+	Use(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	DeleteAllByUserAccountID(ctx context.Context, userAccountID string) error
+}
+
+type RecoveryCodes struct {
+	DB database.IHandler
+}
+
+func (rc *RecoveryCodes) ExistsByUserAccountIDAndCodeHash(ctx context.Context, userAccountID, codeHash string) (bool, error) {
+	var count int
+	err := rc.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM recovery_codes
+		WHERE user_account_id = $1 AND code_hash = $2`, userAccountID, codeHash).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (rc *RecoveryCodes) GetByUserAccountIDAndCodeHash(ctx context.Context, userAccountID, codeHash string) (*models.RecoveryCode, error) {
+	recoveryCode := &models.RecoveryCode{}
+	err := rc.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			code_hash,
+			used_at
+		FROM recovery_codes
+		WHERE user_account_id = $1 AND code_hash = $2`, userAccountID, codeHash).Scan(
+		&recoveryCode.ID,
+		&recoveryCode.UserAccountID,
+		&recoveryCode.CodeHash,
+		&recoveryCode.UsedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCode, nil
+}
+
+func (rc *RecoveryCodes) Create(ctx context.Context, recoveryCode *models.RecoveryCode) error {
+	tag, err := rc.DB.Exec(ctx, `
+		INSERT INTO recovery_codes (
+			id,
+			user_account_id,
+			code_hash,
+			used_at
+		) VALUES (
+			$1, $2, $3, $4
+		)`,
+		recoveryCode.ID,
+		recoveryCode.UserAccountID,
+		recoveryCode.CodeHash,
+		recoveryCode.UsedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create recovery code: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// Use marks the recovery code used at usedAt, unless it already has been.
+// The check and the write are the same statement, so that of two concurrent
+// uses of one code only one succeeds; false is returned for the other.
+func (rc *RecoveryCodes) Use(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	tag, err := rc.DB.Exec(ctx, `
+		UPDATE recovery_codes
+		SET used_at = $2
+		WHERE
+			id = $1 AND
+			used_at IS NULL`,
+		id,
+		usedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (rc *RecoveryCodes) DeleteAllByUserAccountID(ctx context.Context, userAccountID string) error {
+	_, err := rc.DB.Exec(ctx, `
+		DELETE FROM recovery_codes
+		WHERE user_account_id = $1`, userAccountID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,330 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IScheduledTransactions interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetAllByBankAccountID(ctx context.Context, bankAccountID string) ([]*models.ScheduledTransaction, error)
+	GetAllDue(ctx context.Context, asOf time.Time) ([]*models.ScheduledTransaction, error)
+	GetByID(ctx context.Context, id string) (*models.ScheduledTransaction, error)
+	Create(ctx context.Context, scheduledTransaction *models.ScheduledTransaction) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, scheduledTransaction *models.ScheduledTransaction) error
+	UpdateNextDate(ctx context.Context, id string, nextDate *time.Time) error
+	CreateOccurrence(ctx context.Context, scheduledTransactionID string, transaction *models.Transaction) (bool, error)
+}
+
+type ScheduledTransactions struct {
+	DB database.IHandler
+}
+
+func (st *ScheduledTransactions) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := st.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM scheduled_transactions
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (st *ScheduledTransactions) GetAllByBankAccountID(ctx context.Context, bankAccountID string) ([]*models.ScheduledTransaction, error) {
+	rows, err := st.DB.Query(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			amount,
+			payee,
+			memo,
+			frequency,
+			repeat_interval,
+			day_of_month,
+			start_date,
+			end_date,
+			next_date
+		FROM scheduled_transactions
+		WHERE bank_account_id = $1
+		ORDER BY next_date NULLS LAST, payee`, bankAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scheduledTransactions := []*models.ScheduledTransaction{}
+	for rows.Next() {
+		scheduledTransaction := &models.ScheduledTransaction{}
+		err := rows.Scan(
+			&scheduledTransaction.ID,
+			&scheduledTransaction.BankAccountID,
+			&scheduledTransaction.CategoryID,
+			&scheduledTransaction.Amount,
+			&scheduledTransaction.Payee,
+			&scheduledTransaction.Memo,
+			&scheduledTransaction.Frequency,
+			&scheduledTransaction.Interval,
+			&scheduledTransaction.DayOfMonth,
+			&scheduledTransaction.StartDate,
+			&scheduledTransaction.EndDate,
+			&scheduledTransaction.NextDate)
+		if err != nil {
+			return nil, err
+		}
+
+		scheduledTransactions = append(scheduledTransactions, scheduledTransaction)
+	}
+
+	return scheduledTransactions, nil
+}
+
+// GetAllDue gets the scheduled transactions with an occurrence on or before
+// asOf which has not been materialized yet
+func (st *ScheduledTransactions) GetAllDue(ctx context.Context, asOf time.Time) ([]*models.ScheduledTransaction, error) {
+	rows, err := st.DB.Query(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			amount,
+			payee,
+			memo,
+			frequency,
+			repeat_interval,
+			day_of_month,
+			start_date,
+			end_date,
+			next_date
+		FROM scheduled_transactions
+		WHERE next_date <= $1
+		ORDER BY next_date, id`, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scheduledTransactions := []*models.ScheduledTransaction{}
+	for rows.Next() {
+		scheduledTransaction := &models.ScheduledTransaction{}
+		err := rows.Scan(
+			&scheduledTransaction.ID,
+			&scheduledTransaction.BankAccountID,
+			&scheduledTransaction.CategoryID,
+			&scheduledTransaction.Amount,
+			&scheduledTransaction.Payee,
+			&scheduledTransaction.Memo,
+			&scheduledTransaction.Frequency,
+			&scheduledTransaction.Interval,
+			&scheduledTransaction.DayOfMonth,
+			&scheduledTransaction.StartDate,
+			&scheduledTransaction.EndDate,
+			&scheduledTransaction.NextDate)
+		if err != nil {
+			return nil, err
+		}
+
+		scheduledTransactions = append(scheduledTransactions, scheduledTransaction)
+	}
+
+	return scheduledTransactions, nil
+}
+
+func (st *ScheduledTransactions) GetByID(ctx context.Context, id string) (*models.ScheduledTransaction, error) {
+	scheduledTransaction := &models.ScheduledTransaction{}
+	err := st.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			amount,
+			payee,
+			memo,
+			frequency,
+			repeat_interval,
+			day_of_month,
+			start_date,
+			end_date,
+			next_date
+		FROM scheduled_transactions
+		WHERE id = $1`, id).Scan(
+		&scheduledTransaction.ID,
+		&scheduledTransaction.BankAccountID,
+		&scheduledTransaction.CategoryID,
+		&scheduledTransaction.Amount,
+		&scheduledTransaction.Payee,
+		&scheduledTransaction.Memo,
+		&scheduledTransaction.Frequency,
+		&scheduledTransaction.Interval,
+		&scheduledTransaction.DayOfMonth,
+		&scheduledTransaction.StartDate,
+		&scheduledTransaction.EndDate,
+		&scheduledTransaction.NextDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduledTransaction, nil
+}
+
+func (st *ScheduledTransactions) Create(ctx context.Context, scheduledTransaction *models.ScheduledTransaction) error {
+	tag, err := st.DB.Exec(ctx, `
+		INSERT INTO scheduled_transactions (
+			id,
+			bank_account_id,
+			category_id,
+			amount,
+			payee,
+			memo,
+			frequency,
+			repeat_interval,
+			day_of_month,
+			start_date,
+			end_date,
+			next_date
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)`,
+		scheduledTransaction.ID,
+		scheduledTransaction.BankAccountID,
+		scheduledTransaction.CategoryID,
+		scheduledTransaction.Amount,
+		scheduledTransaction.Payee,
+		scheduledTransaction.Memo,
+		scheduledTransaction.Frequency,
+		scheduledTransaction.Interval,
+		scheduledTransaction.DayOfMonth,
+		scheduledTransaction.StartDate,
+		scheduledTransaction.EndDate,
+		scheduledTransaction.NextDate)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create scheduled transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (st *ScheduledTransactions) DeleteByID(ctx context.Context, id string) error {
+	tag, err := st.DB.Exec(ctx, `
+		DELETE FROM scheduled_transactions
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete scheduled transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (st *ScheduledTransactions) Update(ctx context.Context, scheduledTransaction *models.ScheduledTransaction) error {
+	tag, err := st.DB.Exec(ctx, `
+		UPDATE scheduled_transactions
+		SET
+			bank_account_id = $2,
+			category_id = $3,
+			amount = $4,
+			payee = $5,
+			memo = $6,
+			frequency = $7,
+			repeat_interval = $8,
+			day_of_month = $9,
+			start_date = $10,
+			end_date = $11,
+			next_date = $12
+		WHERE id = $1`,
+		scheduledTransaction.ID,
+		scheduledTransaction.BankAccountID,
+		scheduledTransaction.CategoryID,
+		scheduledTransaction.Amount,
+		scheduledTransaction.Payee,
+		scheduledTransaction.Memo,
+		scheduledTransaction.Frequency,
+		scheduledTransaction.Interval,
+		scheduledTransaction.DayOfMonth,
+		scheduledTransaction.StartDate,
+		scheduledTransaction.EndDate,
+		scheduledTransaction.NextDate)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update scheduled transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (st *ScheduledTransactions) UpdateNextDate(ctx context.Context, id string, nextDate *time.Time) error {
+	tag, err := st.DB.Exec(ctx, `
+		UPDATE scheduled_transactions
+		SET next_date = $2
+		WHERE id = $1`, id, nextDate)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update scheduled transaction next date: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// CreateOccurrence records an occurrence of the scheduled transaction on the
+// transaction's date and creates the transaction, in a single statement. If
+// the occurrence has already been recorded nothing is created and false is
+// returned.
+func (st *ScheduledTransactions) CreateOccurrence(ctx context.Context, scheduledTransactionID string, transaction *models.Transaction) (bool, error) {
+	tag, err := st.DB.Exec(ctx, `
+		WITH occurrence AS (
+			INSERT INTO scheduled_transaction_occurrences (
+				scheduled_transaction_id,
+				date,
+				transaction_id
+			) VALUES (
+				$9, $4, $1
+			)
+			ON CONFLICT DO NOTHING
+			RETURNING transaction_id
+		)
+		INSERT INTO transactions (
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared
+		)
+		SELECT $1::UUID, $2::UUID, $3::UUID, $4::DATE, $5::BIGINT, $6::TEXT, $7::TEXT, $8::TEXT
+		FROM occurrence`,
+		transaction.ID,
+		transaction.BankAccountID,
+		transaction.CategoryID,
+		transaction.Date,
+		transaction.Amount,
+		transaction.Payee,
+		transaction.Memo,
+		transaction.Cleared,
+		scheduledTransactionID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,225 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ISessions interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*models.Session, error)
+	Create(ctx context.Context, session *models.Session) error
+	Update(ctx context.Context, session *models.Session) error
+	RotateRefreshToken(ctx context.Context, refreshTokenHash, newRefreshTokenHash string, now, expiresAt time.Time) (bool, error)
+	RevokeAllByUserAccountID(ctx context.Context, userAccountID string, revokedAt time.Time) error
+	RevokeAllOthersByUserAccountID(ctx context.Context, userAccountID, sessionID string, revokedAt time.Time) error
+}
+
+type Sessions struct {
+	DB database.IHandler
+}
+
+func (s *Sessions) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := s.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM sessions
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (s *Sessions) ExistsByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (bool, error) {
+	var count int
+	err := s.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM sessions
+		WHERE refresh_token_hash = $1`, refreshTokenHash).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (s *Sessions) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	session := &models.Session{}
+	err := s.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			refresh_token_hash,
+			created_at,
+			expires_at,
+			revoked_at
+		FROM sessions
+		WHERE id = $1`, id).Scan(
+		&session.ID,
+		&session.UserAccountID,
+		&session.RefreshTokenHash,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *Sessions) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*models.Session, error) {
+	session := &models.Session{}
+	err := s.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			user_account_id,
+			refresh_token_hash,
+			created_at,
+			expires_at,
+			revoked_at
+		FROM sessions
+		WHERE refresh_token_hash = $1`, refreshTokenHash).Scan(
+		&session.ID,
+		&session.UserAccountID,
+		&session.RefreshTokenHash,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *Sessions) Create(ctx context.Context, session *models.Session) error {
+	tag, err := s.DB.Exec(ctx, `
+		INSERT INTO sessions (
+			id,
+			user_account_id,
+			refresh_token_hash,
+			created_at,
+			expires_at,
+			revoked_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`,
+		session.ID,
+		session.UserAccountID,
+		session.RefreshTokenHash,
+		session.CreatedAt,
+		session.ExpiresAt,
+		session.RevokedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create session: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (s *Sessions) Update(ctx context.Context, session *models.Session) error {
+	tag, err := s.DB.Exec(ctx, `
+		UPDATE sessions
+		SET
+			user_account_id = $2,
+			refresh_token_hash = $3,
+			created_at = $4,
+			expires_at = $5,
+			revoked_at = $6
+		WHERE id = $1`,
+		session.ID,
+		session.UserAccountID,
+		session.RefreshTokenHash,
+		session.CreatedAt,
+		session.ExpiresAt,
+		session.RevokedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update session: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// RotateRefreshToken replaces the session's refresh token, provided the
+// session is still live. The check and the update are a single statement,
+// so that two requests racing with the same refresh token can't both
+// succeed. It reports whether the token was rotated.
+func (s *Sessions) RotateRefreshToken(ctx context.Context, refreshTokenHash, newRefreshTokenHash string, now, expiresAt time.Time) (bool, error) {
+	tag, err := s.DB.Exec(ctx, `
+		UPDATE sessions
+		SET
+			refresh_token_hash = $2,
+			expires_at = $4
+		WHERE
+			refresh_token_hash = $1 AND
+			revoked_at IS NULL AND
+			expires_at > $3`,
+		refreshTokenHash,
+		newRefreshTokenHash,
+		now,
+		expiresAt)
+	if err != nil {
+		return false, err
+	}
+	switch tag.RowsAffected() {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, errors.New("failed to rotate refresh token: unexpected number of rows affected")
+	}
+}
+
+func (s *Sessions) RevokeAllByUserAccountID(ctx context.Context, userAccountID string, revokedAt time.Time) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = $2
+		WHERE
+			user_account_id = $1 AND
+			revoked_at IS NULL`,
+		userAccountID,
+		revokedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeAllOthersByUserAccountID revokes all of the user's sessions other
+// than sessionID
+func (s *Sessions) RevokeAllOthersByUserAccountID(ctx context.Context, userAccountID, sessionID string, revokedAt time.Time) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = $3
+		WHERE
+			user_account_id = $1 AND
+			id <> $2 AND
+			revoked_at IS NULL`,
+		userAccountID,
+		sessionID,
+		revokedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
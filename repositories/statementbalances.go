@@ -0,0 +1,85 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type IStatementBalances interface {
+	ExistsByBankAccountID(ctx context.Context, bankAccountID string) (bool, error)
+	GetLatestByBankAccountID(ctx context.Context, bankAccountID string) (*models.StatementBalance, error)
+	Upsert(ctx context.Context, statementBalance *models.StatementBalance) error
+}
+
+type StatementBalances struct {
+	DB database.IHandler
+}
+
+func (sb *StatementBalances) ExistsByBankAccountID(ctx context.Context, bankAccountID string) (bool, error) {
+	var count int
+	err := sb.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM statement_balances
+		WHERE bank_account_id = $1`, bankAccountID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (sb *StatementBalances) GetLatestByBankAccountID(ctx context.Context, bankAccountID string) (*models.StatementBalance, error) {
+	statementBalance := &models.StatementBalance{}
+	err := sb.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			date,
+			balance
+		FROM statement_balances
+		WHERE bank_account_id = $1
+		ORDER BY date DESC
+		LIMIT 1`, bankAccountID).Scan(
+		&statementBalance.ID,
+		&statementBalance.BankAccountID,
+		&statementBalance.Date,
+		&statementBalance.Balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return statementBalance, nil
+}
+
+// Upsert records the statement balance, replacing any balance already
+// recorded for the same account and date
+func (sb *StatementBalances) Upsert(ctx context.Context, statementBalance *models.StatementBalance) error {
+	tag, err := sb.DB.Exec(ctx, `
+		INSERT INTO statement_balances (
+			id,
+			bank_account_id,
+			date,
+			balance
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (bank_account_id, date) DO UPDATE
+		SET balance = EXCLUDED.balance`,
+		statementBalance.ID,
+		statementBalance.BankAccountID,
+		statementBalance.Date,
+		statementBalance.Balance)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to upsert statement balance: unexpected number of rows affected")
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ITOTPCredentials interface {
+	ExistsByUserAccountID(ctx context.Context, userAccountID string) (bool, error)
+	GetByUserAccountID(ctx context.Context, userAccountID string) (*models.TOTPCredential, error)
+	Create(ctx context.Context, totpCredential *models.TOTPCredential) error
+	Update(ctx context.Context, totpCredential *models.TOTPCredential) error
+	UseStep(ctx context.Context, userAccountID string, step int64) (bool, error)
+	RecordFailedAttempt(ctx context.Context, userAccountID string, maxAttempts int, lockedUntil time.Time) error
+	ResetFailedAttempts(ctx context.Context, userAccountID string) error
+	DeleteByUserAccountID(ctx context.Context, userAccountID string) error
+}
+
+type TOTPCredentials struct {
+	DB database.IHandler
+}
+
+func (tc *TOTPCredentials) ExistsByUserAccountID(ctx context.Context, userAccountID string) (bool, error) {
+	var count int
+	err := tc.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM totp_credentials
+		WHERE user_account_id = $1`, userAccountID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (tc *TOTPCredentials) GetByUserAccountID(ctx context.Context, userAccountID string) (*models.TOTPCredential, error) {
+	totpCredential := &models.TOTPCredential{}
+	err := tc.DB.QueryRow(ctx, `
+		SELECT
+			user_account_id,
+			secret,
+			enabled,
+			last_used_step,
+			failed_attempts,
+			locked_until
+		FROM totp_credentials
+		WHERE user_account_id = $1`, userAccountID).Scan(
+		&totpCredential.UserAccountID,
+		&totpCredential.Secret,
+		&totpCredential.Enabled,
+		&totpCredential.LastUsedStep,
+		&totpCredential.FailedAttempts,
+		&totpCredential.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	return totpCredential, nil
+}
+
+func (tc *TOTPCredentials) Create(ctx context.Context, totpCredential *models.TOTPCredential) error {
+	tag, err := tc.DB.Exec(ctx, `
+		INSERT INTO totp_credentials (
+			user_account_id,
+			secret,
+			enabled,
+			last_used_step
+		) VALUES (
+			$1, $2, $3, $4
+		)`,
+		totpCredential.UserAccountID,
+		totpCredential.Secret,
+		totpCredential.Enabled,
+		totpCredential.LastUsedStep)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create totp credential: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (tc *TOTPCredentials) Update(ctx context.Context, totpCredential *models.TOTPCredential) error {
+	tag, err := tc.DB.Exec(ctx, `
+		UPDATE totp_credentials
+		SET
+			secret = $2,
+			enabled = $3,
+			last_used_step = $4
+		WHERE user_account_id = $1`,
+		totpCredential.UserAccountID,
+		totpCredential.Secret,
+		totpCredential.Enabled,
+		totpCredential.LastUsedStep)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update totp credential: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// UseStep records that the code for step has been used, unless a code for it
+// or a later step already has been. The check and the write are the same
+// statement, so that of two concurrent uses of one code only one succeeds;
+// false is returned for the other.
+func (tc *TOTPCredentials) UseStep(ctx context.Context, userAccountID string, step int64) (bool, error) {
+	tag, err := tc.DB.Exec(ctx, `
+		UPDATE totp_credentials
+		SET last_used_step = $2
+		WHERE
+			user_account_id = $1 AND
+			last_used_step < $2`,
+		userAccountID,
+		step)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RecordFailedAttempt counts a wrong code. The count is checked and reset in
+// the same statement, so that concurrent attempts can't slip past
+// maxAttempts, and the credential is locked until lockedUntil once it is
+// reached.
+func (tc *TOTPCredentials) RecordFailedAttempt(ctx context.Context, userAccountID string, maxAttempts int, lockedUntil time.Time) error {
+	tag, err := tc.DB.Exec(ctx, `
+		UPDATE totp_credentials
+		SET
+			failed_attempts = CASE
+				WHEN failed_attempts + 1 >= $2 THEN 0
+				ELSE failed_attempts + 1
+			END,
+			locked_until = CASE
+				WHEN failed_attempts + 1 >= $2 THEN $3
+				ELSE locked_until
+			END
+		WHERE user_account_id = $1`,
+		userAccountID,
+		maxAttempts,
+		lockedUntil)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to record failed totp attempt: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (tc *TOTPCredentials) ResetFailedAttempts(ctx context.Context, userAccountID string) error {
+	tag, err := tc.DB.Exec(ctx, `
+		UPDATE totp_credentials
+		SET
+			failed_attempts = 0,
+			locked_until = NULL
+		WHERE user_account_id = $1`, userAccountID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to reset failed totp attempts: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (tc *TOTPCredentials) DeleteByUserAccountID(ctx context.Context, userAccountID string) error {
+	tag, err := tc.DB.Exec(ctx, `
+		DELETE FROM totp_credentials
+		WHERE user_account_id = $1`, userAccountID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete totp credential: unexpected number of rows affected")
+	}
+
+	return nil
+}
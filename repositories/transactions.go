@@ -0,0 +1,744 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
+)
+
+type ITransactions interface {
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetPageByBankAccountID(ctx context.Context, bankAccountID string, filter *models.TransactionFilter, page *pagination.Params) ([]*models.Transaction, *pagination.Cursor, error)
+	GetAllByBankAccountIDAndDateRange(ctx context.Context, bankAccountID string, from, to time.Time) ([]*models.Transaction, error)
+	GetByID(ctx context.Context, id string) (*models.Transaction, error)
+	Create(ctx context.Context, transaction *models.Transaction) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, transaction *models.Transaction) error
+	GetActivityByBudgetID(ctx context.Context, budgetID string, from, to time.Time) (map[string]map[string]int64, error)
+	GetUncategorizedTotalByBudgetID(ctx context.Context, budgetID string, to time.Time) (map[string]int64, error)
+	GetBalanceByBankAccountID(ctx context.Context, bankAccountID string) (int64, error)
+	GetAllClearedByBankAccountID(ctx context.Context, bankAccountID string, to time.Time) ([]*models.Transaction, error)
+	GetClearedBalanceByBankAccountID(ctx context.Context, bankAccountID string, to time.Time) (int64, error)
+	ReconcileByBankAccountID(ctx context.Context, bankAccountID string, to time.Time) error
+	ExistsByTransferID(ctx context.Context, transferID string) (bool, error)
+	GetAllByTransferID(ctx context.Context, transferID string) ([]*models.Transaction, error)
+	CreateTransfer(ctx context.Context, outflow, inflow *models.Transaction) error
+	UpdateTransfer(ctx context.Context, transferID string, date time.Time, amount int64, memo *string) error
+	UpdateTransferLeg(ctx context.Context, transaction *models.Transaction) error
+	DeleteByTransferID(ctx context.Context, transferID string) error
+	UnlinkTransfersByBankAccountID(ctx context.Context, bankAccountID string) error
+}
+
+type Transactions struct {
+	DB database.IHandler
+}
+
+func (t *Transactions) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := t.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM transactions
+		WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+// transactionSortColumns maps the keys transactions can be sorted by to their
+// columns
+var transactionSortColumns = map[string]sortColumn{
+	"date":   {expression: "date", cast: "DATE"},
+	"amount": {expression: "amount", cast: "BIGINT"},
+	"payee":  {expression: "payee", cast: "TEXT"},
+}
+
+// GetPageByBankAccountID gets a page of the transactions in the bank account
+// matching the filter, and the cursor of its last transaction if there is
+// another page
+func (t *Transactions) GetPageByBankAccountID(ctx context.Context, bankAccountID string, filter *models.TransactionFilter, page *pagination.Params) ([]*models.Transaction, *pagination.Cursor, error) {
+	conditions := []string{"bank_account_id = $1"}
+	args := []interface{}{bankAccountID}
+	addCondition := func(format string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if filter.From != nil {
+		addCondition("date >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("date <= $%d", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		addCondition("amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.PayeeID != nil {
+		addCondition("payee_id = $%d", *filter.PayeeID)
+	}
+	if filter.CategoryID != nil {
+		addCondition("(category_id = $%[1]d OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id AND s.category_id = $%[1]d))", *filter.CategoryID)
+	}
+	if filter.Cleared != nil {
+		addCondition("cleared = $%d", *filter.Cleared)
+	}
+
+	after, orderLimit, args, err := pageClauses(page, transactionSortColumns, "id", args)
+	if err != nil {
+		return nil, nil, err
+	}
+	conditions = append(conditions, after)
+
+	rows, err := t.DB.Query(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			fitid,
+			transfer_id,
+			payee_id
+		FROM transactions
+		WHERE
+			`+strings.Join(conditions, " AND\n\t\t\t")+`
+		`+orderLimit, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*models.Transaction{}
+	for rows.Next() {
+		transaction := &models.Transaction{}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.BankAccountID,
+			&transaction.CategoryID,
+			&transaction.Date,
+			&transaction.Amount,
+			&transaction.Payee,
+			&transaction.Memo,
+			&transaction.Cleared,
+			&transaction.FITID,
+			&transaction.TransferID,
+			&transaction.PayeeID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	if len(transactions) <= page.Limit {
+		return transactions, nil, nil
+	}
+	transactions = transactions[:page.Limit]
+	last := transactions[len(transactions)-1]
+	var value string
+	switch page.Sort {
+	case "amount":
+		value = strconv.FormatInt(last.Amount, 10)
+	case "payee":
+		value = last.Payee
+	default:
+		value = last.Date.Format(constants.DateLayout)
+	}
+	return transactions, page.Next(value, last.ID), nil
+}
+
+// GetAllByBankAccountIDAndDateRange gets the transactions in the bank account
+// dated within the closed range [from, to]
+func (t *Transactions) GetAllByBankAccountIDAndDateRange(ctx context.Context, bankAccountID string, from, to time.Time) ([]*models.Transaction, error) {
+	rows, err := t.DB.Query(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			fitid,
+			transfer_id,
+			payee_id
+		FROM transactions
+		WHERE
+			bank_account_id = $1 AND
+			date >= $2 AND
+			date <= $3
+		ORDER BY date, id`, bankAccountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*models.Transaction{}
+	for rows.Next() {
+		transaction := &models.Transaction{}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.BankAccountID,
+			&transaction.CategoryID,
+			&transaction.Date,
+			&transaction.Amount,
+			&transaction.Payee,
+			&transaction.Memo,
+			&transaction.Cleared,
+			&transaction.FITID,
+			&transaction.TransferID,
+			&transaction.PayeeID)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+func (t *Transactions) GetByID(ctx context.Context, id string) (*models.Transaction, error) {
+	transaction := &models.Transaction{}
+	err := t.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			fitid,
+			transfer_id,
+			payee_id
+		FROM transactions
+		WHERE id = $1`, id).Scan(
+		&transaction.ID,
+		&transaction.BankAccountID,
+		&transaction.CategoryID,
+		&transaction.Date,
+		&transaction.Amount,
+		&transaction.Payee,
+		&transaction.Memo,
+		&transaction.Cleared,
+		&transaction.FITID,
+		&transaction.TransferID,
+		&transaction.PayeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (t *Transactions) Create(ctx context.Context, transaction *models.Transaction) error {
+	tag, err := t.DB.Exec(ctx, `
+		INSERT INTO transactions (
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			fitid,
+			transfer_id,
+			payee_id
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)`,
+		transaction.ID,
+		transaction.BankAccountID,
+		transaction.CategoryID,
+		transaction.Date,
+		transaction.Amount,
+		transaction.Payee,
+		transaction.Memo,
+		transaction.Cleared,
+		transaction.FITID,
+		transaction.TransferID,
+		transaction.PayeeID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to create transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (t *Transactions) DeleteByID(ctx context.Context, id string) error {
+	tag, err := t.DB.Exec(ctx, `
+		DELETE FROM transactions
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to delete transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+func (t *Transactions) Update(ctx context.Context, transaction *models.Transaction) error {
+	tag, err := t.DB.Exec(ctx, `
+		UPDATE transactions
+		SET
+			bank_account_id = $2,
+			category_id = $3,
+			date = $4,
+			amount = $5,
+			payee = $6,
+			memo = $7,
+			cleared = $8,
+			fitid = $9,
+			transfer_id = $10,
+			payee_id = $11
+		WHERE id = $1`,
+		transaction.ID,
+		transaction.BankAccountID,
+		transaction.CategoryID,
+		transaction.Date,
+		transaction.Amount,
+		transaction.Payee,
+		transaction.Memo,
+		transaction.Cleared,
+		transaction.FITID,
+		transaction.TransferID,
+		transaction.PayeeID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// GetActivityByBudgetID sums the amounts of all categorized transactions in the budget
+// dated within the half-open range [from, to), keyed by category ID and then
+// by the currency of the bank account. Split transactions are counted by
+// their splits rather than as a whole.
+func (t *Transactions) GetActivityByBudgetID(ctx context.Context, budgetID string, from, to time.Time) (map[string]map[string]int64, error) {
+	rows, err := t.DB.Query(ctx, `
+		SELECT a.category_id, a.currency, sum(a.amount)::BIGINT
+		FROM (
+			SELECT t.category_id, ba.currency, t.amount
+			FROM transactions t
+			JOIN bank_accounts ba ON ba.id = t.bank_account_id
+			WHERE
+				ba.budget_id = $1 AND
+				t.date >= $2 AND
+				t.date < $3 AND
+				NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+			UNION ALL
+			SELECT s.category_id, ba.currency, s.amount
+			FROM transaction_splits s
+			JOIN transactions t ON t.id = s.transaction_id
+			JOIN bank_accounts ba ON ba.id = t.bank_account_id
+			WHERE
+				ba.budget_id = $1 AND
+				t.date >= $2 AND
+				t.date < $3
+		) a
+		WHERE a.category_id IS NOT NULL
+		GROUP BY a.category_id, a.currency`, budgetID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := map[string]map[string]int64{}
+	for rows.Next() {
+		var categoryID, currency string
+		var amount int64
+		err := rows.Scan(&categoryID, &currency, &amount)
+		if err != nil {
+			return nil, err
+		}
+
+		if activity[categoryID] == nil {
+			activity[categoryID] = map[string]int64{}
+		}
+		activity[categoryID][currency] = amount
+	}
+
+	return activity, nil
+}
+
+// GetUncategorizedTotalByBudgetID sums the amounts of all uncategorized transactions
+// in the budget dated before to, keyed by the currency of the bank account.
+// As with activity, split transactions are counted by their uncategorized
+// splits.
+func (t *Transactions) GetUncategorizedTotalByBudgetID(ctx context.Context, budgetID string, to time.Time) (map[string]int64, error) {
+	rows, err := t.DB.Query(ctx, `
+		SELECT a.currency, sum(a.amount)::BIGINT
+		FROM (
+			SELECT ba.currency, t.amount
+			FROM transactions t
+			JOIN bank_accounts ba ON ba.id = t.bank_account_id
+			WHERE
+				ba.budget_id = $1 AND
+				t.category_id IS NULL AND
+				t.date < $2 AND
+				NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+			UNION ALL
+			SELECT ba.currency, s.amount
+			FROM transaction_splits s
+			JOIN transactions t ON t.id = s.transaction_id
+			JOIN bank_accounts ba ON ba.id = t.bank_account_id
+			WHERE
+				ba.budget_id = $1 AND
+				s.category_id IS NULL AND
+				t.date < $2
+		) a
+		GROUP BY a.currency`, budgetID, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]int64{}
+	for rows.Next() {
+		var currency string
+		var amount int64
+		err := rows.Scan(&currency, &amount)
+		if err != nil {
+			return nil, err
+		}
+
+		totals[currency] = amount
+	}
+
+	return totals, nil
+}
+
+// GetBalanceByBankAccountID sums the amounts of all transactions in the bank
+// account, in the account's currency
+func (t *Transactions) GetBalanceByBankAccountID(ctx context.Context, bankAccountID string) (int64, error) {
+	var total int64
+	err := t.DB.QueryRow(ctx, `
+		SELECT coalesce(sum(amount), 0)::BIGINT
+		FROM transactions
+		WHERE bank_account_id = $1`, bankAccountID).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetAllClearedByBankAccountID gets the cleared, but not yet reconciled,
+// transactions in the bank account dated on or before to
+func (t *Transactions) GetAllClearedByBankAccountID(ctx context.Context, bankAccountID string, to time.Time) ([]*models.Transaction, error) {
+	rows, err := t.DB.Query(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			fitid,
+			transfer_id,
+			payee_id
+		FROM transactions
+		WHERE
+			bank_account_id = $1 AND
+			cleared = 'cleared' AND
+			date <= $2
+		ORDER BY date, id`, bankAccountID, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*models.Transaction{}
+	for rows.Next() {
+		transaction := &models.Transaction{}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.BankAccountID,
+			&transaction.CategoryID,
+			&transaction.Date,
+			&transaction.Amount,
+			&transaction.Payee,
+			&transaction.Memo,
+			&transaction.Cleared,
+			&transaction.FITID,
+			&transaction.TransferID,
+			&transaction.PayeeID)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetClearedBalanceByBankAccountID sums the amounts of all cleared and
+// reconciled transactions in the bank account dated on or before to
+func (t *Transactions) GetClearedBalanceByBankAccountID(ctx context.Context, bankAccountID string, to time.Time) (int64, error) {
+	var total int64
+	err := t.DB.QueryRow(ctx, `
+		SELECT coalesce(sum(amount), 0)::BIGINT
+		FROM transactions
+		WHERE
+			bank_account_id = $1 AND
+			cleared IN ('cleared', 'reconciled') AND
+			date <= $2`, bankAccountID, to).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ReconcileByBankAccountID marks every cleared transaction in the bank account
+// dated on or before to as reconciled
+func (t *Transactions) ReconcileByBankAccountID(ctx context.Context, bankAccountID string, to time.Time) error {
+	_, err := t.DB.Exec(ctx, `
+		UPDATE transactions
+		SET cleared = 'reconciled'
+		WHERE
+			bank_account_id = $1 AND
+			cleared = 'cleared' AND
+			date <= $2`, bankAccountID, to)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *Transactions) ExistsByTransferID(ctx context.Context, transferID string) (bool, error) {
+	var count int
+	err := t.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM transactions
+		WHERE transfer_id = $1`, transferID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	// a lone leg, whose counterpart has gone, is no longer a transfer
+	return count == 2, nil
+}
+
+// GetAllByTransferID gets both sides of a transfer, outflow first
+func (t *Transactions) GetAllByTransferID(ctx context.Context, transferID string) ([]*models.Transaction, error) {
+	rows, err := t.DB.Query(ctx, `
+		SELECT
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			fitid,
+			transfer_id,
+			payee_id
+		FROM transactions
+		WHERE transfer_id = $1
+		ORDER BY amount`, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*models.Transaction{}
+	for rows.Next() {
+		transaction := &models.Transaction{}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.BankAccountID,
+			&transaction.CategoryID,
+			&transaction.Date,
+			&transaction.Amount,
+			&transaction.Payee,
+			&transaction.Memo,
+			&transaction.Cleared,
+			&transaction.FITID,
+			&transaction.TransferID,
+			&transaction.PayeeID)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// CreateTransfer inserts both sides of a transfer in a single statement, so
+// that neither can exist without the other
+func (t *Transactions) CreateTransfer(ctx context.Context, outflow, inflow *models.Transaction) error {
+	tag, err := t.DB.Exec(ctx, `
+		INSERT INTO transactions (
+			id,
+			bank_account_id,
+			category_id,
+			date,
+			amount,
+			payee,
+			memo,
+			cleared,
+			transfer_id
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		), (
+			$10, $11, $12, $13, $14, $15, $16, $17, $18
+		)`,
+		outflow.ID,
+		outflow.BankAccountID,
+		outflow.CategoryID,
+		outflow.Date,
+		outflow.Amount,
+		outflow.Payee,
+		outflow.Memo,
+		outflow.Cleared,
+		outflow.TransferID,
+		inflow.ID,
+		inflow.BankAccountID,
+		inflow.CategoryID,
+		inflow.Date,
+		inflow.Amount,
+		inflow.Payee,
+		inflow.Memo,
+		inflow.Cleared,
+		inflow.TransferID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 2 {
+		return errors.New("failed to create transfer: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// UpdateTransfer sets the date, amount and memo of both sides of a transfer.
+// amount is positive; the outflow side is stored negated.
+func (t *Transactions) UpdateTransfer(ctx context.Context, transferID string, date time.Time, amount int64, memo *string) error {
+	tag, err := t.DB.Exec(ctx, `
+		UPDATE transactions
+		SET
+			date = $2,
+			amount = CASE WHEN amount < 0 THEN -$3::BIGINT ELSE $3::BIGINT END,
+			memo = $4
+		WHERE transfer_id = $1`,
+		transferID,
+		date,
+		amount,
+		memo)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 2 {
+		return errors.New("failed to update transfer: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// UpdateTransferLeg updates one side of a transfer and, in the same statement,
+// mirrors its date, amount and memo onto the other side
+func (t *Transactions) UpdateTransferLeg(ctx context.Context, transaction *models.Transaction) error {
+	tag, err := t.DB.Exec(ctx, `
+		WITH counterpart AS (
+			UPDATE transactions
+			SET
+				date = $3,
+				amount = -$4::BIGINT,
+				memo = $6
+			WHERE transfer_id = $8 AND id <> $1
+		)
+		UPDATE transactions
+		SET
+			category_id = $2,
+			date = $3,
+			amount = $4,
+			payee = $5,
+			memo = $6,
+			cleared = $7
+		WHERE id = $1 AND transfer_id = $8`,
+		transaction.ID,
+		transaction.CategoryID,
+		transaction.Date,
+		transaction.Amount,
+		transaction.Payee,
+		transaction.Memo,
+		transaction.Cleared,
+		transaction.TransferID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return errors.New("failed to update transfer transaction: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// DeleteByTransferID deletes both sides of a transfer, or the one side left
+// if the other has gone
+func (t *Transactions) DeleteByTransferID(ctx context.Context, transferID string) error {
+	tag, err := t.DB.Exec(ctx, `
+		DELETE FROM transactions
+		WHERE transfer_id = $1`, transferID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 && tag.RowsAffected() != 2 {
+		return errors.New("failed to delete transfer: unexpected number of rows affected")
+	}
+
+	return nil
+}
+
+// UnlinkTransfersByBankAccountID turns the other side of each of the bank
+// account's transfers into an ordinary transaction, so that none are left
+// pointing at a transfer that is missing a side once the bank account is
+// deleted
+func (t *Transactions) UnlinkTransfersByBankAccountID(ctx context.Context, bankAccountID string) error {
+	_, err := t.DB.Exec(ctx, `
+		UPDATE transactions
+		SET transfer_id = NULL
+		WHERE bank_account_id <> $1
+			AND transfer_id IN (
+				SELECT transfer_id
+				FROM transactions
+				WHERE bank_account_id = $1 AND transfer_id IS NOT NULL
+			)`, bankAccountID)
+	return err
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHandler records the statements executed against it, reporting
+// each one as affecting a fixed number of rows
+type recordingHandler struct {
+	pgx.Tx
+	rowsAffected int
+	sql          []string
+	arguments    [][]interface{}
+}
+
+func (h *recordingHandler) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	h.sql = append(h.sql, sql)
+	h.arguments = append(h.arguments, arguments)
+	return pgconn.CommandTag("UPDATE " + strconv.Itoa(h.rowsAffected)), nil
+}
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// placeholders returns the distinct placeholder numbers used in sql, in order
+func placeholders(sql string) []int {
+	seen := map[int]bool{}
+	numbers := []int{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(sql, -1) {
+		number, _ := strconv.Atoi(match[1])
+		if !seen[number] {
+			seen[number] = true
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+func TestTransactionsUpdateTransferLeg(t *testing.T) {
+	categoryID := "category-id"
+	memo := "moving money"
+	transferID := "transfer-id"
+	// the checking side of a transfer into savings
+	transaction := &models.Transaction{
+		ID:            "checking-leg-id",
+		BankAccountID: "checking-id",
+		CategoryID:    &categoryID,
+		Date:          time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
+		Amount:        -2500,
+		Payee:         "Transfer: Savings",
+		Memo:          &memo,
+		Cleared:       models.ClearedStateCleared,
+		TransferID:    &transferID,
+	}
+
+	tests := []struct {
+		name         string
+		rowsAffected int
+		expectedErr  string
+	}{
+		{
+			name:         "updates the leg",
+			rowsAffected: 1,
+			expectedErr:  "",
+		},
+		{
+			name:         "leg not found",
+			rowsAffected: 0,
+			expectedErr:  "failed to update transfer transaction: unexpected number of rows affected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordingHandler{rowsAffected: tt.rowsAffected}
+			transactions := &Transactions{DB: handler}
+
+			err := transactions.UpdateTransferLeg(context.Background(), transaction)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+
+			if assert.Len(t, handler.sql, 1) {
+				// postgres can't infer the type of a parameter the statement
+				// never uses, so every argument must have a placeholder
+				arguments := handler.arguments[0]
+				expectedPlaceholders := []int{}
+				for i := range arguments {
+					expectedPlaceholders = append(expectedPlaceholders, i+1)
+				}
+				assert.Equal(t, expectedPlaceholders, placeholders(handler.sql[0]))
+				assert.NotContains(t, arguments, transaction.BankAccountID)
+				assert.Equal(t, transaction.ID, arguments[0])
+				assert.Equal(t, transaction.TransferID, arguments[len(arguments)-1])
+			}
+		})
+	}
+}
+
+func TestTransactionsDeleteByTransferID(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int
+		expectedErr  string
+	}{
+		{
+			name:         "deletes both sides",
+			rowsAffected: 2,
+			expectedErr:  "",
+		},
+		{
+			name:         "deletes a lone side",
+			rowsAffected: 1,
+			expectedErr:  "",
+		},
+		{
+			name:         "transfer not found",
+			rowsAffected: 0,
+			expectedErr:  "failed to delete transfer: unexpected number of rows affected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordingHandler{rowsAffected: tt.rowsAffected}
+			transactions := &Transactions{DB: handler}
+
+			err := transactions.DeleteByTransferID(context.Background(), "transfer-id")
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
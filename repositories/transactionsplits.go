@@ -0,0 +1,131 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+
+	"github.com/paulwrubel/moneybags-server/database"
+	"github.com/paulwrubel/moneybags-server/models"
+)
+
+type ITransactionSplits interface {
+	GetAllByTransactionID(ctx context.Context, transactionID string) ([]*models.TransactionSplit, error)
+	GetAllByTransactionIDs(ctx context.Context, transactionIDs []string) ([]*models.TransactionSplit, error)
+	ReplaceAllByTransactionID(ctx context.Context, transactionID string, splits []*models.TransactionSplit) error
+}
+
+type TransactionSplits struct {
+	DB database.IHandler
+}
+
+func (ts *TransactionSplits) GetAllByTransactionID(ctx context.Context, transactionID string) ([]*models.TransactionSplit, error) {
+	rows, err := ts.DB.Query(ctx, `
+		SELECT
+			id,
+			transaction_id,
+			category_id,
+			amount,
+			memo
+		FROM transaction_splits
+		WHERE transaction_id = $1
+		ORDER BY amount, id`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := []*models.TransactionSplit{}
+	for rows.Next() {
+		split := &models.TransactionSplit{}
+		err := rows.Scan(
+			&split.ID,
+			&split.TransactionID,
+			&split.CategoryID,
+			&split.Amount,
+			&split.Memo)
+		if err != nil {
+			return nil, err
+		}
+
+		splits = append(splits, split)
+	}
+
+	return splits, nil
+}
+
+// GetAllByTransactionIDs gets the splits of several transactions at once
+func (ts *TransactionSplits) GetAllByTransactionIDs(ctx context.Context, transactionIDs []string) ([]*models.TransactionSplit, error) {
+	rows, err := ts.DB.Query(ctx, `
+		SELECT
+			s.id,
+			s.transaction_id,
+			s.category_id,
+			s.amount,
+			s.memo
+		FROM transaction_splits s
+		WHERE s.transaction_id = ANY($1::UUID[])
+		ORDER BY s.amount, s.id`, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := []*models.TransactionSplit{}
+	for rows.Next() {
+		split := &models.TransactionSplit{}
+		err := rows.Scan(
+			&split.ID,
+			&split.TransactionID,
+			&split.CategoryID,
+			&split.Amount,
+			&split.Memo)
+		if err != nil {
+			return nil, err
+		}
+
+		splits = append(splits, split)
+	}
+
+	return splits, nil
+}
+
+// ReplaceAllByTransactionID replaces the splits of a transaction in a single
+// statement. An empty list of splits removes them.
+func (ts *TransactionSplits) ReplaceAllByTransactionID(ctx context.Context, transactionID string, splits []*models.TransactionSplit) error {
+	ids := []string{}
+	categoryIDs := []*string{}
+	amounts := []int64{}
+	memos := []*string{}
+	for _, split := range splits {
+		ids = append(ids, split.ID)
+		categoryIDs = append(categoryIDs, split.CategoryID)
+		amounts = append(amounts, split.Amount)
+		memos = append(memos, split.Memo)
+	}
+
+	_, err := ts.DB.Exec(ctx, `
+		WITH deleted AS (
+			DELETE FROM transaction_splits
+			WHERE transaction_id = $1
+		)
+		INSERT INTO transaction_splits (
+			id,
+			transaction_id,
+			category_id,
+			amount,
+			memo
+		)
+		SELECT s.id, $1, s.category_id, s.amount, s.memo
+		FROM unnest($2::UUID[], $3::UUID[], $4::BIGINT[], $5::TEXT[]) AS s (id, category_id, amount, memo)`,
+		transactionID,
+		ids,
+		categoryIDs,
+		amounts,
+		memos)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
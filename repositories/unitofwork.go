@@ -0,0 +1,142 @@
+package repositories
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/repositories/mock_$GOFILE -package=mockrepositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/database"
+)
+
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+type IUnitOfWork interface {
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+// Repositories are the repositories of a unit of work, whose statements all
+// run in its transaction
+type Repositories struct {
+	// UnitOfWork runs a nested unit of work in a savepoint, whose failure
+	// only rolls back its own statements
+	UnitOfWork            IUnitOfWork
+	APIKeys               IAPIKeys
+	BankAccounts          IBankAccounts
+	BudgetInvitations     IBudgetInvitations
+	BudgetMemberships     IBudgetMemberships
+	Budgets               IBudgets
+	Categories            ICategories
+	CategorizationRules   ICategorizationRules
+	CategoryAllocations   ICategoryAllocations
+	CategoryGroups        ICategoryGroups
+	ExchangeRates         IExchangeRates
+	PasswordResetTokens   IPasswordResetTokens
+	Payees                IPayees
+	RecoveryCodes         IRecoveryCodes
+	ScheduledTransactions IScheduledTransactions
+	Sessions              ISessions
+	StatementBalances     IStatementBalances
+	TOTPCredentials       ITOTPCredentials
+	Transactions          ITransactions
+	TransactionSplits     ITransactionSplits
+	UserAccounts          IUserAccounts
+}
+
+type UnitOfWork struct {
+	DB database.IHandler
+	// savepoint is set on the unit of work of a transaction's Repositories,
+	// which is nested in that transaction
+	savepoint bool
+}
+
+// Do calls fn with repositories bound to a serializable transaction, which
+// is committed if fn returns nil and rolled back otherwise. When the
+// transaction fails to serialize, or deadlocks, it is retried from the start
+// up to MaxTransactionAttempts times, so fn must not have effects outside
+// the database, and must return the errors of repository calls rather than
+// swallow them. Nested units of work are never retried on their own.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos *Repositories) error) error {
+	if u.savepoint {
+		return u.run(ctx, fn)
+	}
+
+	var err error
+	for attempt := 0; attempt < constants.MaxTransactionAttempts; attempt++ {
+		err = u.run(ctx, fn)
+		if !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (u *UnitOfWork) run(ctx context.Context, fn func(repos *Repositories) error) error {
+	tx, err := u.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	// rolling back a committed transaction does nothing, so this undoes the
+	// transaction on every path which does not reach Commit, panics included
+	defer tx.Rollback(ctx)
+
+	if !u.savepoint {
+		_, err = tx.Exec(ctx, `SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`)
+		if err != nil {
+			return fmt.Errorf("error setting transaction isolation level: %w", err)
+		}
+	}
+
+	err = fn(newRepositories(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+func newRepositories(db database.IHandler) *Repositories {
+	return &Repositories{
+		UnitOfWork:            &UnitOfWork{DB: db, savepoint: true},
+		APIKeys:               &APIKeys{DB: db},
+		BankAccounts:          &BankAccounts{DB: db},
+		BudgetInvitations:     &BudgetInvitations{DB: db},
+		BudgetMemberships:     &BudgetMemberships{DB: db},
+		Budgets:               &Budgets{DB: db},
+		Categories:            &Categories{DB: db},
+		CategorizationRules:   &CategorizationRules{DB: db},
+		CategoryAllocations:   &CategoryAllocations{DB: db},
+		CategoryGroups:        &CategoryGroups{DB: db},
+		ExchangeRates:         &ExchangeRates{DB: db},
+		PasswordResetTokens:   &PasswordResetTokens{DB: db},
+		Payees:                &Payees{DB: db},
+		RecoveryCodes:         &RecoveryCodes{DB: db},
+		ScheduledTransactions: &ScheduledTransactions{DB: db},
+		Sessions:              &Sessions{DB: db},
+		StatementBalances:     &StatementBalances{DB: db},
+		TOTPCredentials:       &TOTPCredentials{DB: db},
+		Transactions:          &Transactions{DB: db},
+		TransactionSplits:     &TransactionSplits{DB: db},
+		UserAccounts:          &UserAccounts{DB: db},
+	}
+}
+
+// isRetryable reports whether err is a serialization failure or deadlock,
+// after which the whole transaction may succeed if tried again
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailureCode || pgErr.Code == deadlockDetectedCode
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHandler hands out fakeTxs, counting what happens to them
+type fakeHandler struct {
+	pgx.Tx
+	begun     int
+	committed int
+}
+
+func (h *fakeHandler) Begin(ctx context.Context) (pgx.Tx, error) {
+	h.begun++
+	return &fakeTx{handler: h}, nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+	handler *fakeHandler
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return nil, nil
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return tx.handler.Begin(ctx)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.handler.committed++
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func TestUnitOfWorkDo(t *testing.T) {
+	serializationFailure := &pgconn.PgError{Code: serializationFailureCode}
+	otherFailure := errors.New("something went wrong")
+
+	tests := []struct {
+		name              string
+		fnErrors          []error
+		expectedErr       error
+		expectedBegun     int
+		expectedCommitted int
+	}{
+		{
+			name:              "commits on success",
+			fnErrors:          []error{nil},
+			expectedErr:       nil,
+			expectedBegun:     1,
+			expectedCommitted: 1,
+		},
+		{
+			name:              "rolls back on error",
+			fnErrors:          []error{otherFailure},
+			expectedErr:       otherFailure,
+			expectedBegun:     1,
+			expectedCommitted: 0,
+		},
+		{
+			name:              "retries serialization failures",
+			fnErrors:          []error{serializationFailure, nil},
+			expectedErr:       nil,
+			expectedBegun:     2,
+			expectedCommitted: 1,
+		},
+		{
+			name:              "gives up after too many attempts",
+			fnErrors:          []error{serializationFailure, serializationFailure, serializationFailure},
+			expectedErr:       serializationFailure,
+			expectedBegun:     constants.MaxTransactionAttempts,
+			expectedCommitted: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &fakeHandler{}
+			unitOfWork := &UnitOfWork{DB: handler}
+
+			calls := 0
+			err := unitOfWork.Do(context.Background(), func(repos *Repositories) error {
+				err := tt.fnErrors[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedBegun, handler.begun)
+			assert.Equal(t, tt.expectedCommitted, handler.committed)
+		})
+	}
+}
+
+func TestUnitOfWorkDoDoesNotRetrySavepoints(t *testing.T) {
+	serializationFailure := &pgconn.PgError{Code: serializationFailureCode}
+	handler := &fakeHandler{}
+	unitOfWork := &UnitOfWork{DB: handler}
+
+	nestedCalls := 0
+	err := unitOfWork.Do(context.Background(), func(repos *Repositories) error {
+		return repos.UnitOfWork.Do(context.Background(), func(repos *Repositories) error {
+			nestedCalls++
+			return serializationFailure
+		})
+	})
+	assert.Equal(t, serializationFailure, err)
+	// the outer transaction is retried, and each attempt runs the savepoint
+	// once
+	assert.Equal(t, constants.MaxTransactionAttempts, nestedCalls)
+	assert.Equal(t, 0, handler.committed)
+}
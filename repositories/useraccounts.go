@@ -11,22 +11,37 @@ import (
 )
 
 type IUserAccounts interface {
-	ExistsByID(id string) (bool, error)
-	ExistsByUsername(username string) (bool, error)
-	GetByID(id string) (*models.UserAccount, error)
-	GetByUsername(username string) (*models.UserAccount, error)
-	Create(userAccount *models.UserAccount) error
-	DeleteByID(id string) error
-	Update(userAccount *models.UserAccount) error
+	Count(ctx context.Context) (int, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.UserAccount, error)
+	GetByUsername(ctx context.Context, username string) (*models.UserAccount, error)
+	GetByEmail(ctx context.Context, email string) (*models.UserAccount, error)
+	Create(ctx context.Context, userAccount *models.UserAccount) error
+	DeleteByID(ctx context.Context, id string) error
+	Update(ctx context.Context, userAccount *models.UserAccount) error
 }
 
 type UserAccounts struct {
 	DB database.IHandler
 }
 
-func (ua *UserAccounts) ExistsByID(id string) (bool, error) {
+func (ua *UserAccounts) Count(ctx context.Context) (int, error) {
 	var count int
-	err := ua.DB.QueryRow(context.Background(), `
+	err := ua.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM user_accounts`).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (ua *UserAccounts) ExistsByID(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := ua.DB.QueryRow(ctx, `
 		SELECT count(*) 
 		FROM user_accounts 
 		WHERE id = $1`, id).Scan(&count)
@@ -37,9 +52,9 @@ func (ua *UserAccounts) ExistsByID(id string) (bool, error) {
 	return count == 1, nil
 }
 
-func (ua *UserAccounts) ExistsByUsername(username string) (bool, error) {
+func (ua *UserAccounts) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	var count int
-	err := ua.DB.QueryRow(context.Background(), `
+	err := ua.DB.QueryRow(ctx, `
 		SELECT count(*) 
 		FROM user_accounts 
 		WHERE username = $1`, username).Scan(&count)
@@ -50,9 +65,22 @@ func (ua *UserAccounts) ExistsByUsername(username string) (bool, error) {
 	return count == 1, nil
 }
 
-func (ua *UserAccounts) GetByID(id string) (*models.UserAccount, error) {
+func (ua *UserAccounts) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	var count int
+	err := ua.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM user_accounts
+		WHERE email = $1`, email).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+func (ua *UserAccounts) GetByID(ctx context.Context, id string) (*models.UserAccount, error) {
 	userAccount := &models.UserAccount{}
-	err := ua.DB.QueryRow(context.Background(), `
+	err := ua.DB.QueryRow(ctx, `
 		SELECT 
 			id, 
 			username, 
@@ -71,9 +99,9 @@ func (ua *UserAccounts) GetByID(id string) (*models.UserAccount, error) {
 	return userAccount, nil
 }
 
-func (ua *UserAccounts) GetByUsername(username string) (*models.UserAccount, error) {
+func (ua *UserAccounts) GetByUsername(ctx context.Context, username string) (*models.UserAccount, error) {
 	userAccount := &models.UserAccount{}
-	err := ua.DB.QueryRow(context.Background(), `
+	err := ua.DB.QueryRow(ctx, `
 		SELECT 
 			id, 
 			username, 
@@ -92,8 +120,29 @@ func (ua *UserAccounts) GetByUsername(username string) (*models.UserAccount, err
 	return userAccount, nil
 }
 
-func (ua *UserAccounts) Create(userAccount *models.UserAccount) error {
-	tag, err := ua.DB.Exec(context.Background(), `
+func (ua *UserAccounts) GetByEmail(ctx context.Context, email string) (*models.UserAccount, error) {
+	userAccount := &models.UserAccount{}
+	err := ua.DB.QueryRow(ctx, `
+		SELECT
+			id,
+			username,
+			password_hash,
+			email
+		FROM user_accounts
+		WHERE email = $1`, email).Scan(
+		&userAccount.ID,
+		&userAccount.Username,
+		&userAccount.PasswordHash,
+		&userAccount.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return userAccount, nil
+}
+
+func (ua *UserAccounts) Create(ctx context.Context, userAccount *models.UserAccount) error {
+	tag, err := ua.DB.Exec(ctx, `
 		INSERT INTO user_accounts (
 			id, 
 			username, 
@@ -116,8 +165,8 @@ func (ua *UserAccounts) Create(userAccount *models.UserAccount) error {
 	return nil
 }
 
-func (ua *UserAccounts) DeleteByID(id string) error {
-	tag, err := ua.DB.Exec(context.Background(), `
+func (ua *UserAccounts) DeleteByID(ctx context.Context, id string) error {
+	tag, err := ua.DB.Exec(ctx, `
 		DELETE FROM user_accounts
 		WHERE id = $1`, id)
 	if err != nil {
@@ -130,8 +179,8 @@ func (ua *UserAccounts) DeleteByID(id string) error {
 	return nil
 }
 
-func (ua *UserAccounts) Update(userAccount *models.UserAccount) error {
-	tag, err := ua.DB.Exec(context.Background(), `
+func (ua *UserAccounts) Update(ctx context.Context, userAccount *models.UserAccount) error {
+	tag, err := ua.DB.Exec(ctx, `
 		UPDATE user_accounts
 		SET 
 			username = $2, 
@@ -7,18 +7,34 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/paulwrubel/moneybags-server/injection"
 	"github.com/paulwrubel/moneybags-server/middleware"
-	log "github.com/sirupsen/logrus"
 )
 
-func RunServer(injector injection.IInjector) {
-	router := getRouter(injector)
+// NewServer returns the API server, which is started with ListenAndServe
+// and stopped, letting in-flight requests finish, with Shutdown
+func NewServer(injector injection.IInjector) *http.Server {
+	return &http.Server{
+		Addr:    ":8080",
+		Handler: getRouter(injector),
+	}
+}
 
-	go func() {
-		err := http.ListenAndServe(":8080", router)
-		if err != nil {
-			log.WithError(err).Fatalln("error in RunServer()")
-		}
-	}()
+// NewMetricsServer returns the server for /metrics. It listens apart from
+// the API server, since the metrics include business totals which shouldn't
+// be scraped by anyone who can reach the API
+func NewMetricsServer(injector injection.IInjector) *http.Server {
+	return &http.Server{
+		Addr:    injector.InjectMetricsAddress(),
+		Handler: getMetricsRouter(injector),
+	}
+}
+
+func getMetricsRouter(injector injection.IInjector) *mux.Router {
+	router := mux.NewRouter()
+
+	metricsController := injector.InjectMetricsController()
+	router.HandleFunc("/metrics", metricsController.Get()).Methods(http.MethodGet)
+
+	return router
 }
 
 func getRouter(injector injection.IInjector) *mux.Router {
@@ -27,30 +43,65 @@ func getRouter(injector injection.IInjector) *mux.Router {
 	authService := injector.InjectAuthService()
 
 	router.Use(middleware.Logrus())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Timeout(injector.InjectRequestTimeout()))
 	router.Use(handlers.CORS(
 		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowedMethods([]string{http.MethodGet, http.MethodPost}),
+		handlers.AllowedMethods([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}),
 		handlers.AllowedOrigins([]string{"*"}),
 	))
 	auth := middleware.SessionValidation(authService)
 
 	// healthcheck routes
 	healthController := injector.InjectHealthController()
-	router.HandleFunc("/ping", healthController.Get()).Methods(http.MethodGet)
-	router.HandleFunc("/health", healthController.Get()).Methods(http.MethodGet)
+	router.HandleFunc("/ping", healthController.Ping()).Methods(http.MethodGet)
+	// /health predates the split into liveness and readiness, and stays a
+	// liveness check so that existing probes keep passing while starting up
+	router.HandleFunc("/health", healthController.GetLiveness()).Methods(http.MethodGet)
+	router.HandleFunc("/health/live", healthController.GetLiveness()).Methods(http.MethodGet)
+	router.HandleFunc("/health/ready", healthController.GetReadiness()).Methods(http.MethodGet)
 
 	apiSubrouter := router.PathPrefix("/api/v1").Subrouter()
 
+	// documentation routes
+	openAPIController := injector.InjectOpenAPIController()
+	apiSubrouter.HandleFunc("/openapi.json", openAPIController.Get()).Methods(http.MethodGet)
+
 	// user account routes
 	userAccountsController := injector.InjectUserAccountsController()
 	userAccountsSubrouter := apiSubrouter.PathPrefix("/user-accounts").Subrouter()
 	userAccountsSubrouter.HandleFunc("", userAccountsController.Post()).Methods(http.MethodPost)
 	userAccountsSubrouter.Handle("", auth(userAccountsController.Get())).Methods(http.MethodGet)
+	userAccountsSubrouter.Handle("/password", auth(userAccountsController.PutPassword())).Methods(http.MethodPut)
+	userAccountsSubrouter.HandleFunc("/password-reset", userAccountsController.PostPasswordReset()).Methods(http.MethodPost)
+	userAccountsSubrouter.HandleFunc("/password-reset/confirm", userAccountsController.PostPasswordResetConfirm()).Methods(http.MethodPost)
+
+	// two-factor authentication routes
+	totpController := injector.InjectTOTPController()
+	totpSubrouter := apiSubrouter.PathPrefix("/user-accounts/2fa/totp").Subrouter()
+	totpSubrouter.Use(auth)
+	totpSubrouter.HandleFunc("", totpController.Get()).Methods(http.MethodGet)
+	totpSubrouter.HandleFunc("", totpController.PostEnrollment()).Methods(http.MethodPost)
+	totpSubrouter.HandleFunc("", totpController.Delete()).Methods(http.MethodDelete)
+	totpSubrouter.HandleFunc("/verify", totpController.PostVerification()).Methods(http.MethodPost)
+	totpSubrouter.HandleFunc("/recovery-codes", totpController.PostRecoveryCodes()).Methods(http.MethodPost)
+
+	// api key routes
+	apiKeysController := injector.InjectAPIKeysController()
+	apiKeysSubrouter := apiSubrouter.PathPrefix("/user-accounts/api-keys").Subrouter()
+	apiKeysSubrouter.Use(auth)
+	apiKeysSubrouter.HandleFunc("", apiKeysController.GetAll()).Methods(http.MethodGet)
+	apiKeysSubrouter.HandleFunc("", apiKeysController.Post()).Methods(http.MethodPost)
+	apiKeysSubrouter.HandleFunc("/{apiKeyID}", apiKeysController.Delete()).Methods(http.MethodDelete)
 
 	// auth routes
 	authController := injector.InjectAuthController(authService)
 	authSubrouter := apiSubrouter.PathPrefix("/auth").Subrouter()
 	authSubrouter.HandleFunc("/token", authController.PostToken()).Methods(http.MethodPost)
+	authSubrouter.HandleFunc("/token/challenge", authController.PostTokenChallenge()).Methods(http.MethodPost)
+	authSubrouter.HandleFunc("/refresh", authController.PostRefresh()).Methods(http.MethodPost)
+	authSubrouter.Handle("/logout", auth(authController.PostLogout())).Methods(http.MethodPost)
+	authSubrouter.Handle("/logout-all", auth(authController.PostLogoutAll())).Methods(http.MethodPost)
 
 	// budget routes
 	budgetsController := injector.InjectBudgetsController()
@@ -59,12 +110,125 @@ func getRouter(injector injection.IInjector) *mux.Router {
 	budgetsSubrouter.HandleFunc("", budgetsController.GetAll()).Methods(http.MethodGet)
 	budgetsSubrouter.HandleFunc("/{budgetID}", budgetsController.Get()).Methods(http.MethodGet)
 	budgetsSubrouter.HandleFunc("", budgetsController.Post()).Methods(http.MethodPost)
+	budgetsSubrouter.HandleFunc("/{budgetID}", budgetsController.Patch()).Methods(http.MethodPatch)
+	budgetsSubrouter.HandleFunc("/{budgetID}", budgetsController.Delete()).Methods(http.MethodDelete)
+
+	// budget membership routes
+	budgetMembershipsController := injector.InjectBudgetMembershipsController()
+	budgetMembershipsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/members").Subrouter()
+	budgetMembershipsSubrouter.Use(auth)
+	budgetMembershipsSubrouter.HandleFunc("", budgetMembershipsController.GetAll()).Methods(http.MethodGet)
+	budgetMembershipsSubrouter.HandleFunc("/{userAccountID}", budgetMembershipsController.Patch()).Methods(http.MethodPatch)
+	budgetMembershipsSubrouter.HandleFunc("/{userAccountID}", budgetMembershipsController.Delete()).Methods(http.MethodDelete)
+
+	// budget invitation routes
+	budgetInvitationsController := injector.InjectBudgetInvitationsController()
+	budgetInvitationsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/invitations").Subrouter()
+	budgetInvitationsSubrouter.Use(auth)
+	budgetInvitationsSubrouter.HandleFunc("", budgetInvitationsController.GetAll()).Methods(http.MethodGet)
+	budgetInvitationsSubrouter.HandleFunc("", budgetInvitationsController.Post()).Methods(http.MethodPost)
+	budgetInvitationsSubrouter.HandleFunc("/{invitationID}", budgetInvitationsController.Delete()).Methods(http.MethodDelete)
+	receivedInvitationsSubrouter := apiSubrouter.PathPrefix("/invitations").Subrouter()
+	receivedInvitationsSubrouter.Use(auth)
+	receivedInvitationsSubrouter.HandleFunc("", budgetInvitationsController.GetAllReceived()).Methods(http.MethodGet)
+	receivedInvitationsSubrouter.HandleFunc("/{invitationID}/accept", budgetInvitationsController.PostAcceptance()).Methods(http.MethodPost)
+	receivedInvitationsSubrouter.HandleFunc("/{invitationID}", budgetInvitationsController.DeleteReceived()).Methods(http.MethodDelete)
 
 	// bank account routes
 	bankAccountsController := injector.InjectBankAccountsController()
 	bankAccountsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/bank-accounts").Subrouter()
 	bankAccountsSubrouter.Use(auth)
 	bankAccountsSubrouter.HandleFunc("", bankAccountsController.GetAll()).Methods(http.MethodGet)
+	bankAccountsSubrouter.HandleFunc("/{bankAccountID}", bankAccountsController.Get()).Methods(http.MethodGet)
+	bankAccountsSubrouter.HandleFunc("/{bankAccountID}/balance", bankAccountsController.GetBalance()).Methods(http.MethodGet)
+	bankAccountsSubrouter.HandleFunc("", bankAccountsController.Post()).Methods(http.MethodPost)
+	bankAccountsSubrouter.HandleFunc("/{bankAccountID}", bankAccountsController.Patch()).Methods(http.MethodPatch)
+	bankAccountsSubrouter.HandleFunc("/{bankAccountID}", bankAccountsController.Delete()).Methods(http.MethodDelete)
+
+	// transaction routes
+	transactionsController := injector.InjectTransactionsController()
+	transactionsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/bank-accounts/{bankAccountID}/transactions").Subrouter()
+	transactionsSubrouter.Use(auth)
+	transactionsSubrouter.HandleFunc("", transactionsController.GetAll()).Methods(http.MethodGet)
+	transactionsSubrouter.HandleFunc("/{transactionID}", transactionsController.Get()).Methods(http.MethodGet)
+	transactionsSubrouter.HandleFunc("", transactionsController.Post()).Methods(http.MethodPost)
+	transactionsSubrouter.HandleFunc("/{transactionID}", transactionsController.Patch()).Methods(http.MethodPatch)
+	transactionsSubrouter.HandleFunc("/{transactionID}", transactionsController.Delete()).Methods(http.MethodDelete)
+
+	// import routes
+	importsController := injector.InjectImportsController()
+	importsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/bank-accounts/{bankAccountID}/imports").Subrouter()
+	importsSubrouter.Use(auth)
+	importsSubrouter.HandleFunc("/csv/preview", importsController.PostCSVPreview()).Methods(http.MethodPost)
+	importsSubrouter.HandleFunc("/csv", importsController.PostCSV()).Methods(http.MethodPost)
+	importsSubrouter.HandleFunc("/ofx/preview", importsController.PostOFXPreview()).Methods(http.MethodPost)
+	importsSubrouter.HandleFunc("/ofx", importsController.PostOFX()).Methods(http.MethodPost)
+	importsSubrouter.HandleFunc("/qif/preview", importsController.PostQIFPreview()).Methods(http.MethodPost)
+	importsSubrouter.HandleFunc("/qif", importsController.PostQIF()).Methods(http.MethodPost)
+
+	// scheduled transaction routes
+	scheduledTransactionsController := injector.InjectScheduledTransactionsController()
+	scheduledTransactionsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/bank-accounts/{bankAccountID}/scheduled-transactions").Subrouter()
+	scheduledTransactionsSubrouter.Use(auth)
+	scheduledTransactionsSubrouter.HandleFunc("", scheduledTransactionsController.GetAll()).Methods(http.MethodGet)
+	scheduledTransactionsSubrouter.HandleFunc("/upcoming", scheduledTransactionsController.GetUpcoming()).Methods(http.MethodGet)
+	scheduledTransactionsSubrouter.HandleFunc("/{scheduledTransactionID}", scheduledTransactionsController.Get()).Methods(http.MethodGet)
+	scheduledTransactionsSubrouter.HandleFunc("", scheduledTransactionsController.Post()).Methods(http.MethodPost)
+	scheduledTransactionsSubrouter.HandleFunc("/{scheduledTransactionID}", scheduledTransactionsController.Patch()).Methods(http.MethodPatch)
+	scheduledTransactionsSubrouter.HandleFunc("/{scheduledTransactionID}", scheduledTransactionsController.Delete()).Methods(http.MethodDelete)
+
+	// transfer routes
+	transfersController := injector.InjectTransfersController()
+	transfersSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/transfers").Subrouter()
+	transfersSubrouter.Use(auth)
+	transfersSubrouter.HandleFunc("/{transferID}", transfersController.Get()).Methods(http.MethodGet)
+	transfersSubrouter.HandleFunc("", transfersController.Post()).Methods(http.MethodPost)
+	transfersSubrouter.HandleFunc("/{transferID}", transfersController.Patch()).Methods(http.MethodPatch)
+	transfersSubrouter.HandleFunc("/{transferID}", transfersController.Delete()).Methods(http.MethodDelete)
+
+	// reconciliation routes
+	reconciliationsController := injector.InjectReconciliationsController()
+	reconciliationsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/bank-accounts/{bankAccountID}/reconciliation").Subrouter()
+	reconciliationsSubrouter.Use(auth)
+	reconciliationsSubrouter.HandleFunc("/preview", reconciliationsController.PostPreview()).Methods(http.MethodPost)
+	reconciliationsSubrouter.HandleFunc("", reconciliationsController.Post()).Methods(http.MethodPost)
+
+	// category routes
+	categoriesController := injector.InjectCategoriesController()
+	categoriesSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/categories").Subrouter()
+	categoriesSubrouter.Use(auth)
+	categoriesSubrouter.HandleFunc("", categoriesController.GetAll()).Methods(http.MethodGet)
+	categoriesSubrouter.HandleFunc("", categoriesController.Post()).Methods(http.MethodPost)
+	categoriesSubrouter.HandleFunc("/groups", categoriesController.PostGroup()).Methods(http.MethodPost)
+	categoriesSubrouter.HandleFunc("/{categoryID}", categoriesController.Patch()).Methods(http.MethodPatch)
+	categoriesSubrouter.HandleFunc("/{categoryID}", categoriesController.Delete()).Methods(http.MethodDelete)
+
+	// payee routes
+	payeesController := injector.InjectPayeesController()
+	payeesSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/payees").Subrouter()
+	payeesSubrouter.Use(auth)
+	payeesSubrouter.HandleFunc("", payeesController.GetAll()).Methods(http.MethodGet)
+	payeesSubrouter.HandleFunc("/{payeeID}", payeesController.Get()).Methods(http.MethodGet)
+	payeesSubrouter.HandleFunc("", payeesController.Post()).Methods(http.MethodPost)
+	payeesSubrouter.HandleFunc("/{payeeID}", payeesController.Patch()).Methods(http.MethodPatch)
+	payeesSubrouter.HandleFunc("/{payeeID}", payeesController.Delete()).Methods(http.MethodDelete)
+	payeesSubrouter.HandleFunc("/{payeeID}/merge", payeesController.PostMerge()).Methods(http.MethodPost)
+
+	// categorization rule routes
+	categorizationRulesController := injector.InjectCategorizationRulesController()
+	categorizationRulesSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/categorization-rules").Subrouter()
+	categorizationRulesSubrouter.Use(auth)
+	categorizationRulesSubrouter.HandleFunc("", categorizationRulesController.GetAll()).Methods(http.MethodGet)
+	categorizationRulesSubrouter.HandleFunc("", categorizationRulesController.Post()).Methods(http.MethodPost)
+	categorizationRulesSubrouter.HandleFunc("/{ruleID}", categorizationRulesController.Put()).Methods(http.MethodPut)
+	categorizationRulesSubrouter.HandleFunc("/{ruleID}", categorizationRulesController.Delete()).Methods(http.MethodDelete)
+
+	// month routes
+	monthsController := injector.InjectMonthsController()
+	monthsSubrouter := apiSubrouter.PathPrefix("/budgets/{budgetID}/months/{month}").Subrouter()
+	monthsSubrouter.Use(auth)
+	monthsSubrouter.HandleFunc("", monthsController.Get()).Methods(http.MethodGet)
+	monthsSubrouter.HandleFunc("/categories/{categoryID}", monthsController.PutCategory()).Methods(http.MethodPut)
 
 	return router
 }
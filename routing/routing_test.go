@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/paulwrubel/moneybags-server/config"
+	"github.com/paulwrubel/moneybags-server/injection"
+	"github.com/paulwrubel/moneybags-server/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutesAreDocumented(t *testing.T) {
+	router := getRouter(&injection.Injector{
+		AppInfo: &config.AppInfo{
+			AuthInfo: &config.AuthInfo{},
+		},
+	})
+
+	routed := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// subrouters match a path prefix, and have no methods of their own
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			routed[method+" "+path] = true
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	document := struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	err = json.Unmarshal(openapi.Document, &document)
+	assert.NoError(t, err)
+
+	documented := map[string]bool{}
+	for path, operations := range document.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for operation := range routed {
+		assert.True(t, documented[operation], "%s is routed but not documented", operation)
+	}
+	for operation := range documented {
+		assert.True(t, routed[operation], "%s is documented but not routed", operation)
+	}
+	assert.True(t, routed[http.MethodGet+" /api/v1/openapi.json"])
+}
+
+func TestMetricsAreNotPublic(t *testing.T) {
+	injector := &injection.Injector{
+		AppInfo: &config.AppInfo{
+			AuthInfo: &config.AuthInfo{},
+		},
+	}
+
+	r, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	assert.NoError(t, err)
+
+	var match mux.RouteMatch
+	assert.False(t, getRouter(injector).Match(r, &match), "/metrics is served by the API server")
+	assert.True(t, getMetricsRouter(injector).Match(r, &match), "/metrics is not served by the metrics server")
+}
@@ -0,0 +1,53 @@
+// Package scheduler runs the background job which turns due scheduled
+// transactions into real transactions
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/services"
+	log "github.com/sirupsen/logrus"
+)
+
+type Worker struct {
+	SScheduledTransactions services.IScheduledTransactions
+	Interval               time.Duration
+	// Now returns the current time, and defaults to time.Now
+	Now func() time.Time
+}
+
+// Run materializes due scheduled transactions straight away, catching up on
+// anything missed while the server was down, then again every Interval
+// until ctx is done
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce materializes every scheduled transaction due as of today (UTC)
+func (w *Worker) RunOnce(ctx context.Context) {
+	now := time.Now
+	if w.Now != nil {
+		now = w.Now
+	}
+	today := now().UTC().Truncate(24 * time.Hour)
+
+	created, err := w.SScheduledTransactions.MaterializeDue(ctx, today)
+	if err != nil {
+		log.WithError(err).Error("error materializing scheduled transactions")
+	}
+	if created > 0 {
+		log.WithField("count", created).Info("materialized scheduled transactions")
+	}
+}
@@ -0,0 +1,33 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/scheduler"
+)
+
+func TestWorkerRunCatchesUpImmediately(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockScheduledTransactionsService := mockservices.NewMockIScheduledTransactions(mockCtrl)
+
+	mockScheduledTransactionsService.EXPECT().
+		MaterializeDue(gomock.Any(), gomock.Eq(time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC))).
+		Times(1).
+		Return(3, nil)
+
+	w := &scheduler.Worker{
+		SScheduledTransactions: mockScheduledTransactionsService,
+		Interval:               time.Hour,
+		Now: func() time.Time {
+			return time.Date(2022, time.March, 2, 17, 30, 0, 0, time.FixedZone("", -5*60*60))
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.Run(ctx)
+}
@@ -0,0 +1,112 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IAPIKeys interface {
+	BelongsTo(ctx context.Context, userAccountID, apiKeyID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetAll(ctx context.Context, userAccountID string) ([]*models.APIKey, error)
+	GetByID(ctx context.Context, id string) (*models.APIKey, error)
+	Create(ctx context.Context, userAccountID, name string, readOnly bool, budgetID *string, expiresAt *time.Time) (*models.APIKey, string, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+type APIKeys struct {
+	RAPIKeys repositories.IAPIKeys
+}
+
+func (ak *APIKeys) BelongsTo(ctx context.Context, userAccountID, apiKeyID string) (bool, error) {
+	apiKey, err := ak.RAPIKeys.GetByID(ctx, apiKeyID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get api key by id: %v", err)
+	}
+	return userAccountID == apiKey.UserAccountID, nil
+}
+
+func (ak *APIKeys) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return ak.RAPIKeys.ExistsByID(ctx, id)
+}
+
+func (ak *APIKeys) GetAll(ctx context.Context, userAccountID string) ([]*models.APIKey, error) {
+	return ak.RAPIKeys.GetAllByUserAccountID(ctx, userAccountID)
+}
+
+func (ak *APIKeys) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	return ak.RAPIKeys.GetByID(ctx, id)
+}
+
+// Create issues a new API key for the user, returning it along with the key
+// itself. Only the key's hash is stored, so it cannot be shown again.
+func (ak *APIKeys) Create(ctx context.Context, userAccountID, name string, readOnly bool, budgetID *string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", constants.ErrInvalidAPIKeyName
+	}
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", constants.ErrInvalidAPIKeyExpiry
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("error generating api key: %w", err)
+	}
+	key := constants.APIKeyPrefix + token
+
+	newAPIKey := &models.APIKey{
+		ID:            uuid.NewString(),
+		UserAccountID: userAccountID,
+		Name:          name,
+		KeyHash:       hashOpaqueToken(key),
+		KeyPrefix:     key[:constants.APIKeyDisplayLength],
+		ReadOnly:      readOnly,
+		BudgetID:      budgetID,
+		CreatedAt:     now,
+		ExpiresAt:     expiresAt,
+		RevokedAt:     nil,
+	}
+	err = ak.RAPIKeys.Create(ctx, newAPIKey)
+	if err != nil {
+		return nil, "", err
+	}
+	exists, err := ak.ExistsByID(ctx, newAPIKey.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		return nil, "", errors.New("api key failed post-creation existence check")
+	}
+	createdAPIKey, err := ak.RAPIKeys.GetByID(ctx, newAPIKey.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return createdAPIKey, key, nil
+}
+
+// Revoke stops the API key from being accepted. Revoking a key twice is not
+// an error.
+func (ak *APIKeys) Revoke(ctx context.Context, id string) error {
+	apiKey, err := ak.RAPIKeys.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting api key: %w", err)
+	}
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	return ak.RAPIKeys.Update(ctx, apiKey)
+}
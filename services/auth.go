@@ -3,20 +3,33 @@ package services
 //go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
 	"github.com/paulwrubel/moneybags-server/repositories"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type IAuth interface {
-	ValidateSession(tokenString string) (*jwt.Token, error)
-	Authenticate(username, password string) (bool, error)
-	CreateAuthToken(username string) (string, error)
+	ValidateSession(ctx context.Context, tokenString string) (*jwt.Token, error)
+	ValidateAPIKey(ctx context.Context, key string) (string, *models.APIKey, error)
+	Authenticate(ctx context.Context, username, password string) (bool, error)
+	CreateSession(ctx context.Context, username string) (string, string, error)
+	CreateChallengeToken(ctx context.Context, username string) (string, error)
+	ValidateChallengeToken(ctx context.Context, challengeToken string) (string, error)
+	RefreshSession(ctx context.Context, refreshToken string) (string, string, error)
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeAllSessions(ctx context.Context, username string) error
 }
 
 type Auth struct {
@@ -24,9 +37,13 @@ type Auth struct {
 	SigningMethod jwt.SigningMethod
 	PrivateKey    *rsa.PrivateKey
 	UserAccounts  repositories.IUserAccounts
+	Sessions      repositories.ISessions
+	APIKeys       repositories.IAPIKeys
 }
 
-func (a *Auth) ValidateSession(tokenString string) (*jwt.Token, error) {
+// ValidateSession parses and verifies the given access token, and then checks
+// that the session named by its "jti" claim has not been revoked
+func (a *Auth) ValidateSession(ctx context.Context, tokenString string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 		switch t.Method.Alg() {
 		case a.SigningMethod.Alg():
@@ -38,11 +55,58 @@ func (a *Auth) ValidateSession(tokenString string) (*jwt.Token, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	sessionID, ok := token.Claims.(jwt.MapClaims)["jti"].(string)
+	if !ok {
+		return nil, constants.ErrInvalidSession
+	}
+	exists, err := a.Sessions.ExistsByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking if session exists: %w", err)
+	}
+	if !exists {
+		return nil, constants.ErrInvalidSession
+	}
+	session, err := a.Sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+	if session.RevokedAt != nil {
+		return nil, constants.ErrInvalidSession
+	}
+
 	return token, nil
 }
 
-func (a *Auth) Authenticate(username, password string) (bool, error) {
-	userExists, err := a.UserAccounts.ExistsByUsername(username)
+// ValidateAPIKey checks that the API key exists and has been neither revoked
+// nor left to expire, returning the username of the user it acts for along
+// with the key's details. Returns ErrInvalidAPIKey otherwise.
+func (a *Auth) ValidateAPIKey(ctx context.Context, key string) (string, *models.APIKey, error) {
+	keyHash := hashOpaqueToken(key)
+	exists, err := a.APIKeys.ExistsByKeyHash(ctx, keyHash)
+	if err != nil {
+		return "", nil, fmt.Errorf("error checking if api key exists: %w", err)
+	}
+	if !exists {
+		return "", nil, constants.ErrInvalidAPIKey
+	}
+	apiKey, err := a.APIKeys.GetByKeyHash(ctx, keyHash)
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting api key: %w", err)
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt)) {
+		return "", nil, constants.ErrInvalidAPIKey
+	}
+
+	userAccount, err := a.UserAccounts.GetByID(ctx, apiKey.UserAccountID)
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting user account: %w", err)
+	}
+	return userAccount.Username, apiKey, nil
+}
+
+func (a *Auth) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	userExists, err := a.UserAccounts.ExistsByUsername(ctx, username)
 	if err != nil {
 		return false, fmt.Errorf("error checking if user exists: %w", err)
 	}
@@ -50,7 +114,7 @@ func (a *Auth) Authenticate(username, password string) (bool, error) {
 		return false, constants.ErrUserDoesNotExist
 	}
 
-	userAccount, err := a.UserAccounts.GetByUsername(username)
+	userAccount, err := a.UserAccounts.GetByUsername(ctx, username)
 	if err != nil {
 		return false, fmt.Errorf("error getting user account: %w", err)
 	}
@@ -63,14 +127,153 @@ func (a *Auth) Authenticate(username, password string) (bool, error) {
 	return isValid, nil
 }
 
-func (a *Auth) CreateAuthToken(username string) (string, error) {
+// CreateSession starts a new session for the user, returning an access token
+// and the opaque refresh token which can later be exchanged for a new pair
+func (a *Auth) CreateSession(ctx context.Context, username string) (string, string, error) {
+	userAccount, err := a.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting user account: %w", err)
+	}
+
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %w", err)
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:               uuid.NewString(),
+		UserAccountID:    userAccount.ID,
+		RefreshTokenHash: hashOpaqueToken(refreshToken),
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(constants.RefreshTokenLifetime),
+		RevokedAt:        nil,
+	}
+	err = a.Sessions.Create(ctx, session)
+	if err != nil {
+		return "", "", fmt.Errorf("error creating session: %w", err)
+	}
+
+	accessToken, err := a.createAccessToken(username, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// CreateChallengeToken returns a short-lived token proving that the user has
+// passed the password check, to be exchanged for a session once the second
+// factor has also been checked. It carries no session, so it is rejected
+// by ValidateSession.
+func (a *Auth) CreateChallengeToken(ctx context.Context, username string) (string, error) {
 	issueTime := time.Now()
 	token := jwt.NewWithClaims(a.SigningMethod, jwt.StandardClaims{
+		Issuer:    a.JWTIssuer,
+		Audience:  a.challengeTokenAudience(),
+		Subject:   username,
+		IssuedAt:  issueTime.Unix(),
+		ExpiresAt: issueTime.Add(constants.ChallengeTokenLifetime).Unix(),
+	})
+
+	signedTokenString, err := token.SignedString(a.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("error signing token: %w", err)
+	}
+	return signedTokenString, nil
+}
+
+// ValidateChallengeToken verifies a token from CreateChallengeToken and
+// returns the username it was issued for
+func (a *Auth) ValidateChallengeToken(ctx context.Context, challengeToken string) (string, error) {
+	token, err := jwt.Parse(challengeToken, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.Alg() {
+		case a.SigningMethod.Alg():
+			return a.PrivateKey.Public(), nil
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Method.Alg())
+		}
+	})
+	if err != nil {
+		return "", constants.ErrInvalidChallengeToken
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	if !claims.VerifyAudience(a.challengeTokenAudience(), true) {
+		return "", constants.ErrInvalidChallengeToken
+	}
+	username, ok := claims["sub"].(string)
+	if !ok {
+		return "", constants.ErrInvalidChallengeToken
+	}
+
+	return username, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token. The
+// refresh token is rotated, so the one passed in cannot be used again.
+func (a *Auth) RefreshSession(ctx context.Context, refreshToken string) (string, string, error) {
+	newRefreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %w", err)
+	}
+	newRefreshTokenHash := hashOpaqueToken(newRefreshToken)
+
+	// a refresh token which is unknown, revoked, expired or already rotated
+	// away by a concurrent request leaves nothing to rotate
+	now := time.Now()
+	rotated, err := a.Sessions.RotateRefreshToken(ctx, hashOpaqueToken(refreshToken), newRefreshTokenHash, now, now.Add(constants.RefreshTokenLifetime))
+	if err != nil {
+		return "", "", fmt.Errorf("error rotating refresh token: %w", err)
+	}
+	if !rotated {
+		return "", "", constants.ErrInvalidRefreshToken
+	}
+	session, err := a.Sessions.GetByRefreshTokenHash(ctx, newRefreshTokenHash)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting session: %w", err)
+	}
+
+	userAccount, err := a.UserAccounts.GetByID(ctx, session.UserAccountID)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting user account: %w", err)
+	}
+	accessToken, err := a.createAccessToken(userAccount.Username, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, newRefreshToken, nil
+}
+
+func (a *Auth) RevokeSession(ctx context.Context, sessionID string) error {
+	session, err := a.Sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("error getting session: %w", err)
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return a.Sessions.Update(ctx, session)
+}
+
+func (a *Auth) RevokeAllSessions(ctx context.Context, username string) error {
+	userAccount, err := a.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("error getting user account: %w", err)
+	}
+	return a.Sessions.RevokeAllByUserAccountID(ctx, userAccount.ID, time.Now())
+}
+
+func (a *Auth) createAccessToken(username, sessionID string) (string, error) {
+	issueTime := time.Now()
+	token := jwt.NewWithClaims(a.SigningMethod, jwt.StandardClaims{
+		Id:        sessionID,
 		Issuer:    a.JWTIssuer,
 		Audience:  a.JWTIssuer,
 		Subject:   username,
 		IssuedAt:  issueTime.Unix(),
-		ExpiresAt: issueTime.Add(60 * time.Minute).Unix(),
+		ExpiresAt: issueTime.Add(constants.AccessTokenLifetime).Unix(),
 	})
 
 	signedTokenString, err := token.SignedString(a.PrivateKey)
@@ -80,6 +283,10 @@ func (a *Auth) CreateAuthToken(username string) (string, error) {
 	return signedTokenString, nil
 }
 
+func (a *Auth) challengeTokenAudience() string {
+	return a.JWTIssuer + "/challenge"
+}
+
 func passwordIsValid(password string, passwordHash string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
 	if err != nil && err == bcrypt.ErrMismatchedHashAndPassword {
@@ -89,3 +296,19 @@ func passwordIsValid(password string, passwordHash string) (bool, error) {
 	}
 	return true, nil
 }
+
+// generateOpaqueToken returns a random, URL-safe token suitable for handing
+// out to clients. Only its hash should ever be stored.
+func generateOpaqueToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
+}
+
+func hashOpaqueToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,250 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAuth returns an Auth service whose sessions are kept in memory by
+// its mock sessions repository
+func newTestAuth(t *testing.T, privateKey *rsa.PrivateKey) (*services.Auth, map[string]*models.Session) {
+	mockCtrl := gomock.NewController(t)
+	mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+	mockSessionsRepository := mockrepositories.NewMockISessions(mockCtrl)
+	sessions := map[string]*models.Session{}
+
+	mockUserAccountsRepository.EXPECT().
+		GetByUsername(gomock.Any(), gomock.Eq("user_1")).
+		AnyTimes().
+		Return(&models.UserAccount{
+			ID:       "__uaid_1__",
+			Username: "user_1",
+		}, nil)
+	mockSessionsRepository.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, session *models.Session) error {
+			sessions[session.ID] = session
+			return nil
+		})
+	mockSessionsRepository.EXPECT().
+		ExistsByID(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, id string) (bool, error) {
+			_, ok := sessions[id]
+			return ok, nil
+		})
+	mockSessionsRepository.EXPECT().
+		GetByID(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, id string) (*models.Session, error) {
+			session := *sessions[id]
+			return &session, nil
+		})
+	mockSessionsRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, session *models.Session) error {
+			sessions[session.ID] = session
+			return nil
+		})
+	mockSessionsRepository.EXPECT().
+		RevokeAllByUserAccountID(gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, userAccountID string, revokedAt time.Time) error {
+			for _, session := range sessions {
+				if session.UserAccountID == userAccountID && session.RevokedAt == nil {
+					session.RevokedAt = &revokedAt
+				}
+			}
+			return nil
+		})
+
+	return &services.Auth{
+		JWTIssuer:     "moneybags-test",
+		SigningMethod: jwt.SigningMethodRS256,
+		PrivateKey:    privateKey,
+		UserAccounts:  mockUserAccountsRepository,
+		Sessions:      mockSessionsRepository,
+	}, sessions
+}
+
+func TestAuthValidateSession(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		setupFunc   func(a *services.Auth, sessions map[string]*models.Session) string
+		expectedErr error
+	}{
+		{
+			name: "live session",
+			setupFunc: func(a *services.Auth, sessions map[string]*models.Session) string {
+				accessToken, _, err := a.CreateSession(context.Background(), "user_1")
+				assert.NoError(t, err)
+				return accessToken
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "logged out",
+			setupFunc: func(a *services.Auth, sessions map[string]*models.Session) string {
+				accessToken, _, err := a.CreateSession(context.Background(), "user_1")
+				assert.NoError(t, err)
+				for id := range sessions {
+					assert.NoError(t, a.RevokeSession(context.Background(), id))
+				}
+				return accessToken
+			},
+			expectedErr: constants.ErrInvalidSession,
+		},
+		{
+			name: "logged out everywhere",
+			setupFunc: func(a *services.Auth, sessions map[string]*models.Session) string {
+				accessToken, _, err := a.CreateSession(context.Background(), "user_1")
+				assert.NoError(t, err)
+				_, _, err = a.CreateSession(context.Background(), "user_1")
+				assert.NoError(t, err)
+				assert.NoError(t, a.RevokeAllSessions(context.Background(), "user_1"))
+				return accessToken
+			},
+			expectedErr: constants.ErrInvalidSession,
+		},
+		{
+			name: "unknown session",
+			setupFunc: func(a *services.Auth, sessions map[string]*models.Session) string {
+				accessToken, _, err := a.CreateSession(context.Background(), "user_1")
+				assert.NoError(t, err)
+				for id := range sessions {
+					delete(sessions, id)
+				}
+				return accessToken
+			},
+			expectedErr: constants.ErrInvalidSession,
+		},
+		{
+			name: "challenge token",
+			setupFunc: func(a *services.Auth, sessions map[string]*models.Session) string {
+				challengeToken, err := a.CreateChallengeToken(context.Background(), "user_1")
+				assert.NoError(t, err)
+				return challengeToken
+			},
+			expectedErr: constants.ErrInvalidSession,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, sessions := newTestAuth(t, privateKey)
+			tokenString := tt.setupFunc(a, sessions)
+
+			token, err := a.ValidateSession(context.Background(), tokenString)
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr == nil {
+				assert.Equal(t, "user_1", token.Claims.(jwt.MapClaims)["sub"])
+			}
+		})
+	}
+}
+
+func TestAuthRefreshSession(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		mockSetupFunc func(mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions)
+		expectedErr   error
+	}{
+		{
+			name: "rotates the refresh token",
+			mockSetupFunc: func(mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions) {
+				var newRefreshTokenHash string
+				rotateCall := ms.EXPECT().
+					RotateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, refreshTokenHash, newHash string, now, expiresAt time.Time) (bool, error) {
+						assert.NotEqual(t, refreshTokenHash, newHash)
+						assert.Equal(t, now.Add(constants.RefreshTokenLifetime), expiresAt)
+						newRefreshTokenHash = newHash
+						return true, nil
+					})
+				getCall := ms.EXPECT().
+					GetByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					After(rotateCall).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, refreshTokenHash string) (*models.Session, error) {
+						assert.Equal(t, newRefreshTokenHash, refreshTokenHash)
+						return &models.Session{
+							ID:               "__sid_1__",
+							UserAccountID:    "__uaid_1__",
+							RefreshTokenHash: refreshTokenHash,
+						}, nil
+					})
+				mua.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__uaid_1__")).
+					After(getCall).
+					Times(1).
+					Return(&models.UserAccount{
+						ID:       "__uaid_1__",
+						Username: "user_1",
+					}, nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "invalid, revoked, expired or already rotated",
+			mockSetupFunc: func(mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions) {
+				ms.EXPECT().
+					RotateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				ms.EXPECT().
+					GetByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectedErr: constants.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+			mockSessionsRepository := mockrepositories.NewMockISessions(mockCtrl)
+
+			tt.mockSetupFunc(mockUserAccountsRepository, mockSessionsRepository)
+
+			a := &services.Auth{
+				JWTIssuer:     "moneybags-test",
+				SigningMethod: jwt.SigningMethodRS256,
+				PrivateKey:    privateKey,
+				UserAccounts:  mockUserAccountsRepository,
+				Sessions:      mockSessionsRepository,
+			}
+
+			accessToken, refreshToken, err := a.RefreshSession(context.Background(), "__refresh_token_1__")
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr == nil {
+				assert.NotEmpty(t, accessToken)
+				assert.NotEqual(t, "__refresh_token_1__", refreshToken)
+			}
+		})
+	}
+}
@@ -3,57 +3,157 @@ package services
 //go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/currency"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/paulwrubel/moneybags-server/repositories"
 )
 
 type IBankAccounts interface {
-	ExistsByID(id string) (bool, error)
-	GetAll(budgetID string) ([]*models.BankAccount, error)
-	GetByID(id string) (*models.BankAccount, error)
-	Create(budgetID string, name string) (*models.BankAccount, error)
-	Delete(id string) error
+	BelongsTo(ctx context.Context, budgetID, bankAccountID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error)
+	GetPage(ctx context.Context, budgetID string, page *pagination.Params) ([]*models.BankAccount, *pagination.Cursor, error)
+	GetByID(ctx context.Context, id string) (*models.BankAccount, error)
+	GetBalance(ctx context.Context, id string) (*models.BankAccountBalance, error)
+	Create(ctx context.Context, budgetID string, name string, accountType models.BankAccountType, currencyCode string) (*models.BankAccount, error)
+	Update(ctx context.Context, bankAccount *models.BankAccount) (*models.BankAccount, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type BankAccounts struct {
-	Repository repositories.IBankAccounts
+	UnitOfWork     repositories.IUnitOfWork
+	Repository     repositories.IBankAccounts
+	RBudgets       repositories.IBudgets
+	RTransactions  repositories.ITransactions
+	SExchangeRates IExchangeRates
 }
 
-func (ba *BankAccounts) ExistsByID(id string) (bool, error) {
-	return ba.Repository.ExistsByID(id)
+func (ba *BankAccounts) BelongsTo(ctx context.Context, budgetID, bankAccountID string) (bool, error) {
+	bankAccount, err := ba.Repository.GetByID(ctx, bankAccountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bank account by id: %v", err)
+	}
+	return budgetID == bankAccount.BudgetID, nil
+}
+
+func (ba *BankAccounts) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return ba.Repository.ExistsByID(ctx, id)
 }
 
-func (ba *BankAccounts) GetAll(budgetID string) ([]*models.BankAccount, error) {
-	return ba.Repository.GetAllByBudgetID(budgetID)
+func (ba *BankAccounts) ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error) {
+	return ba.Repository.ExistsByBudgetIDAndName(ctx, budgetID, name)
 }
 
-func (ba *BankAccounts) GetByID(id string) (*models.BankAccount, error) {
-	return ba.Repository.GetByID(id)
+func (ba *BankAccounts) GetPage(ctx context.Context, budgetID string, page *pagination.Params) ([]*models.BankAccount, *pagination.Cursor, error) {
+	return ba.Repository.GetPageByBudgetID(ctx, budgetID, page)
 }
 
-func (ba *BankAccounts) Create(budgetID string, name string) (*models.BankAccount, error) {
+func (ba *BankAccounts) GetByID(ctx context.Context, id string) (*models.BankAccount, error) {
+	return ba.Repository.GetByID(ctx, id)
+}
+
+// GetBalance totals the bank account's transactions, converting the total
+// into the budget's currency at the latest rate. Returns ErrNoExchangeRate if
+// the currencies differ and there is no rate between them.
+func (ba *BankAccounts) GetBalance(ctx context.Context, id string) (*models.BankAccountBalance, error) {
+	bankAccount, err := ba.Repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bank account: %w", err)
+	}
+	budget, err := ba.RBudgets.GetByID(ctx, bankAccount.BudgetID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting budget: %w", err)
+	}
+	balance, err := ba.RTransactions.GetBalanceByBankAccountID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting balance: %w", err)
+	}
+	budgetBalance, err := ba.SExchangeRates.Convert(ctx, balance, bankAccount.Currency, budget.Currency, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BankAccountBalance{
+		BankAccountID:  bankAccount.ID,
+		Currency:       bankAccount.Currency,
+		Balance:        balance,
+		BudgetCurrency: budget.Currency,
+		BudgetBalance:  budgetBalance,
+	}, nil
+}
+
+func (ba *BankAccounts) Create(ctx context.Context, budgetID string, name string, accountType models.BankAccountType, currencyCode string) (*models.BankAccount, error) {
+	if !bankAccountTypeIsValid(accountType) {
+		return nil, constants.ErrInvalidBankAccountType
+	}
+	if !currency.Valid(currencyCode) {
+		return nil, constants.ErrInvalidCurrency
+	}
+
 	newBankAccount := &models.BankAccount{
 		ID:       uuid.NewString(),
 		BudgetID: budgetID,
 		Name:     name,
+		Type:     accountType,
+		Closed:   false,
+		Currency: currencyCode,
 	}
-	err := ba.Repository.Create(newBankAccount)
+	err := ba.Repository.Create(ctx, newBankAccount)
 	if err != nil {
 		return nil, err
 	}
-	exists, err := ba.ExistsByID(newBankAccount.ID)
+	exists, err := ba.ExistsByID(ctx, newBankAccount.ID)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
 		return nil, errors.New("bank account failed post creation existence check")
 	}
-	return ba.Repository.GetByID(newBankAccount.ID)
+	return ba.Repository.GetByID(ctx, newBankAccount.ID)
 }
 
-func (ba *BankAccounts) Delete(id string) error {
-	return ba.Repository.DeleteByID(id)
+func (ba *BankAccounts) Update(ctx context.Context, bankAccount *models.BankAccount) (*models.BankAccount, error) {
+	if !bankAccountTypeIsValid(bankAccount.Type) {
+		return nil, constants.ErrInvalidBankAccountType
+	}
+
+	err := ba.Repository.Update(ctx, bankAccount)
+	if err != nil {
+		return nil, err
+	}
+	return ba.Repository.GetByID(ctx, bankAccount.ID)
+}
+
+// Delete removes the bank account and its transactions. The other side of
+// each of its transfers is kept, as an ordinary transaction in the other
+// bank account.
+func (ba *BankAccounts) Delete(ctx context.Context, id string) error {
+	return ba.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		err := repos.Transactions.UnlinkTransfersByBankAccountID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error unlinking transfers: %w", err)
+		}
+		return repos.BankAccounts.DeleteByID(ctx, id)
+	})
+}
+
+func bankAccountTypeIsValid(accountType models.BankAccountType) bool {
+	switch accountType {
+	case models.BankAccountTypeChecking,
+		models.BankAccountTypeSavings,
+		models.BankAccountTypeCreditCard,
+		models.BankAccountTypeCash,
+		models.BankAccountTypeOther:
+		return true
+	default:
+		return false
+	}
 }
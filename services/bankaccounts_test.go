@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	mockservices "github.com/paulwrubel/moneybags-server/mocks/services"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankAccountsGetBalance(t *testing.T) {
+	tests := []struct {
+		name            string
+		convertedAmount int64
+		convertErr      error
+		expectedBalance *models.BankAccountBalance
+		expectedErr     error
+	}{
+		{
+			name:            "converts into the budget's currency",
+			convertedAmount: 10850,
+			convertErr:      nil,
+			expectedBalance: &models.BankAccountBalance{
+				BankAccountID:  "__baid_1__",
+				Currency:       "EUR",
+				Balance:        10000,
+				BudgetCurrency: "USD",
+				BudgetBalance:  10850,
+			},
+			expectedErr: nil,
+		},
+		{
+			name:            "no exchange rate",
+			convertErr:      constants.ErrNoExchangeRate,
+			expectedBalance: nil,
+			expectedErr:     constants.ErrNoExchangeRate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockBankAccountsRepository := mockrepositories.NewMockIBankAccounts(mockCtrl)
+			mockBudgetsRepository := mockrepositories.NewMockIBudgets(mockCtrl)
+			mockTransactionsRepository := mockrepositories.NewMockITransactions(mockCtrl)
+			mockExchangeRatesService := mockservices.NewMockIExchangeRates(mockCtrl)
+
+			mockBankAccountsRepository.EXPECT().
+				GetByID(gomock.Any(), gomock.Eq("__baid_1__")).
+				Times(1).
+				Return(&models.BankAccount{
+					ID:       "__baid_1__",
+					BudgetID: "__bid_1__",
+					Currency: "EUR",
+				}, nil)
+			mockBudgetsRepository.EXPECT().
+				GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+				Times(1).
+				Return(&models.Budget{
+					ID:       "__bid_1__",
+					Currency: "USD",
+				}, nil)
+			mockTransactionsRepository.EXPECT().
+				GetBalanceByBankAccountID(gomock.Any(), gomock.Eq("__baid_1__")).
+				Times(1).
+				Return(int64(10000), nil)
+			mockExchangeRatesService.EXPECT().
+				Convert(gomock.Any(), gomock.Eq(int64(10000)), gomock.Eq("EUR"), gomock.Eq("USD"), gomock.Any()).
+				Times(1).
+				Return(tt.convertedAmount, tt.convertErr)
+
+			ba := &services.BankAccounts{
+				Repository:     mockBankAccountsRepository,
+				RBudgets:       mockBudgetsRepository,
+				RTransactions:  mockTransactionsRepository,
+				SExchangeRates: mockExchangeRatesService,
+			}
+
+			balance, err := ba.GetBalance(context.Background(), "__baid_1__")
+			// the controller compares errors directly, so ErrNoExchangeRate
+			// must not be wrapped
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedBalance, balance)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IBudgetInvitations interface {
+	BelongsTo(ctx context.Context, budgetID, invitationID string) (bool, error)
+	IsFor(ctx context.Context, userAccountID, invitationID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetAll(ctx context.Context, budgetID string) ([]*models.BudgetInvitation, error)
+	GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.BudgetInvitation, error)
+	GetByID(ctx context.Context, id string) (*models.BudgetInvitation, error)
+	Create(ctx context.Context, budgetID, invitedByUserAccountID, username string, role models.BudgetRole) (*models.BudgetInvitation, error)
+	Accept(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+type BudgetInvitations struct {
+	UnitOfWork         repositories.IUnitOfWork
+	RBudgetInvitations repositories.IBudgetInvitations
+}
+
+func (bi *BudgetInvitations) BelongsTo(ctx context.Context, budgetID, invitationID string) (bool, error) {
+	invitation, err := bi.RBudgetInvitations.GetByID(ctx, invitationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get budget invitation by id: %v", err)
+	}
+	return budgetID == invitation.BudgetID, nil
+}
+
+// IsFor reports whether the invitation was sent to the user
+func (bi *BudgetInvitations) IsFor(ctx context.Context, userAccountID, invitationID string) (bool, error) {
+	invitation, err := bi.RBudgetInvitations.GetByID(ctx, invitationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get budget invitation by id: %v", err)
+	}
+	return userAccountID == invitation.UserAccountID, nil
+}
+
+func (bi *BudgetInvitations) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return bi.RBudgetInvitations.ExistsByID(ctx, id)
+}
+
+func (bi *BudgetInvitations) GetAll(ctx context.Context, budgetID string) ([]*models.BudgetInvitation, error) {
+	return bi.RBudgetInvitations.GetAllByBudgetID(ctx, budgetID)
+}
+
+func (bi *BudgetInvitations) GetAllByUserAccountID(ctx context.Context, userAccountID string) ([]*models.BudgetInvitation, error) {
+	return bi.RBudgetInvitations.GetAllByUserAccountID(ctx, userAccountID)
+}
+
+func (bi *BudgetInvitations) GetByID(ctx context.Context, id string) (*models.BudgetInvitation, error) {
+	return bi.RBudgetInvitations.GetByID(ctx, id)
+}
+
+// Create invites the user with the given username to the budget. Returns
+// ErrUserDoesNotExist if there is no such user, ErrAlreadyBudgetMember if
+// they are already a member, or ErrBudgetInvitationExists if they have
+// already been invited.
+func (bi *BudgetInvitations) Create(ctx context.Context, budgetID, invitedByUserAccountID, username string, role models.BudgetRole) (*models.BudgetInvitation, error) {
+	if !budgetRoleIsValid(role) {
+		return nil, constants.ErrInvalidBudgetRole
+	}
+
+	var createdInvitation *models.BudgetInvitation
+	err := bi.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		userExists, err := repos.UserAccounts.ExistsByUsername(ctx, username)
+		if err != nil {
+			return fmt.Errorf("error checking if user exists: %w", err)
+		}
+		if !userExists {
+			return constants.ErrUserDoesNotExist
+		}
+		userAccount, err := repos.UserAccounts.GetByUsername(ctx, username)
+		if err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		isMember, err := repos.BudgetMemberships.ExistsByBudgetIDAndUserAccountID(ctx, budgetID, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error checking budget membership: %w", err)
+		}
+		if isMember {
+			return constants.ErrAlreadyBudgetMember
+		}
+		isInvited, err := repos.BudgetInvitations.ExistsByBudgetIDAndUserAccountID(ctx, budgetID, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error checking for budget invitation: %w", err)
+		}
+		if isInvited {
+			return constants.ErrBudgetInvitationExists
+		}
+
+		newInvitation := &models.BudgetInvitation{
+			ID:                     uuid.NewString(),
+			BudgetID:               budgetID,
+			UserAccountID:          userAccount.ID,
+			InvitedByUserAccountID: invitedByUserAccountID,
+			Role:                   role,
+		}
+		err = repos.BudgetInvitations.Create(ctx, newInvitation)
+		if err != nil {
+			return err
+		}
+		exists, err := repos.BudgetInvitations.ExistsByID(ctx, newInvitation.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("budget invitation failed post-creation existence check")
+		}
+		createdInvitation, err = repos.BudgetInvitations.GetByID(ctx, newInvitation.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return createdInvitation, nil
+}
+
+// Accept makes the invited user a member of the budget with the invited role
+func (bi *BudgetInvitations) Accept(ctx context.Context, id string) error {
+	return bi.RBudgetInvitations.Accept(ctx, id)
+}
+
+// Delete revokes or declines the invitation
+func (bi *BudgetInvitations) Delete(ctx context.Context, id string) error {
+	return bi.RBudgetInvitations.DeleteByID(ctx, id)
+}
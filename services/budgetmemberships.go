@@ -0,0 +1,119 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+// budgetRoleRanks orders the budget roles, each granting everything the roles
+// ranked below it do
+var budgetRoleRanks = map[models.BudgetRole]int{
+	models.BudgetRoleViewer: 1,
+	models.BudgetRoleEditor: 2,
+	models.BudgetRoleOwner:  3,
+}
+
+type IBudgetMemberships interface {
+	ExistsByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (bool, error)
+	GetAll(ctx context.Context, budgetID string) ([]*models.BudgetMembership, error)
+	GetByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (*models.BudgetMembership, error)
+	UpdateRole(ctx context.Context, budgetID, userAccountID string, role models.BudgetRole) (*models.BudgetMembership, error)
+	Delete(ctx context.Context, budgetID, userAccountID string) error
+}
+
+type BudgetMemberships struct {
+	UnitOfWork         repositories.IUnitOfWork
+	RBudgetMemberships repositories.IBudgetMemberships
+}
+
+func (bm *BudgetMemberships) ExistsByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (bool, error) {
+	return bm.RBudgetMemberships.ExistsByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+}
+
+func (bm *BudgetMemberships) GetAll(ctx context.Context, budgetID string) ([]*models.BudgetMembership, error) {
+	return bm.RBudgetMemberships.GetAllByBudgetID(ctx, budgetID)
+}
+
+func (bm *BudgetMemberships) GetByBudgetIDAndUserAccountID(ctx context.Context, budgetID, userAccountID string) (*models.BudgetMembership, error) {
+	return bm.RBudgetMemberships.GetByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+}
+
+// UpdateRole changes a member's role. Returns ErrBudgetNeedsOwner if that
+// would leave the budget without an owner.
+func (bm *BudgetMemberships) UpdateRole(ctx context.Context, budgetID, userAccountID string, role models.BudgetRole) (*models.BudgetMembership, error) {
+	if !budgetRoleIsValid(role) {
+		return nil, constants.ErrInvalidBudgetRole
+	}
+
+	var updatedMembership *models.BudgetMembership
+	err := bm.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		membership, err := repos.BudgetMemberships.GetByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+		if err != nil {
+			return fmt.Errorf("error getting budget membership: %w", err)
+		}
+		if membership.Role == models.BudgetRoleOwner && role != models.BudgetRoleOwner {
+			err = checkOtherOwnerExists(ctx, repos.BudgetMemberships, budgetID, userAccountID)
+			if err != nil {
+				return err
+			}
+		}
+
+		membership.Role = role
+		err = repos.BudgetMemberships.Update(ctx, membership)
+		if err != nil {
+			return err
+		}
+		updatedMembership, err = repos.BudgetMemberships.GetByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedMembership, nil
+}
+
+// Delete removes a member from the budget. Returns ErrBudgetNeedsOwner if
+// that would leave the budget without an owner.
+func (bm *BudgetMemberships) Delete(ctx context.Context, budgetID, userAccountID string) error {
+	return bm.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		membership, err := repos.BudgetMemberships.GetByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+		if err != nil {
+			return fmt.Errorf("error getting budget membership: %w", err)
+		}
+		if membership.Role == models.BudgetRoleOwner {
+			err = checkOtherOwnerExists(ctx, repos.BudgetMemberships, budgetID, userAccountID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return repos.BudgetMemberships.DeleteByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+	})
+}
+
+// checkOtherOwnerExists returns ErrBudgetNeedsOwner unless the budget has an
+// owner besides the given user. It must run in the same unit of work as the
+// change it guards, or two owners could each demote themselves at once.
+func checkOtherOwnerExists(ctx context.Context, rBudgetMemberships repositories.IBudgetMemberships, budgetID, userAccountID string) error {
+	memberships, err := rBudgetMemberships.GetAllByBudgetID(ctx, budgetID)
+	if err != nil {
+		return fmt.Errorf("error getting budget memberships: %w", err)
+	}
+	for _, membership := range memberships {
+		if membership.Role == models.BudgetRoleOwner && membership.UserAccountID != userAccountID {
+			return nil
+		}
+	}
+	return constants.ErrBudgetNeedsOwner
+}
+
+func budgetRoleIsValid(role models.BudgetRole) bool {
+	_, ok := budgetRoleRanks[role]
+	return ok
+}
@@ -3,81 +3,170 @@ package services
 //go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/currency"
 	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
 	"github.com/paulwrubel/moneybags-server/repositories"
 )
 
 type IBudgets interface {
-	BelongsTo(userAccountID, budgetID string) (bool, error)
-	ExistsByID(id string) (bool, error)
-	ExistsByUserIDAndName(userAccountID, name string) (bool, error)
-	GetAllByUserAccountID(userAccountID string) ([]*models.Budget, error)
-	GetByID(id string) (*models.Budget, error)
-	GetByUserIDAndName(userAccountID, name string) (*models.Budget, error)
-	Create(userAccountId, name string) (*models.Budget, error)
-	Delete(id string) error
+	Authorize(ctx context.Context, userAccountID, budgetID string, role models.BudgetRole) error
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByUserIDAndName(ctx context.Context, userAccountID, name string) (bool, error)
+	GetPageByUserAccountID(ctx context.Context, userAccountID string, page *pagination.Params) ([]*models.Budget, *pagination.Cursor, error)
+	GetByID(ctx context.Context, id string) (*models.Budget, error)
+	GetByUserIDAndName(ctx context.Context, userAccountID, name string) (*models.Budget, error)
+	Create(ctx context.Context, userAccountId, name, currencyCode string) (*models.Budget, error)
+	Update(ctx context.Context, budget *models.Budget) (*models.Budget, error)
+	Delete(ctx context.Context, id string, cascade bool) error
 }
 
 type Budgets struct {
-	RBudgets repositories.IBudgets
+	UnitOfWork         repositories.IUnitOfWork
+	RBudgets           repositories.IBudgets
+	RBudgetMemberships repositories.IBudgetMemberships
 }
 
-func (b *Budgets) BelongsTo(userAccountID, budgetID string) (bool, error) {
-	budget, err := b.RBudgets.GetByID(budgetID)
+// Authorize checks that the user's role on the budget is at least role.
+// Returns ErrNotBudgetMember if the user is not a member of the budget at
+// all, or ErrInsufficientBudgetRole if their role falls short.
+func (b *Budgets) Authorize(ctx context.Context, userAccountID, budgetID string, role models.BudgetRole) error {
+	isMember, err := b.RBudgetMemberships.ExistsByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
 	if err != nil {
-		return false, fmt.Errorf("failed to get budget by id: %v", err)
+		return fmt.Errorf("error checking budget membership: %w", err)
 	}
-	return userAccountID == budget.UserAccountID, nil
+	if !isMember {
+		return constants.ErrNotBudgetMember
+	}
+	membership, err := b.RBudgetMemberships.GetByBudgetIDAndUserAccountID(ctx, budgetID, userAccountID)
+	if err != nil {
+		return fmt.Errorf("error getting budget membership: %w", err)
+	}
+
+	if budgetRoleRanks[membership.Role] < budgetRoleRanks[role] {
+		return constants.ErrInsufficientBudgetRole
+	}
+	return nil
 }
 
-func (b *Budgets) ExistsByID(id string) (bool, error) {
-	return b.RBudgets.ExistsByID(id)
+func (b *Budgets) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return b.RBudgets.ExistsByID(ctx, id)
 }
 
-func (b *Budgets) ExistsByUserIDAndName(userAccountID, name string) (bool, error) {
-	return b.RBudgets.ExistsByUserIDAndName(userAccountID, name)
+func (b *Budgets) ExistsByUserIDAndName(ctx context.Context, userAccountID, name string) (bool, error) {
+	return b.RBudgets.ExistsByUserIDAndName(ctx, userAccountID, name)
 }
 
-func (b *Budgets) GetAllByUserAccountID(userAccountID string) ([]*models.Budget, error) {
-	budgets, err := b.RBudgets.GetAllByUserAccountID(userAccountID)
-	if err != nil {
-		return nil, err
-	}
-	return budgets, nil
+func (b *Budgets) GetPageByUserAccountID(ctx context.Context, userAccountID string, page *pagination.Params) ([]*models.Budget, *pagination.Cursor, error) {
+	return b.RBudgets.GetPageByUserAccountID(ctx, userAccountID, page)
 }
 
-func (b *Budgets) GetByID(id string) (*models.Budget, error) {
-	return b.RBudgets.GetByID(id)
+func (b *Budgets) GetByID(ctx context.Context, id string) (*models.Budget, error) {
+	return b.RBudgets.GetByID(ctx, id)
 }
 
-func (b *Budgets) GetByUserIDAndName(userAccountID, name string) (*models.Budget, error) {
-	return b.RBudgets.GetByUserIDAndName(userAccountID, name)
+func (b *Budgets) GetByUserIDAndName(ctx context.Context, userAccountID, name string) (*models.Budget, error) {
+	return b.RBudgets.GetByUserIDAndName(ctx, userAccountID, name)
 }
 
-func (b *Budgets) Create(userAccountID, name string) (*models.Budget, error) {
+func (b *Budgets) Create(ctx context.Context, userAccountID, name, currencyCode string) (*models.Budget, error) {
+	if !currency.Valid(currencyCode) {
+		return nil, constants.ErrInvalidCurrency
+	}
+
 	newBudget := &models.Budget{
 		ID:            uuid.NewString(),
 		UserAccountID: userAccountID,
 		Name:          name,
+		Currency:      currencyCode,
 	}
-	err := b.RBudgets.Create(newBudget)
+	var createdBudget *models.Budget
+	err := b.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		err := repos.Budgets.Create(ctx, newBudget)
+		if err != nil {
+			return err
+		}
+		exists, err := repos.Budgets.ExistsByID(ctx, newBudget.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("budget failed post-creation existence check")
+		}
+		createdBudget, err = repos.Budgets.GetByID(ctx, newBudget.ID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	exists, err := b.ExistsByID(newBudget.ID)
+	return createdBudget, nil
+}
+
+// Update saves the budget's name, currency and archived state. Amounts are
+// stored in the minor units of the budget's currency, so the currency is only
+// allowed to change while the budget has no bank accounts or allocations;
+// otherwise ErrBudgetCurrencyInUse is returned.
+func (b *Budgets) Update(ctx context.Context, budget *models.Budget) (*models.Budget, error) {
+	if !currency.Valid(budget.Currency) {
+		return nil, constants.ErrInvalidCurrency
+	}
+
+	var updatedBudget *models.Budget
+	err := b.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		currentBudget, err := repos.Budgets.GetByID(ctx, budget.ID)
+		if err != nil {
+			return fmt.Errorf("error getting budget: %w", err)
+		}
+		if currentBudget.Currency != budget.Currency {
+			bankAccounts, err := repos.BankAccounts.GetAllByBudgetID(ctx, budget.ID)
+			if err != nil {
+				return fmt.Errorf("error getting bank accounts: %w", err)
+			}
+			if len(bankAccounts) > 0 {
+				return constants.ErrBudgetCurrencyInUse
+			}
+			allocated, err := repos.CategoryAllocations.ExistsByBudgetID(ctx, budget.ID)
+			if err != nil {
+				return fmt.Errorf("error checking category allocations: %w", err)
+			}
+			if allocated {
+				return constants.ErrBudgetCurrencyInUse
+			}
+		}
+
+		err = repos.Budgets.Update(ctx, budget)
+		if err != nil {
+			return err
+		}
+		updatedBudget, err = repos.Budgets.GetByID(ctx, budget.ID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, errors.New("budget failed post-creation existence check")
-	}
-	return b.RBudgets.GetByID(newBudget.ID)
+	return updatedBudget, nil
 }
 
-func (b *Budgets) Delete(id string) error {
-	return b.RBudgets.DeleteByID(id)
+// Delete removes a budget. Unless cascade is set, budgets which still have
+// bank accounts are refused with ErrBudgetHasBankAccounts; otherwise the
+// budget's bank accounts, transactions and categories are removed with it.
+func (b *Budgets) Delete(ctx context.Context, id string, cascade bool) error {
+	return b.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		if !cascade {
+			bankAccounts, err := repos.BankAccounts.GetAllByBudgetID(ctx, id)
+			if err != nil {
+				return fmt.Errorf("error getting bank accounts: %w", err)
+			}
+			if len(bankAccounts) > 0 {
+				return constants.ErrBudgetHasBankAccounts
+			}
+		}
+		return repos.Budgets.DeleteByID(ctx, id)
+	})
 }
@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetsUpdate(t *testing.T) {
+	tests := []struct {
+		name            string
+		currency        string
+		bankAccounts    []*models.BankAccount
+		allocated       bool
+		expectedUpdated bool
+		expectedErr     error
+	}{
+		{
+			name:            "same currency - not checked",
+			currency:        "USD",
+			expectedUpdated: true,
+			expectedErr:     nil,
+		},
+		{
+			name:            "new currency - empty budget",
+			currency:        "JPY",
+			bankAccounts:    []*models.BankAccount{},
+			allocated:       false,
+			expectedUpdated: true,
+			expectedErr:     nil,
+		},
+		{
+			name:     "new currency - has bank accounts",
+			currency: "JPY",
+			bankAccounts: []*models.BankAccount{
+				{ID: "__baid_1__", BudgetID: "__bid_1__", Currency: "USD"},
+			},
+			expectedUpdated: false,
+			expectedErr:     constants.ErrBudgetCurrencyInUse,
+		},
+		{
+			name:            "new currency - has allocations",
+			currency:        "JPY",
+			bankAccounts:    []*models.BankAccount{},
+			allocated:       true,
+			expectedUpdated: false,
+			expectedErr:     constants.ErrBudgetCurrencyInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUnitOfWork := mockrepositories.NewMockIUnitOfWork(mockCtrl)
+			mockBudgetsRepository := mockrepositories.NewMockIBudgets(mockCtrl)
+			mockBankAccountsRepository := mockrepositories.NewMockIBankAccounts(mockCtrl)
+			mockCategoryAllocationsRepository := mockrepositories.NewMockICategoryAllocations(mockCtrl)
+
+			mockUnitOfWork.EXPECT().
+				Do(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, fn func(repos *repositories.Repositories) error) error {
+					return fn(&repositories.Repositories{
+						UnitOfWork:          mockUnitOfWork,
+						Budgets:             mockBudgetsRepository,
+						BankAccounts:        mockBankAccountsRepository,
+						CategoryAllocations: mockCategoryAllocationsRepository,
+					})
+				})
+			mockBudgetsRepository.EXPECT().
+				GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+				Times(1).
+				Return(&models.Budget{
+					ID:       "__bid_1__",
+					Name:     "budget_1",
+					Currency: "USD",
+				}, nil)
+			if tt.bankAccounts != nil {
+				mockBankAccountsRepository.EXPECT().
+					GetAllByBudgetID(gomock.Any(), gomock.Eq("__bid_1__")).
+					Times(1).
+					Return(tt.bankAccounts, nil)
+			}
+			if tt.bankAccounts != nil && len(tt.bankAccounts) == 0 {
+				mockCategoryAllocationsRepository.EXPECT().
+					ExistsByBudgetID(gomock.Any(), gomock.Eq("__bid_1__")).
+					Times(1).
+					Return(tt.allocated, nil)
+			}
+			if tt.expectedUpdated {
+				mockBudgetsRepository.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				mockBudgetsRepository.EXPECT().
+					GetByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					Times(1).
+					Return(&models.Budget{
+						ID:       "__bid_1__",
+						Name:     "budget_1",
+						Currency: tt.currency,
+					}, nil)
+			} else {
+				mockBudgetsRepository.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Times(0)
+			}
+
+			b := &services.Budgets{
+				UnitOfWork: mockUnitOfWork,
+				RBudgets:   mockBudgetsRepository,
+			}
+
+			budget, err := b.Update(context.Background(), &models.Budget{
+				ID:       "__bid_1__",
+				Name:     "budget_1",
+				Currency: tt.currency,
+			})
+
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedUpdated {
+				assert.Equal(t, tt.currency, budget.Currency)
+			} else {
+				assert.Nil(t, budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type ICategories interface {
+	BelongsTo(ctx context.Context, budgetID, categoryID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByCategoryGroupIDAndName(ctx context.Context, categoryGroupID, name string) (bool, error)
+	GetAll(ctx context.Context, budgetID string) ([]*models.Category, error)
+	GetByID(ctx context.Context, id string) (*models.Category, error)
+	Create(ctx context.Context, categoryGroupID, name string) (*models.Category, error)
+	Update(ctx context.Context, category *models.Category) (*models.Category, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Categories struct {
+	RCategories     repositories.ICategories
+	RCategoryGroups repositories.ICategoryGroups
+}
+
+func (c *Categories) BelongsTo(ctx context.Context, budgetID, categoryID string) (bool, error) {
+	category, err := c.RCategories.GetByID(ctx, categoryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get category by id: %v", err)
+	}
+	categoryGroup, err := c.RCategoryGroups.GetByID(ctx, category.CategoryGroupID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get category group by id: %v", err)
+	}
+	return budgetID == categoryGroup.BudgetID, nil
+}
+
+func (c *Categories) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return c.RCategories.ExistsByID(ctx, id)
+}
+
+func (c *Categories) ExistsByCategoryGroupIDAndName(ctx context.Context, categoryGroupID, name string) (bool, error) {
+	return c.RCategories.ExistsByCategoryGroupIDAndName(ctx, categoryGroupID, name)
+}
+
+func (c *Categories) GetAll(ctx context.Context, budgetID string) ([]*models.Category, error) {
+	return c.RCategories.GetAllByBudgetID(ctx, budgetID)
+}
+
+func (c *Categories) GetByID(ctx context.Context, id string) (*models.Category, error) {
+	return c.RCategories.GetByID(ctx, id)
+}
+
+func (c *Categories) Create(ctx context.Context, categoryGroupID, name string) (*models.Category, error) {
+	newCategory := &models.Category{
+		ID:              uuid.NewString(),
+		CategoryGroupID: categoryGroupID,
+		Name:            name,
+	}
+	err := c.RCategories.Create(ctx, newCategory)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := c.ExistsByID(ctx, newCategory.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("category failed post-creation existence check")
+	}
+	return c.RCategories.GetByID(ctx, newCategory.ID)
+}
+
+func (c *Categories) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	err := c.RCategories.Update(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return c.RCategories.GetByID(ctx, category.ID)
+}
+
+func (c *Categories) Delete(ctx context.Context, id string) error {
+	return c.RCategories.DeleteByID(ctx, id)
+}
@@ -0,0 +1,109 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type ICategorizationRules interface {
+	BelongsTo(ctx context.Context, budgetID, ruleID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetAll(ctx context.Context, budgetID string) ([]*models.CategorizationRule, error)
+	GetByID(ctx context.Context, id string) (*models.CategorizationRule, error)
+	Create(ctx context.Context, budgetID, categoryID string, payeeContains *string, amountMin, amountMax *int64, priority int) (*models.CategorizationRule, error)
+	Update(ctx context.Context, rule *models.CategorizationRule) (*models.CategorizationRule, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type CategorizationRules struct {
+	RCategorizationRules repositories.ICategorizationRules
+}
+
+func (cr *CategorizationRules) BelongsTo(ctx context.Context, budgetID, ruleID string) (bool, error) {
+	rule, err := cr.RCategorizationRules.GetByID(ctx, ruleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get categorization rule by id: %v", err)
+	}
+	return budgetID == rule.BudgetID, nil
+}
+
+func (cr *CategorizationRules) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return cr.RCategorizationRules.ExistsByID(ctx, id)
+}
+
+func (cr *CategorizationRules) GetAll(ctx context.Context, budgetID string) ([]*models.CategorizationRule, error) {
+	return cr.RCategorizationRules.GetAllByBudgetID(ctx, budgetID)
+}
+
+func (cr *CategorizationRules) GetByID(ctx context.Context, id string) (*models.CategorizationRule, error) {
+	return cr.RCategorizationRules.GetByID(ctx, id)
+}
+
+func (cr *CategorizationRules) Create(ctx context.Context, budgetID, categoryID string, payeeContains *string, amountMin, amountMax *int64, priority int) (*models.CategorizationRule, error) {
+	newRule := &models.CategorizationRule{
+		ID:            uuid.NewString(),
+		BudgetID:      budgetID,
+		CategoryID:    categoryID,
+		PayeeContains: payeeContains,
+		AmountMin:     amountMin,
+		AmountMax:     amountMax,
+		Priority:      priority,
+	}
+	if !ruleIsValid(newRule) {
+		return nil, constants.ErrInvalidCategorizationRule
+	}
+
+	err := cr.RCategorizationRules.Create(ctx, newRule)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := cr.ExistsByID(ctx, newRule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("categorization rule failed post-creation existence check")
+	}
+	return cr.RCategorizationRules.GetByID(ctx, newRule.ID)
+}
+
+func (cr *CategorizationRules) Update(ctx context.Context, rule *models.CategorizationRule) (*models.CategorizationRule, error) {
+	if !ruleIsValid(rule) {
+		return nil, constants.ErrInvalidCategorizationRule
+	}
+
+	err := cr.RCategorizationRules.Update(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	return cr.RCategorizationRules.GetByID(ctx, rule.ID)
+}
+
+func (cr *CategorizationRules) Delete(ctx context.Context, id string) error {
+	return cr.RCategorizationRules.DeleteByID(ctx, id)
+}
+
+// ruleIsValid reports whether the rule has at least one condition and a
+// sensible amount range. A blank payee_contains would match every payee, so
+// it does not count as a condition.
+func ruleIsValid(rule *models.CategorizationRule) bool {
+	if rule.PayeeContains != nil && strings.TrimSpace(*rule.PayeeContains) == "" {
+		return false
+	}
+	if rule.PayeeContains == nil && rule.AmountMin == nil && rule.AmountMax == nil {
+		return false
+	}
+	if rule.AmountMin != nil && rule.AmountMax != nil && *rule.AmountMin > *rule.AmountMax {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type ICategoryGroups interface {
+	BelongsTo(ctx context.Context, budgetID, categoryGroupID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error)
+	GetAll(ctx context.Context, budgetID string) ([]*models.CategoryGroup, error)
+	GetByID(ctx context.Context, id string) (*models.CategoryGroup, error)
+	Create(ctx context.Context, budgetID, name string) (*models.CategoryGroup, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type CategoryGroups struct {
+	Repository repositories.ICategoryGroups
+}
+
+func (cg *CategoryGroups) BelongsTo(ctx context.Context, budgetID, categoryGroupID string) (bool, error) {
+	categoryGroup, err := cg.Repository.GetByID(ctx, categoryGroupID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get category group by id: %v", err)
+	}
+	return budgetID == categoryGroup.BudgetID, nil
+}
+
+func (cg *CategoryGroups) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return cg.Repository.ExistsByID(ctx, id)
+}
+
+func (cg *CategoryGroups) ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error) {
+	return cg.Repository.ExistsByBudgetIDAndName(ctx, budgetID, name)
+}
+
+func (cg *CategoryGroups) GetAll(ctx context.Context, budgetID string) ([]*models.CategoryGroup, error) {
+	return cg.Repository.GetAllByBudgetID(ctx, budgetID)
+}
+
+func (cg *CategoryGroups) GetByID(ctx context.Context, id string) (*models.CategoryGroup, error) {
+	return cg.Repository.GetByID(ctx, id)
+}
+
+func (cg *CategoryGroups) Create(ctx context.Context, budgetID, name string) (*models.CategoryGroup, error) {
+	newCategoryGroup := &models.CategoryGroup{
+		ID:       uuid.NewString(),
+		BudgetID: budgetID,
+		Name:     name,
+	}
+	err := cg.Repository.Create(ctx, newCategoryGroup)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := cg.ExistsByID(ctx, newCategoryGroup.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("category group failed post-creation existence check")
+	}
+	return cg.Repository.GetByID(ctx, newCategoryGroup.ID)
+}
+
+func (cg *CategoryGroups) Delete(ctx context.Context, id string) error {
+	return cg.Repository.DeleteByID(ctx, id)
+}
@@ -0,0 +1,93 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/currency"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IExchangeRates interface {
+	Import(ctx context.Context, rates []*models.ExchangeRate) error
+	GetRate(ctx context.Context, from, to string, asOf time.Time) (*big.Rat, error)
+	Convert(ctx context.Context, amount int64, from, to string, asOf time.Time) (int64, error)
+}
+
+type ExchangeRates struct {
+	RExchangeRates repositories.IExchangeRates
+}
+
+// Import records the rates, replacing any already recorded for the same
+// currencies and dates. Where the same rate appears more than once, the last
+// one wins.
+func (er *ExchangeRates) Import(ctx context.Context, rates []*models.ExchangeRate) error {
+	type rateKey struct {
+		base  string
+		quote string
+		date  time.Time
+	}
+
+	indexes := map[rateKey]int{}
+	deduplicated := []*models.ExchangeRate{}
+	for _, rate := range rates {
+		if !currency.Valid(rate.Base) || !currency.Valid(rate.Quote) {
+			return constants.ErrInvalidCurrency
+		}
+
+		key := rateKey{rate.Base, rate.Quote, rate.Date}
+		if index, ok := indexes[key]; ok {
+			deduplicated[index] = rate
+			continue
+		}
+		indexes[key] = len(deduplicated)
+		deduplicated = append(deduplicated, rate)
+	}
+
+	err := er.RExchangeRates.UpsertAll(ctx, deduplicated)
+	if err != nil {
+		return fmt.Errorf("error saving exchange rates: %w", err)
+	}
+	return nil
+}
+
+// GetRate gets the price of one unit of from in units of to, using the most
+// recent rate dated on or before asOf. A rate quoted the other way round is
+// inverted. Returns ErrNoExchangeRate if there is no such rate.
+func (er *ExchangeRates) GetRate(ctx context.Context, from, to string, asOf time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	exists, err := er.RExchangeRates.ExistsByCurrencies(ctx, from, to, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("error checking for exchange rate: %w", err)
+	}
+	if !exists {
+		return nil, constants.ErrNoExchangeRate
+	}
+	rate, err := er.RExchangeRates.GetLatestByCurrencies(ctx, from, to, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("error getting exchange rate: %w", err)
+	}
+
+	if rate.Base == from {
+		return rate.Rate, nil
+	}
+	return new(big.Rat).Inv(rate.Rate), nil
+}
+
+// Convert converts amount, in minor units of from, into minor units of to
+func (er *ExchangeRates) Convert(ctx context.Context, amount int64, from, to string, asOf time.Time) (int64, error) {
+	rate, err := er.GetRate(ctx, from, to, asOf)
+	if err != nil {
+		return 0, err
+	}
+	return currency.Convert(amount, from, to, rate), nil
+}
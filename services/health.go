@@ -0,0 +1,109 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"crypto/rsa"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/migrations"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	log "github.com/sirupsen/logrus"
+)
+
+// unavailableMessage stands in for the errors of failed checks, which are
+// logged instead, since readiness is reported to anyone who asks
+const unavailableMessage = "unavailable"
+
+type IHealth interface {
+	Check(ctx context.Context) *models.Health
+}
+
+type Health struct {
+	RHealth    repositories.IHealth
+	PrivateKey *rsa.PrivateKey
+	// IsDraining reports whether the server is shutting down
+	IsDraining func() bool
+}
+
+// Check reports whether the server is ready for traffic: it must not be
+// shutting down, the database must be reachable and fully migrated, and the
+// key for signing tokens must be loaded
+func (h *Health) Check(ctx context.Context) *models.Health {
+	health := &models.Health{
+		Draining:   h.IsDraining(),
+		Database:   h.checkDatabase(ctx),
+		JWTKey:     h.checkJWTKey(),
+		Migrations: h.checkMigrations(ctx),
+	}
+
+	health.Status = models.HealthStatusUp
+	if health.Draining ||
+		health.Database.Status != models.HealthStatusUp ||
+		health.JWTKey.Status != models.HealthStatusUp ||
+		health.Migrations.Status != models.HealthStatusUp {
+		health.Status = models.HealthStatusDown
+	}
+	return health
+}
+
+func (h *Health) checkDatabase(ctx context.Context) *models.DatabaseHealth {
+	ctx, cancel := context.WithTimeout(ctx, constants.HealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.RHealth.Ping(ctx)
+	databaseHealth := &models.DatabaseHealth{
+		Status:  models.HealthStatusUp,
+		Latency: time.Since(start),
+		Pool:    h.RHealth.GetPool(),
+	}
+	if err != nil {
+		log.WithError(err).Error("Error pinging database")
+		message := unavailableMessage
+		databaseHealth.Status = models.HealthStatusDown
+		databaseHealth.Error = &message
+	}
+	return databaseHealth
+}
+
+func (h *Health) checkJWTKey() *models.JWTKeyHealth {
+	if h.PrivateKey == nil {
+		return &models.JWTKeyHealth{Status: models.HealthStatusDown}
+	}
+	return &models.JWTKeyHealth{Status: models.HealthStatusUp}
+}
+
+func (h *Health) checkMigrations(ctx context.Context) *models.MigrationsHealth {
+	ctx, cancel := context.WithTimeout(ctx, constants.HealthCheckTimeout)
+	defer cancel()
+
+	migrationsHealth := &models.MigrationsHealth{Status: models.HealthStatusDown}
+	latestVersion, err := migrations.Latest()
+	if err != nil {
+		log.WithError(err).Error("Error getting latest migration version")
+		message := unavailableMessage
+		migrationsHealth.Error = &message
+		return migrationsHealth
+	}
+	migrationsHealth.LatestVersion = latestVersion
+
+	currentVersion, err := h.RHealth.GetMigrationVersion(ctx)
+	if err != nil {
+		log.WithError(err).Error("Error getting current migration version")
+		message := unavailableMessage
+		migrationsHealth.Error = &message
+		return migrationsHealth
+	}
+	migrationsHealth.CurrentVersion = currentVersion
+
+	// a newer server may already have migrated further, which this one can
+	// live with until it is replaced
+	if currentVersion >= latestVersion {
+		migrationsHealth.Status = models.HealthStatusUp
+	}
+	return migrationsHealth
+}
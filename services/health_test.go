@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheckHidesErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockHealthRepository := mockrepositories.NewMockIHealth(mockCtrl)
+
+	internalErr := errors.New("failed to connect to `host=db.internal user=moneybags database=moneybags`")
+	mockHealthRepository.EXPECT().
+		Ping(gomock.Any()).
+		Times(1).
+		Return(internalErr)
+	mockHealthRepository.EXPECT().
+		GetPool().
+		Times(1).
+		Return(&models.DatabasePool{})
+	mockHealthRepository.EXPECT().
+		GetMigrationVersion(gomock.Any()).
+		Times(1).
+		Return(0, internalErr)
+
+	h := &services.Health{
+		RHealth:    mockHealthRepository,
+		IsDraining: func() bool { return false },
+	}
+
+	health := h.Check(context.Background())
+
+	assert.Equal(t, models.HealthStatusDown, health.Status)
+	if assert.NotNil(t, health.Database.Error) {
+		assert.Equal(t, "unavailable", *health.Database.Error)
+	}
+	if assert.NotNil(t, health.Migrations.Error) {
+		assert.Equal(t, "unavailable", *health.Migrations.Error)
+	}
+}
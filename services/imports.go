@@ -0,0 +1,206 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/currency"
+	"github.com/paulwrubel/moneybags-server/importers"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IImports interface {
+	MinorUnits(ctx context.Context, bankAccountID string) (int, error)
+	FindDuplicates(ctx context.Context, bankAccountID string, entries []*importers.Entry) ([]bool, error)
+	Import(ctx context.Context, bankAccountID string, entries []*importers.Entry) ([]*models.Transaction, []*importers.Entry, error)
+	SaveStatementBalance(ctx context.Context, bankAccountID string, balance *importers.Balance) (*models.StatementBalance, error)
+}
+
+type Imports struct {
+	UnitOfWork         repositories.IUnitOfWork
+	RTransactions      repositories.ITransactions
+	RStatementBalances repositories.IStatementBalances
+	RBankAccounts      repositories.IBankAccounts
+}
+
+// MinorUnits returns the number of decimal places of the bank account's
+// currency, which statement amounts are parsed to
+func (i *Imports) MinorUnits(ctx context.Context, bankAccountID string) (int, error) {
+	bankAccount, err := i.RBankAccounts.GetByID(ctx, bankAccountID)
+	if err != nil {
+		return 0, fmt.Errorf("error getting bank account: %w", err)
+	}
+	return currency.MinorUnits(bankAccount.Currency), nil
+}
+
+// FindDuplicates reports, for each entry, whether it is already in the bank
+// account. Entries with a FITID are duplicates of transactions imported with
+// the same FITID. Otherwise entries match transactions on date, amount and
+// payee, although an entry with a FITID only matches a transaction without
+// one, such as one entered by hand. Each existing transaction only matches
+// one entry, so an export containing two identical purchases is not
+// collapsed into one.
+func (i *Imports) FindDuplicates(ctx context.Context, bankAccountID string, entries []*importers.Entry) ([]bool, error) {
+	duplicates := make([]bool, len(entries))
+	if len(entries) == 0 {
+		return duplicates, nil
+	}
+
+	from, to := entries[0].Date, entries[0].Date
+	for _, entry := range entries {
+		if entry.Date.Before(from) {
+			from = entry.Date
+		}
+		if entry.Date.After(to) {
+			to = entry.Date
+		}
+	}
+	existing, err := i.RTransactions.GetAllByBankAccountIDAndDateRange(ctx, bankAccountID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error getting existing transactions: %w", err)
+	}
+
+	seenFITIDs := map[string]bool{}
+	unmatchedWithoutFITID := map[duplicateKey]int{}
+	unmatchedWithFITID := map[duplicateKey]int{}
+	for _, transaction := range existing {
+		key := newDuplicateKey(transaction.Date, transaction.Amount, transaction.Payee)
+		if transaction.FITID != nil {
+			seenFITIDs[*transaction.FITID] = true
+			unmatchedWithFITID[key]++
+		} else {
+			unmatchedWithoutFITID[key]++
+		}
+	}
+	for index, entry := range entries {
+		key := newDuplicateKey(entry.Date, entry.Amount, entry.Payee)
+		switch {
+		case entry.FITID != nil && seenFITIDs[*entry.FITID]:
+			duplicates[index] = true
+		case unmatchedWithoutFITID[key] > 0:
+			unmatchedWithoutFITID[key]--
+			duplicates[index] = true
+		case entry.FITID == nil && unmatchedWithFITID[key] > 0:
+			unmatchedWithFITID[key]--
+			duplicates[index] = true
+		}
+		if entry.FITID != nil {
+			seenFITIDs[*entry.FITID] = true
+		}
+	}
+
+	return duplicates, nil
+}
+
+// Import creates a cleared transaction for every entry which is not a
+// duplicate, returning the created transactions and the skipped entries.
+// Payees are registered with the budget and the transactions categorized by
+// its rules.
+func (i *Imports) Import(ctx context.Context, bankAccountID string, entries []*importers.Entry) ([]*models.Transaction, []*importers.Entry, error) {
+	duplicates, err := i.FindDuplicates(ctx, bankAccountID, entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	bankAccount, err := i.RBankAccounts.GetByID(ctx, bankAccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting bank account: %w", err)
+	}
+
+	// the transactions and their payees are created together, so a failed
+	// import can simply be retried without finding half of it already
+	// imported
+	var created []*models.Transaction
+	var skipped []*importers.Entry
+	err = i.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		created = []*models.Transaction{}
+		skipped = []*importers.Entry{}
+		for index, entry := range entries {
+			if duplicates[index] {
+				skipped = append(skipped, entry)
+				continue
+			}
+
+			payee, categoryID, err := categorize(ctx, repos, bankAccount.BudgetID, entry.Payee, entry.Amount)
+			if err != nil {
+				return fmt.Errorf("error categorizing transaction: %w", err)
+			}
+
+			newTransaction := &models.Transaction{
+				ID:            uuid.NewString(),
+				BankAccountID: bankAccountID,
+				CategoryID:    categoryID,
+				Date:          entry.Date,
+				Amount:        entry.Amount,
+				Payee:         entry.Payee,
+				Memo:          entry.Memo,
+				Cleared:       models.ClearedStateCleared,
+				FITID:         entry.FITID,
+			}
+			if payee != nil {
+				newTransaction.PayeeID = &payee.ID
+			}
+
+			err = repos.Transactions.Create(ctx, newTransaction)
+			if err != nil {
+				return fmt.Errorf("error creating transaction: %w", err)
+			}
+			exists, err := repos.Transactions.ExistsByID(ctx, newTransaction.ID)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return errors.New("transaction failed post-creation existence check")
+			}
+			createdTransaction, err := repos.Transactions.GetByID(ctx, newTransaction.ID)
+			if err != nil {
+				return err
+			}
+			created = append(created, createdTransaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return created, skipped, nil
+}
+
+type duplicateKey struct {
+	date   string
+	amount int64
+	payee  string
+}
+
+func newDuplicateKey(date time.Time, amount int64, payee string) duplicateKey {
+	return duplicateKey{
+		date:   date.Format(constants.DateLayout),
+		amount: amount,
+		payee:  strings.ToLower(strings.Join(strings.Fields(payee), " ")),
+	}
+}
+
+// SaveStatementBalance records the ledger balance from an imported
+// statement, for use when reconciling the account
+func (i *Imports) SaveStatementBalance(ctx context.Context, bankAccountID string, balance *importers.Balance) (*models.StatementBalance, error) {
+	statementBalance := &models.StatementBalance{
+		ID:            uuid.NewString(),
+		BankAccountID: bankAccountID,
+		Date:          balance.Date,
+		Balance:       balance.Amount,
+	}
+	err := i.RStatementBalances.Upsert(ctx, statementBalance)
+	if err != nil {
+		return nil, fmt.Errorf("error saving statement balance: %w", err)
+	}
+
+	return statementBalance, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/currency"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IMonths interface {
+	Get(ctx context.Context, budgetID string, month time.Time) (*models.BudgetMonth, error)
+	SetAssigned(ctx context.Context, categoryID string, month time.Time, assigned int64) error
+}
+
+type Months struct {
+	RBudgets             repositories.IBudgets
+	RCategories          repositories.ICategories
+	RCategoryAllocations repositories.ICategoryAllocations
+	RTransactions        repositories.ITransactions
+	SExchangeRates       IExchangeRates
+}
+
+// Get calculates the budget figures for the given month.
+//
+// A category's available balance rolls over from month to month, so it is
+// the sum of everything ever assigned to it plus all of its activity up to
+// the end of the month. Uncategorized transactions are treated as income,
+// and whatever part of that income has not been assigned to a category
+// through the end of the month is available to budget.
+//
+// Transactions in bank accounts held in other currencies are converted into
+// the budget's currency at the rates as of the last day of the month.
+// Returns ErrNoExchangeRate if any of the rates are missing.
+func (m *Months) Get(ctx context.Context, budgetID string, month time.Time) (*models.BudgetMonth, error) {
+	monthStart := startOfMonth(month)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	budget, err := m.RBudgets.GetByID(ctx, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting budget: %w", err)
+	}
+	categories, err := m.RCategories.GetAllByBudgetID(ctx, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting categories: %w", err)
+	}
+	assigned, err := m.RCategoryAllocations.GetAssignedByBudgetID(ctx, budgetID, monthStart, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting assigned amounts: %w", err)
+	}
+	totalAssigned, err := m.RCategoryAllocations.GetAssignedByBudgetID(ctx, budgetID, time.Time{}, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting total assigned amounts: %w", err)
+	}
+	activity, err := m.RTransactions.GetActivityByBudgetID(ctx, budgetID, monthStart, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting activity: %w", err)
+	}
+	totalActivity, err := m.RTransactions.GetActivityByBudgetID(ctx, budgetID, time.Time{}, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting total activity: %w", err)
+	}
+	income, err := m.RTransactions.GetUncategorizedTotalByBudgetID(ctx, budgetID, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting income: %w", err)
+	}
+
+	rates := map[string]*big.Rat{}
+	asOf := monthEnd.AddDate(0, 0, -1)
+	availableToBudget, err := m.sumInCurrency(ctx, income, budget.Currency, asOf, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetMonth := &models.BudgetMonth{
+		BudgetID:          budgetID,
+		Month:             monthStart,
+		AvailableToBudget: availableToBudget,
+		Categories:        []*models.CategoryMonth{},
+	}
+	for _, category := range categories {
+		categoryActivity, err := m.sumInCurrency(ctx, activity[category.ID], budget.Currency, asOf, rates)
+		if err != nil {
+			return nil, err
+		}
+		categoryTotalActivity, err := m.sumInCurrency(ctx, totalActivity[category.ID], budget.Currency, asOf, rates)
+		if err != nil {
+			return nil, err
+		}
+
+		budgetMonth.Categories = append(budgetMonth.Categories, &models.CategoryMonth{
+			CategoryID: category.ID,
+			Assigned:   assigned[category.ID],
+			Activity:   categoryActivity,
+			Available:  totalAssigned[category.ID] + categoryTotalActivity,
+		})
+	}
+	for _, amount := range totalAssigned {
+		budgetMonth.AvailableToBudget -= amount
+	}
+
+	return budgetMonth, nil
+}
+
+func (m *Months) SetAssigned(ctx context.Context, categoryID string, month time.Time, assigned int64) error {
+	return m.RCategoryAllocations.Upsert(ctx, &models.CategoryAllocation{
+		CategoryID: categoryID,
+		Month:      startOfMonth(month),
+		Assigned:   assigned,
+	})
+}
+
+// sumInCurrency converts amounts keyed by currency into budgetCurrency and
+// sums them. Rates are looked up once and kept in rates, keyed by currency.
+func (m *Months) sumInCurrency(ctx context.Context, amounts map[string]int64, budgetCurrency string, asOf time.Time, rates map[string]*big.Rat) (int64, error) {
+	var total int64
+	for code, amount := range amounts {
+		rate, ok := rates[code]
+		if !ok {
+			var err error
+			rate, err = m.SExchangeRates.GetRate(ctx, code, budgetCurrency, asOf)
+			if err != nil {
+				return 0, err
+			}
+			rates[code] = rate
+		}
+		total += currency.Convert(amount, code, budgetCurrency, rate)
+	}
+
+	return total, nil
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
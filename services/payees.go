@@ -0,0 +1,174 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IPayees interface {
+	BelongsTo(ctx context.Context, budgetID, payeeID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error)
+	GetAll(ctx context.Context, budgetID string) ([]*models.Payee, error)
+	GetByID(ctx context.Context, id string) (*models.Payee, error)
+	Create(ctx context.Context, budgetID, name string, defaultCategoryID *string) (*models.Payee, error)
+	Update(ctx context.Context, payee *models.Payee) (*models.Payee, error)
+	Merge(ctx context.Context, sourceID, targetID string) (*models.Payee, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Payees struct {
+	RPayees repositories.IPayees
+}
+
+func (p *Payees) BelongsTo(ctx context.Context, budgetID, payeeID string) (bool, error) {
+	payee, err := p.RPayees.GetByID(ctx, payeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get payee by id: %v", err)
+	}
+	return budgetID == payee.BudgetID, nil
+}
+
+func (p *Payees) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return p.RPayees.ExistsByID(ctx, id)
+}
+
+func (p *Payees) ExistsByBudgetIDAndName(ctx context.Context, budgetID, name string) (bool, error) {
+	return p.RPayees.ExistsByBudgetIDAndName(ctx, budgetID, name)
+}
+
+func (p *Payees) GetAll(ctx context.Context, budgetID string) ([]*models.Payee, error) {
+	return p.RPayees.GetAllByBudgetID(ctx, budgetID)
+}
+
+func (p *Payees) GetByID(ctx context.Context, id string) (*models.Payee, error) {
+	return p.RPayees.GetByID(ctx, id)
+}
+
+func (p *Payees) Create(ctx context.Context, budgetID, name string, defaultCategoryID *string) (*models.Payee, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, constants.ErrInvalidPayeeName
+	}
+
+	newPayee := &models.Payee{
+		ID:                uuid.NewString(),
+		BudgetID:          budgetID,
+		Name:              name,
+		DefaultCategoryID: defaultCategoryID,
+	}
+	err := p.RPayees.Create(ctx, newPayee)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := p.ExistsByID(ctx, newPayee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("payee failed post-creation existence check")
+	}
+	return p.RPayees.GetByID(ctx, newPayee.ID)
+}
+
+// Update saves changes to a payee. Renaming a payee renames its transactions
+// too.
+func (p *Payees) Update(ctx context.Context, payee *models.Payee) (*models.Payee, error) {
+	payee.Name = strings.TrimSpace(payee.Name)
+	if payee.Name == "" {
+		return nil, constants.ErrInvalidPayeeName
+	}
+
+	err := p.RPayees.Update(ctx, payee)
+	if err != nil {
+		return nil, err
+	}
+	return p.RPayees.GetByID(ctx, payee.ID)
+}
+
+// Merge moves every transaction of the source payee to the target payee and
+// deletes the source, returning the target
+func (p *Payees) Merge(ctx context.Context, sourceID, targetID string) (*models.Payee, error) {
+	if sourceID == targetID {
+		return nil, constants.ErrInvalidPayeeMerge
+	}
+
+	err := p.RPayees.Merge(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("error merging payees: %w", err)
+	}
+	return p.RPayees.GetByID(ctx, targetID)
+}
+
+func (p *Payees) Delete(ctx context.Context, id string) error {
+	return p.RPayees.DeleteByID(ctx, id)
+}
+
+// getOrCreatePayee gets the budget's payee with the given name, creating it
+// if it is new to the budget. It takes the repository so that callers can
+// register payees in the same unit of work as the transaction using them.
+func getOrCreatePayee(ctx context.Context, rPayees repositories.IPayees, budgetID, name string) (*models.Payee, error) {
+	payee, err := rPayees.GetOrCreateByBudgetIDAndName(ctx, &models.Payee{
+		ID:       uuid.NewString(),
+		BudgetID: budgetID,
+		Name:     strings.TrimSpace(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error registering payee: %w", err)
+	}
+
+	return payee, nil
+}
+
+// categorize registers the payee of a new transaction and picks a category
+// for it. The category of the first of the budget's rules to match is used,
+// falling back to the payee's default category. A blank name has no payee,
+// but may still match rules on amount alone.
+func categorize(ctx context.Context, repos *repositories.Repositories, budgetID, name string, amount int64) (*models.Payee, *string, error) {
+	var payee *models.Payee
+	if strings.TrimSpace(name) != "" {
+		var err error
+		payee, err = getOrCreatePayee(ctx, repos.Payees, budgetID, name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rules, err := repos.CategorizationRules.GetAllByBudgetID(ctx, budgetID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting categorization rules: %w", err)
+	}
+	for _, rule := range rules {
+		if ruleMatches(rule, name, amount) {
+			categoryID := rule.CategoryID
+			return payee, &categoryID, nil
+		}
+	}
+
+	if payee != nil {
+		return payee, payee.DefaultCategoryID, nil
+	}
+	return nil, nil, nil
+}
+
+func ruleMatches(rule *models.CategorizationRule, name string, amount int64) bool {
+	if rule.PayeeContains != nil && !strings.Contains(strings.ToLower(name), strings.ToLower(*rule.PayeeContains)) {
+		return false
+	}
+	if rule.AmountMin != nil && amount < *rule.AmountMin {
+		return false
+	}
+	if rule.AmountMax != nil && amount > *rule.AmountMax {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IReconciliations interface {
+	GetLatestStatementBalance(ctx context.Context, bankAccountID string) (*models.StatementBalance, error)
+	Preview(ctx context.Context, bankAccountID string, statementDate time.Time, statementBalance int64) (*models.Reconciliation, error)
+	Commit(ctx context.Context, bankAccountID string, statementDate time.Time, statementBalance int64, createAdjustment bool) (*models.Reconciliation, error)
+}
+
+type Reconciliations struct {
+	UnitOfWork         repositories.IUnitOfWork
+	RTransactions      repositories.ITransactions
+	RStatementBalances repositories.IStatementBalances
+}
+
+// GetLatestStatementBalance gets the most recent statement balance recorded
+// by an import, or ErrNoStatementBalance if there are none
+func (r *Reconciliations) GetLatestStatementBalance(ctx context.Context, bankAccountID string) (*models.StatementBalance, error) {
+	exists, err := r.RStatementBalances.ExistsByBankAccountID(ctx, bankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking if statement balance exists: %w", err)
+	}
+	if !exists {
+		return nil, constants.ErrNoStatementBalance
+	}
+
+	return r.RStatementBalances.GetLatestByBankAccountID(ctx, bankAccountID)
+}
+
+// Preview compares the cleared balance of the bank account as of the
+// statement date to the statement balance, without changing anything
+func (r *Reconciliations) Preview(ctx context.Context, bankAccountID string, statementDate time.Time, statementBalance int64) (*models.Reconciliation, error) {
+	return previewReconciliation(ctx, r.RTransactions, bankAccountID, statementDate, statementBalance)
+}
+
+// Commit locks the cleared transactions dated on or before the statement date
+// as reconciled. If the cleared balance does not match the statement balance,
+// the reconciliation is refused with ErrReconciliationUnbalanced unless
+// createAdjustment is set, in which case an uncategorized transaction for
+// the difference is added and reconciled along with the rest.
+func (r *Reconciliations) Commit(ctx context.Context, bankAccountID string, statementDate time.Time, statementBalance int64, createAdjustment bool) (*models.Reconciliation, error) {
+	var reconciliation *models.Reconciliation
+	err := r.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		var err error
+		reconciliation, err = previewReconciliation(ctx, repos.Transactions, bankAccountID, statementDate, statementBalance)
+		if err != nil {
+			return err
+		}
+
+		if reconciliation.Difference != 0 {
+			if !createAdjustment {
+				return constants.ErrReconciliationUnbalanced
+			}
+
+			adjustment := &models.Transaction{
+				ID:            uuid.NewString(),
+				BankAccountID: bankAccountID,
+				Date:          statementDate,
+				Amount:        reconciliation.Difference,
+				Payee:         constants.ReconciliationAdjustmentPayee,
+				Cleared:       models.ClearedStateCleared,
+			}
+			err := repos.Transactions.Create(ctx, adjustment)
+			if err != nil {
+				return fmt.Errorf("error creating adjustment transaction: %w", err)
+			}
+			reconciliation.Adjustment = adjustment
+			reconciliation.Transactions = append(reconciliation.Transactions, adjustment)
+			reconciliation.ClearedBalance += adjustment.Amount
+			reconciliation.Difference = 0
+		}
+
+		err = repos.Transactions.ReconcileByBankAccountID(ctx, bankAccountID, statementDate)
+		if err != nil {
+			return fmt.Errorf("error reconciling transactions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range reconciliation.Transactions {
+		transaction.Cleared = models.ClearedStateReconciled
+	}
+	return reconciliation, nil
+}
+
+func previewReconciliation(ctx context.Context, rTransactions repositories.ITransactions, bankAccountID string, statementDate time.Time, statementBalance int64) (*models.Reconciliation, error) {
+	clearedBalance, err := rTransactions.GetClearedBalanceByBankAccountID(ctx, bankAccountID, statementDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cleared balance: %w", err)
+	}
+	transactions, err := rTransactions.GetAllClearedByBankAccountID(ctx, bankAccountID, statementDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cleared transactions: %w", err)
+	}
+
+	return &models.Reconciliation{
+		BankAccountID:    bankAccountID,
+		StatementDate:    statementDate,
+		StatementBalance: statementBalance,
+		ClearedBalance:   clearedBalance,
+		Difference:       statementBalance - clearedBalance,
+		Transactions:     transactions,
+	}, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/recurrence"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type IScheduledTransactions interface {
+	BelongsTo(ctx context.Context, bankAccountID, scheduledTransactionID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetAll(ctx context.Context, bankAccountID string) ([]*models.ScheduledTransaction, error)
+	GetByID(ctx context.Context, id string) (*models.ScheduledTransaction, error)
+	GetUpcoming(ctx context.Context, bankAccountID string, from, to time.Time) ([]*models.ScheduledOccurrence, error)
+	Create(ctx context.Context, bankAccountID string, categoryID *string, amount int64, payee string, memo *string, frequency models.RecurrenceFrequency, interval int, dayOfMonth *int, startDate time.Time, endDate *time.Time) (*models.ScheduledTransaction, error)
+	Update(ctx context.Context, scheduledTransaction *models.ScheduledTransaction) (*models.ScheduledTransaction, error)
+	Delete(ctx context.Context, id string) error
+	MaterializeDue(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type ScheduledTransactions struct {
+	UnitOfWork             repositories.IUnitOfWork
+	RScheduledTransactions repositories.IScheduledTransactions
+}
+
+func (st *ScheduledTransactions) BelongsTo(ctx context.Context, bankAccountID, scheduledTransactionID string) (bool, error) {
+	scheduledTransaction, err := st.RScheduledTransactions.GetByID(ctx, scheduledTransactionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get scheduled transaction by id: %v", err)
+	}
+	return bankAccountID == scheduledTransaction.BankAccountID, nil
+}
+
+func (st *ScheduledTransactions) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return st.RScheduledTransactions.ExistsByID(ctx, id)
+}
+
+func (st *ScheduledTransactions) GetAll(ctx context.Context, bankAccountID string) ([]*models.ScheduledTransaction, error) {
+	return st.RScheduledTransactions.GetAllByBankAccountID(ctx, bankAccountID)
+}
+
+func (st *ScheduledTransactions) GetByID(ctx context.Context, id string) (*models.ScheduledTransaction, error) {
+	return st.RScheduledTransactions.GetByID(ctx, id)
+}
+
+// GetUpcoming lists the occurrences of the bank account's scheduled
+// transactions within the closed range [from, to] which have not been
+// materialized yet, ordered by date
+func (st *ScheduledTransactions) GetUpcoming(ctx context.Context, bankAccountID string, from, to time.Time) ([]*models.ScheduledOccurrence, error) {
+	scheduledTransactions, err := st.RScheduledTransactions.GetAllByBankAccountID(ctx, bankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scheduled transactions: %w", err)
+	}
+
+	occurrences := []*models.ScheduledOccurrence{}
+	for _, scheduledTransaction := range scheduledTransactions {
+		if scheduledTransaction.NextDate == nil {
+			continue
+		}
+		start := from
+		if scheduledTransaction.NextDate.After(start) {
+			start = *scheduledTransaction.NextDate
+		}
+		for _, date := range ruleFor(scheduledTransaction).Between(start, to) {
+			occurrences = append(occurrences, &models.ScheduledOccurrence{
+				ScheduledTransaction: scheduledTransaction,
+				Date:                 date,
+			})
+		}
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date.Before(occurrences[j].Date)
+	})
+
+	return occurrences, nil
+}
+
+// Create adds a scheduled transaction. Occurrences from the start date
+// onwards are materialized by MaterializeDue, so a start date in the past
+// is caught up on the next run.
+func (st *ScheduledTransactions) Create(ctx context.Context, bankAccountID string, categoryID *string, amount int64, payee string, memo *string, frequency models.RecurrenceFrequency, interval int, dayOfMonth *int, startDate time.Time, endDate *time.Time) (*models.ScheduledTransaction, error) {
+	newScheduledTransaction := &models.ScheduledTransaction{
+		ID:            uuid.NewString(),
+		BankAccountID: bankAccountID,
+		CategoryID:    categoryID,
+		Amount:        amount,
+		Payee:         payee,
+		Memo:          memo,
+		Frequency:     frequency,
+		Interval:      interval,
+		DayOfMonth:    dayOfMonth,
+		StartDate:     startDate,
+		EndDate:       endDate,
+	}
+	rule := ruleFor(newScheduledTransaction)
+	if !rule.Valid() {
+		return nil, constants.ErrInvalidRecurrenceRule
+	}
+	newScheduledTransaction.NextDate = nextDate(rule, startDate.AddDate(0, 0, -1))
+
+	err := st.RScheduledTransactions.Create(ctx, newScheduledTransaction)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := st.ExistsByID(ctx, newScheduledTransaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("scheduled transaction failed post-creation existence check")
+	}
+	return st.RScheduledTransactions.GetByID(ctx, newScheduledTransaction.ID)
+}
+
+// Update saves changes to a scheduled transaction. Changing the schedule
+// only affects occurrences from today onwards; nothing in the past is
+// created or removed.
+func (st *ScheduledTransactions) Update(ctx context.Context, scheduledTransaction *models.ScheduledTransaction) (*models.ScheduledTransaction, error) {
+	rule := ruleFor(scheduledTransaction)
+	if !rule.Valid() {
+		return nil, constants.ErrInvalidRecurrenceRule
+	}
+	after := scheduledTransaction.StartDate.AddDate(0, 0, -1)
+	yesterday := today().AddDate(0, 0, -1)
+	if yesterday.After(after) {
+		after = yesterday
+	}
+	scheduledTransaction.NextDate = nextDate(rule, after)
+
+	err := st.RScheduledTransactions.Update(ctx, scheduledTransaction)
+	if err != nil {
+		return nil, err
+	}
+	return st.RScheduledTransactions.GetByID(ctx, scheduledTransaction.ID)
+}
+
+func (st *ScheduledTransactions) Delete(ctx context.Context, id string) error {
+	return st.RScheduledTransactions.DeleteByID(ctx, id)
+}
+
+// MaterializeDue creates a transaction for every occurrence on or before
+// asOf which hasn't been created yet, including any missed while the server
+// was down, and returns how many were created.
+//
+// Each occurrence is recorded as it is created, so running this again, or
+// on several servers at once, never creates an occurrence twice. Failures
+// don't stop the remaining scheduled transactions from being processed;
+// the first is returned once all have been attempted.
+func (st *ScheduledTransactions) MaterializeDue(ctx context.Context, asOf time.Time) (int, error) {
+	scheduledTransactions, err := st.RScheduledTransactions.GetAllDue(ctx, asOf)
+	if err != nil {
+		return 0, fmt.Errorf("error getting due scheduled transactions: %w", err)
+	}
+
+	created := 0
+	var firstErr error
+	for _, scheduledTransaction := range scheduledTransactions {
+		count, err := st.materialize(ctx, scheduledTransaction, asOf)
+		created += count
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("error materializing scheduled transaction %s: %w", scheduledTransaction.ID, err)
+		}
+	}
+
+	return created, firstErr
+}
+
+// materialize creates the scheduled transaction's due occurrences and moves
+// its next date past them, all in one unit of work
+func (st *ScheduledTransactions) materialize(ctx context.Context, scheduledTransaction *models.ScheduledTransaction, asOf time.Time) (int, error) {
+	rule := ruleFor(scheduledTransaction)
+	if !rule.Valid() {
+		return 0, constants.ErrInvalidRecurrenceRule
+	}
+
+	created := 0
+	err := st.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		created = 0
+		next := scheduledTransaction.NextDate
+		for next != nil && !next.After(asOf) {
+			wasCreated, err := repos.ScheduledTransactions.CreateOccurrence(ctx, scheduledTransaction.ID, &models.Transaction{
+				ID:            uuid.NewString(),
+				BankAccountID: scheduledTransaction.BankAccountID,
+				CategoryID:    scheduledTransaction.CategoryID,
+				Date:          *next,
+				Amount:        scheduledTransaction.Amount,
+				Payee:         scheduledTransaction.Payee,
+				Memo:          scheduledTransaction.Memo,
+				Cleared:       models.ClearedStateUncleared,
+			})
+			if err != nil {
+				return err
+			}
+			if wasCreated {
+				created++
+			}
+			next = nextDate(rule, *next)
+		}
+
+		return repos.ScheduledTransactions.UpdateNextDate(ctx, scheduledTransaction.ID, next)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+func ruleFor(scheduledTransaction *models.ScheduledTransaction) *recurrence.Rule {
+	rule := &recurrence.Rule{
+		Frequency: scheduledTransaction.Frequency,
+		Interval:  scheduledTransaction.Interval,
+		Start:     scheduledTransaction.StartDate,
+		End:       scheduledTransaction.EndDate,
+	}
+	if scheduledTransaction.DayOfMonth != nil {
+		rule.DayOfMonth = *scheduledTransaction.DayOfMonth
+	}
+	return rule
+}
+
+// nextDate returns the rule's first occurrence after the given date, or nil
+// if it has ended
+func nextDate(rule *recurrence.Rule, after time.Time) *time.Time {
+	date, ok := rule.Next(after)
+	if !ok {
+		return nil
+	}
+	return &date
+}
+
+// today returns the current date in UTC, matching how dates are stored
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,356 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/paulwrubel/moneybags-server/totp"
+)
+
+type ITOTP interface {
+	IsEnabled(ctx context.Context, username string) (bool, error)
+	Enroll(ctx context.Context, username string) (string, string, error)
+	Enable(ctx context.Context, username, code string) ([]string, error)
+	Disable(ctx context.Context, username, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error)
+	ValidateCode(ctx context.Context, username, code string) error
+}
+
+type TOTP struct {
+	UnitOfWork      repositories.IUnitOfWork
+	UserAccounts    repositories.IUserAccounts
+	TOTPCredentials repositories.ITOTPCredentials
+	RecoveryCodes   repositories.IRecoveryCodes
+}
+
+func (t *TOTP) IsEnabled(ctx context.Context, username string) (bool, error) {
+	userAccount, err := t.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return false, fmt.Errorf("error getting user account: %w", err)
+	}
+
+	exists, err := t.TOTPCredentials.ExistsByUserAccountID(ctx, userAccount.ID)
+	if err != nil {
+		return false, fmt.Errorf("error checking if totp credential exists: %w", err)
+	}
+	if !exists {
+		return false, nil
+	}
+	totpCredential, err := t.TOTPCredentials.GetByUserAccountID(ctx, userAccount.ID)
+	if err != nil {
+		return false, fmt.Errorf("error getting totp credential: %w", err)
+	}
+
+	return totpCredential.Enabled, nil
+}
+
+// Enroll generates a new secret for the user, returning it along with its
+// otpauth URI. The secret is not used to log in until it has been confirmed
+// with Enable, and enrolling again before then replaces it.
+func (t *TOTP) Enroll(ctx context.Context, username string) (string, string, error) {
+	userAccount, err := t.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting user account: %w", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("error generating totp secret: %w", err)
+	}
+
+	exists, err := t.TOTPCredentials.ExistsByUserAccountID(ctx, userAccount.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("error checking if totp credential exists: %w", err)
+	}
+	if exists {
+		totpCredential, err := t.TOTPCredentials.GetByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return "", "", fmt.Errorf("error getting totp credential: %w", err)
+		}
+		if totpCredential.Enabled {
+			return "", "", constants.ErrTOTPAlreadyEnabled
+		}
+		totpCredential.Secret = secret
+		err = t.TOTPCredentials.Update(ctx, totpCredential)
+		if err != nil {
+			return "", "", fmt.Errorf("error updating totp credential: %w", err)
+		}
+	} else {
+		err = t.TOTPCredentials.Create(ctx, &models.TOTPCredential{
+			UserAccountID: userAccount.ID,
+			Secret:        secret,
+			Enabled:       false,
+			LastUsedStep:  0,
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("error creating totp credential: %w", err)
+		}
+	}
+
+	return secret, totp.URI(constants.TOTPIssuer, userAccount.Username, secret), nil
+}
+
+// Enable confirms a pending enrollment with a code from the authenticator
+// app, and returns a fresh set of recovery codes
+func (t *TOTP) Enable(ctx context.Context, username, code string) ([]string, error) {
+	userAccount, err := t.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user account: %w", err)
+	}
+
+	var codes []string
+	err = t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		exists, err := repos.TOTPCredentials.ExistsByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error checking if totp credential exists: %w", err)
+		}
+		if !exists {
+			return constants.ErrTOTPNotEnrolled
+		}
+		totpCredential, err := repos.TOTPCredentials.GetByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error getting totp credential: %w", err)
+		}
+		if totpCredential.Enabled {
+			return constants.ErrTOTPAlreadyEnabled
+		}
+
+		step, isValid, err := totp.Validate(totpCredential.Secret, code, time.Now(), constants.TOTPSkew)
+		if err != nil {
+			return fmt.Errorf("error validating totp code: %w", err)
+		}
+		if !isValid {
+			return constants.ErrInvalidTOTPCode
+		}
+
+		totpCredential.Enabled = true
+		totpCredential.LastUsedStep = step
+		err = repos.TOTPCredentials.Update(ctx, totpCredential)
+		if err != nil {
+			return fmt.Errorf("error updating totp credential: %w", err)
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, repos.RecoveryCodes, userAccount.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication. Both the password and a
+// current code (or an unused recovery code) are required, and wrong codes
+// count towards the lockout as they do when logging in.
+func (t *TOTP) Disable(ctx context.Context, username, password, code string) error {
+	userAccount, err := t.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("error getting user account: %w", err)
+	}
+
+	isValid, err := passwordIsValid(password, userAccount.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("error checking password validity: %w", err)
+	}
+	if !isValid {
+		return constants.ErrIncorrectPassword
+	}
+
+	err = t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		err := validateCode(ctx, repos.TOTPCredentials, repos.RecoveryCodes, userAccount.ID, code)
+		if err != nil {
+			return err
+		}
+
+		err = repos.RecoveryCodes.DeleteAllByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error deleting recovery codes: %w", err)
+		}
+		return repos.TOTPCredentials.DeleteByUserAccountID(ctx, userAccount.ID)
+	})
+	return t.recordFailedAttempt(ctx, userAccount.ID, err)
+}
+
+// RegenerateRecoveryCodes invalidates all existing recovery codes and
+// returns a new set. Wrong codes count towards the lockout as they do when
+// logging in.
+func (t *TOTP) RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error) {
+	userAccount, err := t.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user account: %w", err)
+	}
+
+	var codes []string
+	err = t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		err := validateCode(ctx, repos.TOTPCredentials, repos.RecoveryCodes, userAccount.ID, code)
+		if err != nil {
+			return err
+		}
+		err = repos.TOTPCredentials.ResetFailedAttempts(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error resetting failed totp attempts: %w", err)
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, repos.RecoveryCodes, userAccount.ID)
+		return err
+	})
+	if err != nil {
+		return nil, t.recordFailedAttempt(ctx, userAccount.ID, err)
+	}
+	return codes, nil
+}
+
+// ValidateCode checks a second factor for the user, accepting either a code
+// from the authenticator app or an unused recovery code. Each code can only
+// be used once. After MaxTOTPAttempts wrong codes in a row, every code is
+// refused with ErrTOTPLocked for TOTPLockoutDuration.
+func (t *TOTP) ValidateCode(ctx context.Context, username, code string) error {
+	userAccount, err := t.UserAccounts.GetByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("error getting user account: %w", err)
+	}
+
+	err = validateCode(ctx, t.TOTPCredentials, t.RecoveryCodes, userAccount.ID, code)
+	if err != nil {
+		return t.recordFailedAttempt(ctx, userAccount.ID, err)
+	}
+	err = t.TOTPCredentials.ResetFailedAttempts(ctx, userAccount.ID)
+	if err != nil {
+		return fmt.Errorf("error resetting failed totp attempts: %w", err)
+	}
+	return nil
+}
+
+// recordFailedAttempt counts a wrong code towards the lockout, given the
+// error from checking it, which is returned. It is recorded outside of any
+// unit of work checking the code, since that is rolled back when the code is
+// wrong.
+func (t *TOTP) recordFailedAttempt(ctx context.Context, userAccountID string, err error) error {
+	if err != constants.ErrInvalidTOTPCode {
+		return err
+	}
+
+	lockedUntil := time.Now().Add(constants.TOTPLockoutDuration)
+	recordErr := t.TOTPCredentials.RecordFailedAttempt(ctx, userAccountID, constants.MaxTOTPAttempts, lockedUntil)
+	if recordErr != nil {
+		return fmt.Errorf("error recording failed totp attempt: %w", recordErr)
+	}
+	return err
+}
+
+func validateCode(ctx context.Context, rTOTPCredentials repositories.ITOTPCredentials, rRecoveryCodes repositories.IRecoveryCodes, userAccountID, code string) error {
+	exists, err := rTOTPCredentials.ExistsByUserAccountID(ctx, userAccountID)
+	if err != nil {
+		return fmt.Errorf("error checking if totp credential exists: %w", err)
+	}
+	if !exists {
+		return constants.ErrTOTPNotEnabled
+	}
+	totpCredential, err := rTOTPCredentials.GetByUserAccountID(ctx, userAccountID)
+	if err != nil {
+		return fmt.Errorf("error getting totp credential: %w", err)
+	}
+	if !totpCredential.Enabled {
+		return constants.ErrTOTPNotEnabled
+	}
+	if totpCredential.LockedUntil != nil && time.Now().Before(*totpCredential.LockedUntil) {
+		return constants.ErrTOTPLocked
+	}
+
+	step, isValid, err := totp.Validate(totpCredential.Secret, code, time.Now(), constants.TOTPSkew)
+	if err != nil {
+		return fmt.Errorf("error validating totp code: %w", err)
+	}
+	if isValid {
+		// refuse to accept the same code, or an older one, twice
+		used, err := rTOTPCredentials.UseStep(ctx, userAccountID, step)
+		if err != nil {
+			return fmt.Errorf("error using totp code: %w", err)
+		}
+		if !used {
+			return constants.ErrInvalidTOTPCode
+		}
+		return nil
+	}
+
+	codeHash := hashOpaqueToken(normalizeRecoveryCode(code))
+	exists, err = rRecoveryCodes.ExistsByUserAccountIDAndCodeHash(ctx, userAccountID, codeHash)
+	if err != nil {
+		return fmt.Errorf("error checking if recovery code exists: %w", err)
+	}
+	if !exists {
+		return constants.ErrInvalidTOTPCode
+	}
+	recoveryCode, err := rRecoveryCodes.GetByUserAccountIDAndCodeHash(ctx, userAccountID, codeHash)
+	if err != nil {
+		return fmt.Errorf("error getting recovery code: %w", err)
+	}
+	used, err := rRecoveryCodes.Use(ctx, recoveryCode.ID, time.Now())
+	if err != nil {
+		return fmt.Errorf("error using recovery code: %w", err)
+	}
+	if !used {
+		return constants.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, rRecoveryCodes repositories.IRecoveryCodes, userAccountID string) ([]string, error) {
+	err := rRecoveryCodes.DeleteAllByUserAccountID(ctx, userAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	codes := []string{}
+	for i := 0; i < constants.RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("error generating recovery code: %w", err)
+		}
+		err = rRecoveryCodes.Create(ctx, &models.RecoveryCode{
+			ID:            uuid.NewString(),
+			UserAccountID: userAccountID,
+			CodeHash:      hashOpaqueToken(normalizeRecoveryCode(code)),
+			UsedAt:        nil,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted for humans, such as
+// "abcd-efgh-ijkl-mnop"
+func generateRecoveryCode() (string, error) {
+	codeBytes := make([]byte, 10)
+	_, err := rand.Read(codeBytes)
+	if err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(base32.StdEncoding.EncodeToString(codeBytes))
+
+	groups := []string{}
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
@@ -0,0 +1,265 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/paulwrubel/moneybags-server/totp"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPValidateCode(t *testing.T) {
+	currentCode, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name          string
+		code          string
+		mockSetupFunc func(mtc *mockrepositories.MockITOTPCredentials, mrc *mockrepositories.MockIRecoveryCodes)
+		expectedErr   error
+	}{
+		{
+			name: "success - current code",
+			code: currentCode,
+			mockSetupFunc: func(mtc *mockrepositories.MockITOTPCredentials, mrc *mockrepositories.MockIRecoveryCodes) {
+				mtc.EXPECT().
+					UseStep(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				mtc.EXPECT().
+					ResetFailedAttempts(gomock.Any(), gomock.Eq("__uaid_1__")).
+					Times(1).
+					Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "failure - code already used",
+			code: currentCode,
+			mockSetupFunc: func(mtc *mockrepositories.MockITOTPCredentials, mrc *mockrepositories.MockIRecoveryCodes) {
+				mtc.EXPECT().
+					UseStep(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				mtc.EXPECT().
+					RecordFailedAttempt(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq(constants.MaxTOTPAttempts), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			expectedErr: constants.ErrInvalidTOTPCode,
+		},
+		{
+			name: "success - recovery code",
+			code: "abcd-efgh-ijkl-mnop",
+			mockSetupFunc: func(mtc *mockrepositories.MockITOTPCredentials, mrc *mockrepositories.MockIRecoveryCodes) {
+				mrc.EXPECT().
+					ExistsByUserAccountIDAndCodeHash(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				mrc.EXPECT().
+					GetByUserAccountIDAndCodeHash(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(&models.RecoveryCode{ID: "__rcid_1__", UserAccountID: "__uaid_1__"}, nil)
+				mrc.EXPECT().
+					Use(gomock.Any(), gomock.Eq("__rcid_1__"), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				mtc.EXPECT().
+					ResetFailedAttempts(gomock.Any(), gomock.Eq("__uaid_1__")).
+					Times(1).
+					Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "failure - recovery code already used",
+			code: "abcd-efgh-ijkl-mnop",
+			mockSetupFunc: func(mtc *mockrepositories.MockITOTPCredentials, mrc *mockrepositories.MockIRecoveryCodes) {
+				mrc.EXPECT().
+					ExistsByUserAccountIDAndCodeHash(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				mrc.EXPECT().
+					GetByUserAccountIDAndCodeHash(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(&models.RecoveryCode{ID: "__rcid_1__", UserAccountID: "__uaid_1__"}, nil)
+				mrc.EXPECT().
+					Use(gomock.Any(), gomock.Eq("__rcid_1__"), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				mtc.EXPECT().
+					RecordFailedAttempt(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq(constants.MaxTOTPAttempts), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			expectedErr: constants.ErrInvalidTOTPCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+			mockTOTPCredentialsRepository := mockrepositories.NewMockITOTPCredentials(mockCtrl)
+			mockRecoveryCodesRepository := mockrepositories.NewMockIRecoveryCodes(mockCtrl)
+
+			mockUserAccountsRepository.EXPECT().
+				GetByUsername(gomock.Any(), gomock.Eq("user_1")).
+				Times(1).
+				Return(&models.UserAccount{ID: "__uaid_1__", Username: "user_1"}, nil)
+			expectEnabledTOTPCredential(mockTOTPCredentialsRepository, nil)
+			tt.mockSetupFunc(mockTOTPCredentialsRepository, mockRecoveryCodesRepository)
+
+			s := &services.TOTP{
+				UserAccounts:    mockUserAccountsRepository,
+				TOTPCredentials: mockTOTPCredentialsRepository,
+				RecoveryCodes:   mockRecoveryCodesRepository,
+			}
+
+			err := s.ValidateCode(context.Background(), "user_1", tt.code)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+// TestTOTPLockoutOutsideLogin checks that disabling two-factor
+// authentication and regenerating recovery codes count wrong codes towards
+// the lockout, and refuse every code once it is locked, as logging in does
+func TestTOTPLockoutOutsideLogin(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if !assert.NoError(t, err) {
+		return
+	}
+	lockedUntil := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name         string
+		lockedUntil  *time.Time
+		expectRecord bool
+		expectedErr  error
+		callFunc     func(s *services.TOTP) error
+	}{
+		{
+			name:         "disable - wrong code",
+			lockedUntil:  nil,
+			expectRecord: true,
+			expectedErr:  constants.ErrInvalidTOTPCode,
+			callFunc: func(s *services.TOTP) error {
+				return s.Disable(context.Background(), "user_1", "password", "00000x")
+			},
+		},
+		{
+			name:         "disable - locked",
+			lockedUntil:  &lockedUntil,
+			expectRecord: false,
+			expectedErr:  constants.ErrTOTPLocked,
+			callFunc: func(s *services.TOTP) error {
+				return s.Disable(context.Background(), "user_1", "password", "00000x")
+			},
+		},
+		{
+			name:         "regenerate recovery codes - wrong code",
+			lockedUntil:  nil,
+			expectRecord: true,
+			expectedErr:  constants.ErrInvalidTOTPCode,
+			callFunc: func(s *services.TOTP) error {
+				_, err := s.RegenerateRecoveryCodes(context.Background(), "user_1", "00000x")
+				return err
+			},
+		},
+		{
+			name:         "regenerate recovery codes - locked",
+			lockedUntil:  &lockedUntil,
+			expectRecord: false,
+			expectedErr:  constants.ErrTOTPLocked,
+			callFunc: func(s *services.TOTP) error {
+				_, err := s.RegenerateRecoveryCodes(context.Background(), "user_1", "00000x")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUnitOfWork := mockrepositories.NewMockIUnitOfWork(mockCtrl)
+			mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+			mockTOTPCredentialsRepository := mockrepositories.NewMockITOTPCredentials(mockCtrl)
+			mockRecoveryCodesRepository := mockrepositories.NewMockIRecoveryCodes(mockCtrl)
+
+			mockUnitOfWork.EXPECT().
+				Do(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, fn func(repos *repositories.Repositories) error) error {
+					return fn(&repositories.Repositories{
+						UnitOfWork:      mockUnitOfWork,
+						TOTPCredentials: mockTOTPCredentialsRepository,
+						RecoveryCodes:   mockRecoveryCodesRepository,
+					})
+				})
+			mockUserAccountsRepository.EXPECT().
+				GetByUsername(gomock.Any(), gomock.Eq("user_1")).
+				Times(1).
+				Return(&models.UserAccount{ID: "__uaid_1__", Username: "user_1", PasswordHash: string(passwordHash)}, nil)
+			expectEnabledTOTPCredential(mockTOTPCredentialsRepository, tt.lockedUntil)
+			if tt.lockedUntil == nil {
+				mockRecoveryCodesRepository.EXPECT().
+					ExistsByUserAccountIDAndCodeHash(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+			}
+			recordTimes := 0
+			if tt.expectRecord {
+				recordTimes = 1
+			}
+			mockTOTPCredentialsRepository.EXPECT().
+				RecordFailedAttempt(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq(constants.MaxTOTPAttempts), gomock.Any()).
+				Times(recordTimes).
+				Return(nil)
+			mockTOTPCredentialsRepository.EXPECT().
+				DeleteByUserAccountID(gomock.Any(), gomock.Any()).
+				Times(0)
+			mockRecoveryCodesRepository.EXPECT().
+				DeleteAllByUserAccountID(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			s := &services.TOTP{
+				UnitOfWork:      mockUnitOfWork,
+				UserAccounts:    mockUserAccountsRepository,
+				TOTPCredentials: mockTOTPCredentialsRepository,
+				RecoveryCodes:   mockRecoveryCodesRepository,
+			}
+
+			err := tt.callFunc(s)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func expectEnabledTOTPCredential(mtc *mockrepositories.MockITOTPCredentials, lockedUntil *time.Time) {
+	mtc.EXPECT().
+		ExistsByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__")).
+		Times(1).
+		Return(true, nil)
+	mtc.EXPECT().
+		GetByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__")).
+		Times(1).
+		Return(&models.TOTPCredential{
+			UserAccountID: "__uaid_1__",
+			Secret:        testTOTPSecret,
+			Enabled:       true,
+			LockedUntil:   lockedUntil,
+		}, nil)
+}
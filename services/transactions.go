@@ -0,0 +1,354 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/pagination"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type ITransactions interface {
+	BelongsTo(ctx context.Context, bankAccountID, transactionID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetPage(ctx context.Context, bankAccountID string, filter *models.TransactionFilter, page *pagination.Params) ([]*models.Transaction, *pagination.Cursor, error)
+	GetByID(ctx context.Context, id string) (*models.Transaction, error)
+	Create(ctx context.Context, bankAccountID string, categoryID *string, date time.Time, amount int64, payee string, memo *string, cleared models.ClearedState, splits []*models.TransactionSplit) (*models.Transaction, error)
+	Update(ctx context.Context, transaction *models.Transaction, force bool) (*models.Transaction, error)
+	Delete(ctx context.Context, id string, force bool) error
+}
+
+type Transactions struct {
+	UnitOfWork         repositories.IUnitOfWork
+	Repository         repositories.ITransactions
+	RTransactionSplits repositories.ITransactionSplits
+}
+
+func (t *Transactions) BelongsTo(ctx context.Context, bankAccountID, transactionID string) (bool, error) {
+	transaction, err := t.Repository.GetByID(ctx, transactionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get transaction by id: %v", err)
+	}
+	return bankAccountID == transaction.BankAccountID, nil
+}
+
+func (t *Transactions) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return t.Repository.ExistsByID(ctx, id)
+}
+
+// GetPage gets a page of the transactions in the bank account matching the
+// filter, along with their splits
+func (t *Transactions) GetPage(ctx context.Context, bankAccountID string, filter *models.TransactionFilter, page *pagination.Params) ([]*models.Transaction, *pagination.Cursor, error) {
+	if filter.Cleared != nil && !clearedStateIsValid(*filter.Cleared) {
+		return nil, nil, constants.ErrInvalidClearedState
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, nil, constants.ErrInvalidDateRange
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return nil, nil, constants.ErrInvalidAmountRange
+	}
+
+	transactions, next, err := t.Repository.GetPageByBankAccountID(ctx, bankAccountID, filter, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactionIDs := []string{}
+	for _, transaction := range transactions {
+		transactionIDs = append(transactionIDs, transaction.ID)
+	}
+	splits, err := t.RTransactionSplits.GetAllByTransactionIDs(ctx, transactionIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting transaction splits: %w", err)
+	}
+
+	splitsByTransactionID := map[string][]*models.TransactionSplit{}
+	for _, split := range splits {
+		splitsByTransactionID[split.TransactionID] = append(splitsByTransactionID[split.TransactionID], split)
+	}
+	for _, transaction := range transactions {
+		transaction.Splits = splitsByTransactionID[transaction.ID]
+		if transaction.Splits == nil {
+			transaction.Splits = []*models.TransactionSplit{}
+		}
+	}
+
+	return transactions, next, nil
+}
+
+func (t *Transactions) GetByID(ctx context.Context, id string) (*models.Transaction, error) {
+	return getTransaction(ctx, t.Repository, t.RTransactionSplits, id)
+}
+
+// Create adds a new transaction. Transactions may not be created already
+// reconciled; only reconciling the bank account can do that. If splits are
+// given they must sum to amount, and the transaction itself is left
+// uncategorized. The payee is registered with the budget, and a transaction
+// given neither a category nor splits is categorized by the budget's rules.
+func (t *Transactions) Create(ctx context.Context, bankAccountID string, categoryID *string, date time.Time, amount int64, payee string, memo *string, cleared models.ClearedState, splits []*models.TransactionSplit) (*models.Transaction, error) {
+	if !clearedStateIsValid(cleared) || cleared == models.ClearedStateReconciled {
+		return nil, constants.ErrInvalidClearedState
+	}
+	if !splitsAreValid(amount, splits) {
+		return nil, constants.ErrInvalidSplits
+	}
+
+	// the payee is registered in the same unit of work as the transaction,
+	// so a failed or retried insert leaves no payee behind
+	var createdTransaction *models.Transaction
+	err := t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		bankAccount, err := repos.BankAccounts.GetByID(ctx, bankAccountID)
+		if err != nil {
+			return fmt.Errorf("error getting bank account: %w", err)
+		}
+		registeredPayee, ruleCategoryID, err := categorize(ctx, repos, bankAccount.BudgetID, payee, amount)
+		if err != nil {
+			return fmt.Errorf("error categorizing transaction: %w", err)
+		}
+
+		newTransaction := &models.Transaction{
+			ID:            uuid.NewString(),
+			BankAccountID: bankAccountID,
+			CategoryID:    categoryID,
+			Date:          date,
+			Amount:        amount,
+			Payee:         payee,
+			Memo:          memo,
+			Cleared:       cleared,
+		}
+		if categoryID == nil {
+			newTransaction.CategoryID = ruleCategoryID
+		}
+		if registeredPayee != nil {
+			newTransaction.PayeeID = &registeredPayee.ID
+		}
+		if len(splits) > 0 {
+			newTransaction.CategoryID = nil
+		}
+
+		err = repos.Transactions.Create(ctx, newTransaction)
+		if err != nil {
+			return err
+		}
+		exists, err := repos.Transactions.ExistsByID(ctx, newTransaction.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("transaction failed post-creation existence check")
+		}
+		if len(splits) > 0 {
+			err = replaceSplits(ctx, repos.TransactionSplits, newTransaction.ID, splits)
+			if err != nil {
+				return err
+			}
+		}
+		createdTransaction, err = getTransaction(ctx, repos.Transactions, repos.TransactionSplits, newTransaction.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return createdTransaction, nil
+}
+
+// Update saves changes to a transaction. Reconciled transactions are refused
+// with ErrTransactionReconciled unless force is set. Changes to the date,
+// amount or memo of one side of a transfer are mirrored onto the other.
+func (t *Transactions) Update(ctx context.Context, transaction *models.Transaction, force bool) (*models.Transaction, error) {
+	var updatedTransaction *models.Transaction
+	err := t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		existingTransaction, err := repos.Transactions.GetByID(ctx, transaction.ID)
+		if err != nil {
+			return fmt.Errorf("error getting transaction: %w", err)
+		}
+		if existingTransaction.Cleared == models.ClearedStateReconciled && !force {
+			return constants.ErrTransactionReconciled
+		}
+		if !clearedStateIsValid(transaction.Cleared) {
+			return constants.ErrInvalidClearedState
+		}
+		if transaction.Cleared == models.ClearedStateReconciled && existingTransaction.Cleared != models.ClearedStateReconciled {
+			return constants.ErrInvalidClearedState
+		}
+
+		// nil splits leave the existing splits as they are, but they must
+		// still add up if the amount has changed
+		splits := transaction.Splits
+		if splits == nil {
+			splits, err = repos.TransactionSplits.GetAllByTransactionID(ctx, transaction.ID)
+			if err != nil {
+				return fmt.Errorf("error getting transaction splits: %w", err)
+			}
+		}
+		if !splitsAreValid(transaction.Amount, splits) {
+			return constants.ErrInvalidSplits
+		}
+		if len(splits) > 0 {
+			transaction.CategoryID = nil
+		}
+
+		// a new payee name is registered with the budget, except on
+		// transfers
+		transaction.TransferID = existingTransaction.TransferID
+		transaction.PayeeID = existingTransaction.PayeeID
+		if transaction.TransferID == nil && transaction.Payee != existingTransaction.Payee {
+			transaction.PayeeID, err = registerPayee(ctx, repos, transaction.BankAccountID, transaction.Payee)
+			if err != nil {
+				return err
+			}
+		}
+
+		// one side of a transfer keeps the other in step
+		if transaction.TransferID != nil {
+			var reconciled bool
+			reconciled, err = transferIsReconciled(ctx, repos.Transactions, *transaction.TransferID)
+			if err != nil {
+				return err
+			}
+			if reconciled && !force {
+				return constants.ErrTransactionReconciled
+			}
+			err = repos.Transactions.UpdateTransferLeg(ctx, transaction)
+		} else {
+			err = repos.Transactions.Update(ctx, transaction)
+		}
+		if err != nil {
+			return err
+		}
+		if transaction.Splits != nil {
+			err = replaceSplits(ctx, repos.TransactionSplits, transaction.ID, transaction.Splits)
+			if err != nil {
+				return err
+			}
+		}
+		updatedTransaction, err = getTransaction(ctx, repos.Transactions, repos.TransactionSplits, transaction.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedTransaction, nil
+}
+
+// Delete removes a transaction, along with the other side if it is part of a
+// transfer. Reconciled transactions are refused with ErrTransactionReconciled
+// unless force is set.
+func (t *Transactions) Delete(ctx context.Context, id string, force bool) error {
+	return t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		transaction, err := repos.Transactions.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error getting transaction: %w", err)
+		}
+		if transaction.Cleared == models.ClearedStateReconciled && !force {
+			return constants.ErrTransactionReconciled
+		}
+
+		if transaction.TransferID != nil {
+			reconciled, err := transferIsReconciled(ctx, repos.Transactions, *transaction.TransferID)
+			if err != nil {
+				return err
+			}
+			if reconciled && !force {
+				return constants.ErrTransactionReconciled
+			}
+			return repos.Transactions.DeleteByTransferID(ctx, *transaction.TransferID)
+		}
+
+		return repos.Transactions.DeleteByID(ctx, id)
+	})
+}
+
+// registerPayee registers a payee with the budget of the bank account,
+// returning its ID. A blank payee has no ID.
+func registerPayee(ctx context.Context, repos *repositories.Repositories, bankAccountID, name string) (*string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+
+	bankAccount, err := repos.BankAccounts.GetByID(ctx, bankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bank account: %w", err)
+	}
+	payee, err := getOrCreatePayee(ctx, repos.Payees, bankAccount.BudgetID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payee.ID, nil
+}
+
+// getTransaction gets a transaction along with its splits
+func getTransaction(ctx context.Context, rTransactions repositories.ITransactions, rTransactionSplits repositories.ITransactionSplits, id string) (*models.Transaction, error) {
+	transaction, err := rTransactions.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	transaction.Splits, err = rTransactionSplits.GetAllByTransactionID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transaction splits: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// replaceSplits gives each split an ID and saves them as the transaction's
+// splits
+func replaceSplits(ctx context.Context, rTransactionSplits repositories.ITransactionSplits, transactionID string, splits []*models.TransactionSplit) error {
+	for _, split := range splits {
+		split.ID = uuid.NewString()
+		split.TransactionID = transactionID
+	}
+	err := rTransactionSplits.ReplaceAllByTransactionID(ctx, transactionID, splits)
+	if err != nil {
+		return fmt.Errorf("error saving transaction splits: %w", err)
+	}
+	return nil
+}
+
+// transferIsReconciled reports whether either side of the transfer has been
+// reconciled
+func transferIsReconciled(ctx context.Context, rTransactions repositories.ITransactions, transferID string) (bool, error) {
+	transactions, err := rTransactions.GetAllByTransferID(ctx, transferID)
+	if err != nil {
+		return false, fmt.Errorf("error getting transfer transactions: %w", err)
+	}
+	for _, transaction := range transactions {
+		if transaction.Cleared == models.ClearedStateReconciled {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func clearedStateIsValid(cleared models.ClearedState) bool {
+	switch cleared {
+	case models.ClearedStateUncleared, models.ClearedStateCleared, models.ClearedStateReconciled:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitsAreValid reports whether the splits add up to the transaction amount.
+// A transaction without splits is always valid.
+func splitsAreValid(amount int64, splits []*models.TransactionSplit) bool {
+	if len(splits) == 0 {
+		return true
+	}
+
+	var total int64
+	for _, split := range splits {
+		total += split.Amount
+	}
+	return total == amount
+}
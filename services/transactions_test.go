@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTransactionsCreate checks that the payee of a new transaction is
+// registered through the repositories of the unit of work inserting it, so
+// that a failed or retried insert takes the payee with it
+func TestTransactionsCreate(t *testing.T) {
+	defaultCategoryID := "__cid_1__"
+	insertErr := errors.New("could not serialize access")
+
+	tests := []struct {
+		name        string
+		attempts    int
+		createErrs  []error
+		expectedErr error
+	}{
+		{
+			name:        "success",
+			attempts:    1,
+			createErrs:  []error{nil},
+			expectedErr: nil,
+		},
+		{
+			name:        "success - retried",
+			attempts:    2,
+			createErrs:  []error{insertErr, nil},
+			expectedErr: nil,
+		},
+		{
+			name:        "failure - insert failed",
+			attempts:    1,
+			createErrs:  []error{insertErr},
+			expectedErr: insertErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUnitOfWork := mockrepositories.NewMockIUnitOfWork(mockCtrl)
+			mockTransactionsRepository := mockrepositories.NewMockITransactions(mockCtrl)
+			mockTransactionSplitsRepository := mockrepositories.NewMockITransactionSplits(mockCtrl)
+			mockBankAccountsRepository := mockrepositories.NewMockIBankAccounts(mockCtrl)
+			mockPayeesRepository := mockrepositories.NewMockIPayees(mockCtrl)
+			mockCategorizationRulesRepository := mockrepositories.NewMockICategorizationRules(mockCtrl)
+
+			// a serialization failure reruns the whole unit of work, as the
+			// real one does
+			mockUnitOfWork.EXPECT().
+				Do(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, fn func(repos *repositories.Repositories) error) error {
+					repos := &repositories.Repositories{
+						UnitOfWork:          mockUnitOfWork,
+						BankAccounts:        mockBankAccountsRepository,
+						CategorizationRules: mockCategorizationRulesRepository,
+						Payees:              mockPayeesRepository,
+						Transactions:        mockTransactionsRepository,
+						TransactionSplits:   mockTransactionSplitsRepository,
+					}
+					var err error
+					for attempt := 0; attempt < tt.attempts; attempt++ {
+						err = fn(repos)
+					}
+					return err
+				})
+			mockBankAccountsRepository.EXPECT().
+				GetByID(gomock.Any(), gomock.Eq("__baid_1__")).
+				Times(tt.attempts).
+				Return(&models.BankAccount{ID: "__baid_1__", BudgetID: "__bid_1__"}, nil)
+			mockPayeesRepository.EXPECT().
+				GetOrCreateByBudgetIDAndName(gomock.Any(), gomock.Any()).
+				Times(tt.attempts).
+				Return(&models.Payee{ID: "__pid_1__", BudgetID: "__bid_1__", Name: "payee_1", DefaultCategoryID: &defaultCategoryID}, nil)
+			mockCategorizationRulesRepository.EXPECT().
+				GetAllByBudgetID(gomock.Any(), gomock.Eq("__bid_1__")).
+				Times(tt.attempts).
+				Return([]*models.CategorizationRule{}, nil)
+			var created *models.Transaction
+			for _, createErr := range tt.createErrs {
+				createErr := createErr
+				mockTransactionsRepository.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, transaction *models.Transaction) error {
+						created = transaction
+						return createErr
+					})
+			}
+			if tt.expectedErr == nil {
+				mockTransactionsRepository.EXPECT().
+					ExistsByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				mockTransactionsRepository.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, id string) (*models.Transaction, error) {
+						return created, nil
+					})
+				mockTransactionSplitsRepository.EXPECT().
+					GetAllByTransactionID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*models.TransactionSplit{}, nil)
+			}
+
+			s := &services.Transactions{
+				UnitOfWork: mockUnitOfWork,
+			}
+
+			transaction, err := s.Create(context.Background(), "__baid_1__", nil, time.Now(), -1000, "payee_1", nil, models.ClearedStateUncleared, nil)
+
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr == nil {
+				assert.Equal(t, "__pid_1__", *transaction.PayeeID)
+				assert.Equal(t, defaultCategoryID, *transaction.CategoryID)
+			} else {
+				assert.Nil(t, transaction)
+			}
+		})
+	}
+}
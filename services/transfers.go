@@ -0,0 +1,214 @@
+package services
+
+//go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+)
+
+type ITransfers interface {
+	BelongsTo(ctx context.Context, budgetID, transferID string) (bool, error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.Transfer, error)
+	Create(ctx context.Context, userAccountID, budgetID, fromBankAccountID, toBankAccountID string, date time.Time, amount int64, memo *string) (*models.Transfer, error)
+	Update(ctx context.Context, id string, date time.Time, amount int64, memo *string, force bool) (*models.Transfer, error)
+	Delete(ctx context.Context, id string, force bool) error
+}
+
+type Transfers struct {
+	UnitOfWork    repositories.IUnitOfWork
+	RTransactions repositories.ITransactions
+	SBankAccounts IBankAccounts
+	SBudgets      IBudgets
+}
+
+func (t *Transfers) BelongsTo(ctx context.Context, budgetID, transferID string) (bool, error) {
+	transfer, err := t.GetByID(ctx, transferID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get transfer by id: %v", err)
+	}
+	return t.SBankAccounts.BelongsTo(ctx, budgetID, transfer.Outflow.BankAccountID)
+}
+
+func (t *Transfers) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return t.RTransactions.ExistsByTransferID(ctx, id)
+}
+
+func (t *Transfers) GetByID(ctx context.Context, id string) (*models.Transfer, error) {
+	return getTransfer(ctx, t.RTransactions, id)
+}
+
+func getTransfer(ctx context.Context, rTransactions repositories.ITransactions, id string) (*models.Transfer, error) {
+	transactions, err := rTransactions.GetAllByTransferID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) != 2 {
+		return nil, fmt.Errorf("transfer %s has %d transactions", id, len(transactions))
+	}
+
+	return &models.Transfer{
+		ID:      id,
+		Outflow: transactions[0],
+		Inflow:  transactions[1],
+	}, nil
+}
+
+// Create moves amount, which must be positive, from one bank account to
+// another. Both accounts must be in the given budget, which the user must be
+// able to edit, or ErrInvalidTransferBankAccounts is returned. Both accounts
+// must also hold the same currency, or ErrInvalidTransferCurrencies is
+// returned.
+func (t *Transfers) Create(ctx context.Context, userAccountID, budgetID, fromBankAccountID, toBankAccountID string, date time.Time, amount int64, memo *string) (*models.Transfer, error) {
+	if amount <= 0 {
+		return nil, constants.ErrInvalidTransferAmount
+	}
+	if fromBankAccountID == toBankAccountID {
+		return nil, constants.ErrInvalidTransferBankAccounts
+	}
+
+	fromBankAccount, err := t.getBankAccountInBudget(ctx, budgetID, fromBankAccountID)
+	if err != nil {
+		return nil, err
+	}
+	toBankAccount, err := t.getBankAccountInBudget(ctx, budgetID, toBankAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if fromBankAccount.BudgetID != toBankAccount.BudgetID {
+		return nil, constants.ErrInvalidTransferBankAccounts
+	}
+	if fromBankAccount.Currency != toBankAccount.Currency {
+		return nil, constants.ErrInvalidTransferCurrencies
+	}
+	err = t.SBudgets.Authorize(ctx, userAccountID, fromBankAccount.BudgetID, models.BudgetRoleEditor)
+	switch err {
+	case constants.ErrNotBudgetMember, constants.ErrInsufficientBudgetRole:
+		return nil, constants.ErrInvalidTransferBankAccounts
+	case nil:
+		// noop, continue past switch
+	default:
+		return nil, fmt.Errorf("error authorizing budget: %w", err)
+	}
+
+	transferID := uuid.NewString()
+	outflow := &models.Transaction{
+		ID:            uuid.NewString(),
+		BankAccountID: fromBankAccount.ID,
+		Date:          date,
+		Amount:        -amount,
+		Payee:         fmt.Sprintf("Transfer to %s", toBankAccount.Name),
+		Memo:          memo,
+		Cleared:       models.ClearedStateUncleared,
+		TransferID:    &transferID,
+	}
+	inflow := &models.Transaction{
+		ID:            uuid.NewString(),
+		BankAccountID: toBankAccount.ID,
+		Date:          date,
+		Amount:        amount,
+		Payee:         fmt.Sprintf("Transfer from %s", fromBankAccount.Name),
+		Memo:          memo,
+		Cleared:       models.ClearedStateUncleared,
+		TransferID:    &transferID,
+	}
+	var transfer *models.Transfer
+	err = t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		err := repos.Transactions.CreateTransfer(ctx, outflow, inflow)
+		if err != nil {
+			return err
+		}
+		exists, err := repos.Transactions.ExistsByTransferID(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("transfer failed post-creation existence check")
+		}
+		transfer, err = getTransfer(ctx, repos.Transactions, transferID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// Update changes the date, amount and memo of both sides of the transfer.
+// Transfers with a reconciled side are refused with ErrTransactionReconciled
+// unless force is set.
+func (t *Transfers) Update(ctx context.Context, id string, date time.Time, amount int64, memo *string, force bool) (*models.Transfer, error) {
+	if amount <= 0 {
+		return nil, constants.ErrInvalidTransferAmount
+	}
+
+	var transfer *models.Transfer
+	err := t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		original, err := getTransfer(ctx, repos.Transactions, id)
+		if err != nil {
+			return fmt.Errorf("error getting transfer: %w", err)
+		}
+		if transferHasReconciledSide(original) && !force {
+			return constants.ErrTransactionReconciled
+		}
+
+		err = repos.Transactions.UpdateTransfer(ctx, id, date, amount, memo)
+		if err != nil {
+			return err
+		}
+		transfer, err = getTransfer(ctx, repos.Transactions, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// Delete removes both sides of the transfer. As with Update, reconciled
+// transfers need force.
+func (t *Transfers) Delete(ctx context.Context, id string, force bool) error {
+	return t.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		transfer, err := getTransfer(ctx, repos.Transactions, id)
+		if err != nil {
+			return fmt.Errorf("error getting transfer: %w", err)
+		}
+		if transferHasReconciledSide(transfer) && !force {
+			return constants.ErrTransactionReconciled
+		}
+
+		return repos.Transactions.DeleteByTransferID(ctx, id)
+	})
+}
+
+func (t *Transfers) getBankAccountInBudget(ctx context.Context, budgetID, bankAccountID string) (*models.BankAccount, error) {
+	exists, err := t.SBankAccounts.ExistsByID(ctx, bankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking if bank account exists: %w", err)
+	}
+	if !exists {
+		return nil, constants.ErrInvalidTransferBankAccounts
+	}
+	bankAccount, err := t.SBankAccounts.GetByID(ctx, bankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bank account: %w", err)
+	}
+	if bankAccount.BudgetID != budgetID {
+		return nil, constants.ErrInvalidTransferBankAccounts
+	}
+
+	return bankAccount, nil
+}
+
+func transferHasReconciledSide(transfer *models.Transfer) bool {
+	return transfer.Outflow.Cleared == models.ClearedStateReconciled ||
+		transfer.Inflow.Cleared == models.ClearedStateReconciled
+}
@@ -3,47 +3,56 @@ package services
 //go:generate mockgen -source=$GOFILE -destination=../mocks/services/mock_$GOFILE -package=mockservices
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/mail"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/paulwrubel/moneybags-server/constants"
+	"github.com/paulwrubel/moneybags-server/mailer"
 	"github.com/paulwrubel/moneybags-server/models"
 	"github.com/paulwrubel/moneybags-server/repositories"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type IUserAccounts interface {
-	GetInfo(username string) (*models.UserAccount, error)
-	ExistsByUsername(username string) (bool, error)
-	Create(username, password string, email *string) (*models.UserAccount, error)
-	Delete(username string) error
+	GetInfo(ctx context.Context, username string) (*models.UserAccount, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	Create(ctx context.Context, username, password string, email *string) (*models.UserAccount, error)
+	Delete(ctx context.Context, username string) error
+	ChangePassword(ctx context.Context, username, sessionID, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
 }
 
 type UserAccounts struct {
-	Repository repositories.IUserAccounts
+	UnitOfWork          repositories.IUnitOfWork
+	Repository          repositories.IUserAccounts
+	PasswordResetTokens repositories.IPasswordResetTokens
+	Mailer              mailer.IMailer
 }
 
-func (ua *UserAccounts) GetInfo(username string) (*models.UserAccount, error) {
-	userAccount, err := ua.Repository.GetByUsername(username)
+func (ua *UserAccounts) GetInfo(ctx context.Context, username string) (*models.UserAccount, error) {
+	userAccount, err := ua.Repository.GetByUsername(ctx, username)
 	if err != nil {
 		return nil, fmt.Errorf("error getting user account: %w", err)
 	}
 	return userAccount, nil
 }
 
-func (ua *UserAccounts) ExistsByUsername(username string) (bool, error) {
-	exists, err := ua.Repository.ExistsByUsername(username)
+func (ua *UserAccounts) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	exists, err := ua.Repository.ExistsByUsername(ctx, username)
 	if err != nil {
 		return false, fmt.Errorf("error checking if user account exists: %w", err)
 	}
 	return exists, nil
 }
 
-func (ua *UserAccounts) Create(username, password string, email *string) (*models.UserAccount, error) {
+func (ua *UserAccounts) Create(ctx context.Context, username, password string, email *string) (*models.UserAccount, error) {
 	// check username exists
-	exists, err := ua.Repository.ExistsByUsername(username)
+	exists, err := ua.Repository.ExistsByUsername(ctx, username)
 	if err != nil {
 		return nil, fmt.Errorf("error checking if user exists: %w", err)
 	}
@@ -52,7 +61,7 @@ func (ua *UserAccounts) Create(username, password string, email *string) (*model
 	}
 
 	// check password requirement and hash
-	if len(password) < 12 {
+	if !passwordMeetsRequirements(password) {
 		return nil, constants.ErrInvalidPassword
 	}
 	passwordHash, err := getPasswordHash(password)
@@ -76,27 +85,247 @@ func (ua *UserAccounts) Create(username, password string, email *string) (*model
 		PasswordHash: passwordHash,
 		Email:        emailVal,
 	}
-	err = ua.Repository.Create(newUserAccount)
+	var createdUserAccount *models.UserAccount
+	err = ua.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		// checked again, as the username may have been taken while hashing
+		exists, err := repos.UserAccounts.ExistsByUsername(ctx, username)
+		if err != nil {
+			return fmt.Errorf("error checking if user exists: %w", err)
+		}
+		if exists {
+			return constants.ErrUserExists
+		}
+		err = repos.UserAccounts.Create(ctx, newUserAccount)
+		if err != nil {
+			return fmt.Errorf("error creating user account: %w", err)
+		}
+		exists, err = repos.UserAccounts.ExistsByID(ctx, newUserAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error validating if user exists: %w", err)
+		}
+		if !exists {
+			return errors.New("user account failed post creation existence check")
+		}
+		createdUserAccount, err = repos.UserAccounts.GetByID(ctx, newUserAccount.ID)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error creating user account: %w", err)
+		return nil, err
 	}
-	exists, err = ua.Repository.ExistsByID(newUserAccount.ID)
+	return createdUserAccount, nil
+}
+
+// Delete removes a user account. Budgets only the user is a member of are
+// deleted with it, while the user leaves shared budgets, which other members
+// keep using. Where the user is the last owner of a shared budget, the
+// highest ranked of the other members is made an owner in their place.
+func (ua *UserAccounts) Delete(ctx context.Context, username string) error {
+	return ua.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		userAccount, err := repos.UserAccounts.GetByUsername(ctx, username)
+		if err != nil {
+			return fmt.Errorf("error getting user account: %w", err)
+		}
+
+		memberships, err := repos.BudgetMemberships.GetAllByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error getting budget memberships: %w", err)
+		}
+		for _, membership := range memberships {
+			err = leaveBudget(ctx, repos, membership)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = repos.Budgets.HandOverByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error handing over budgets: %w", err)
+		}
+		err = repos.Budgets.DeleteAllByUserAccountID(ctx, userAccount.ID)
+		if err != nil {
+			return fmt.Errorf("error deleting budgets: %w", err)
+		}
+		return repos.UserAccounts.DeleteByID(ctx, userAccount.ID)
+	})
+}
+
+// leaveBudget removes a membership of a user who is being deleted. The budget
+// is deleted if nobody else is a member of it, and otherwise, if the user was
+// its last owner, another member is promoted to owner.
+func leaveBudget(ctx context.Context, repos *repositories.Repositories, membership *models.BudgetMembership) error {
+	members, err := repos.BudgetMemberships.GetAllByBudgetID(ctx, membership.BudgetID)
 	if err != nil {
-		return nil, fmt.Errorf("error validating if user exists: %w", err)
+		return fmt.Errorf("error getting budget memberships: %w", err)
 	}
-	if !exists {
-		return nil, errors.New("user account failed post creation existence check")
+
+	var successor *models.BudgetMembership
+	hasOtherOwner := false
+	for _, member := range members {
+		if member.UserAccountID == membership.UserAccountID {
+			continue
+		}
+		if member.Role == models.BudgetRoleOwner {
+			hasOtherOwner = true
+		}
+		if successor == nil || budgetRoleRanks[member.Role] > budgetRoleRanks[successor.Role] {
+			successor = member
+		}
+	}
+
+	if successor == nil {
+		err = repos.Budgets.DeleteByID(ctx, membership.BudgetID)
+		if err != nil {
+			return fmt.Errorf("error deleting budget: %w", err)
+		}
+		return nil
+	}
+
+	if membership.Role == models.BudgetRoleOwner && !hasOtherOwner {
+		successor.Role = models.BudgetRoleOwner
+		err = repos.BudgetMemberships.Update(ctx, successor)
+		if err != nil {
+			return fmt.Errorf("error promoting budget member: %w", err)
+		}
+	}
+	err = repos.BudgetMemberships.DeleteByBudgetIDAndUserAccountID(ctx, membership.BudgetID, membership.UserAccountID)
+	if err != nil {
+		return fmt.Errorf("error leaving budget: %w", err)
+	}
+	return nil
+}
+
+// ChangePassword replaces the user's password, and signs out every session
+// other than sessionID, the one making the change
+func (ua *UserAccounts) ChangePassword(ctx context.Context, username, sessionID, currentPassword, newPassword string) error {
+	userAccount, err := ua.Repository.GetByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("error getting user account: %w", err)
+	}
+
+	isValid, err := passwordIsValid(currentPassword, userAccount.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("error checking password validity: %w", err)
+	}
+	if !isValid {
+		return constants.ErrIncorrectPassword
+	}
+
+	if !passwordMeetsRequirements(newPassword) {
+		return constants.ErrInvalidPassword
+	}
+	passwordHash, err := getPasswordHash(newPassword)
+	if err != nil {
+		return err
 	}
-	return ua.Repository.GetByID(newUserAccount.ID)
 
+	userAccount.PasswordHash = passwordHash
+	return ua.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		err := repos.UserAccounts.Update(ctx, userAccount)
+		if err != nil {
+			return fmt.Errorf("error updating user account: %w", err)
+		}
+
+		return repos.Sessions.RevokeAllOthersByUserAccountID(ctx, userAccount.ID, sessionID, time.Now())
+	})
 }
 
-func (ua *UserAccounts) Delete(username string) error {
-	userAccount, err := ua.Repository.GetByUsername(username)
+// RequestPasswordReset mails a single-use reset token to the user with the
+// given email address. No error is returned when no such user exists, so
+// that callers cannot use this to discover registered addresses.
+func (ua *UserAccounts) RequestPasswordReset(ctx context.Context, email string) error {
+	exists, err := ua.Repository.ExistsByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("error checking if user account exists: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+	userAccount, err := ua.Repository.GetByEmail(ctx, email)
 	if err != nil {
 		return fmt.Errorf("error getting user account: %w", err)
 	}
-	return ua.Repository.DeleteByID(userAccount.ID)
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("error generating password reset token: %w", err)
+	}
+	err = ua.PasswordResetTokens.Create(ctx, &models.PasswordResetToken{
+		ID:            uuid.NewString(),
+		UserAccountID: userAccount.ID,
+		TokenHash:     hashOpaqueToken(token),
+		ExpiresAt:     time.Now().Add(constants.PasswordResetTokenLifetime),
+		UsedAt:        nil,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating password reset token: %w", err)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Someone asked to reset the password for your moneybags account. "+
+		"If that was you, use the following token within %s to choose a new password:\n\n"+
+		"%s\n\n"+
+		"If it wasn't you, you can safely ignore this message.",
+		userAccount.Username,
+		constants.PasswordResetTokenLifetime,
+		token)
+	err = ua.Mailer.Send(email, "Reset your moneybags password", body)
+	if err != nil {
+		return fmt.Errorf("error sending password reset mail: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset.
+// The token is consumed and every existing session of the user is revoked.
+func (ua *UserAccounts) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tokenHash := hashOpaqueToken(token)
+	return ua.UnitOfWork.Do(ctx, func(repos *repositories.Repositories) error {
+		exists, err := repos.PasswordResetTokens.ExistsByTokenHash(ctx, tokenHash)
+		if err != nil {
+			return fmt.Errorf("error checking if password reset token exists: %w", err)
+		}
+		if !exists {
+			return constants.ErrInvalidResetToken
+		}
+		passwordResetToken, err := repos.PasswordResetTokens.GetByTokenHash(ctx, tokenHash)
+		if err != nil {
+			return fmt.Errorf("error getting password reset token: %w", err)
+		}
+		now := time.Now()
+		if passwordResetToken.UsedAt != nil || now.After(passwordResetToken.ExpiresAt) {
+			return constants.ErrInvalidResetToken
+		}
+
+		if !passwordMeetsRequirements(newPassword) {
+			return constants.ErrInvalidPassword
+		}
+		passwordHash, err := getPasswordHash(newPassword)
+		if err != nil {
+			return err
+		}
+
+		passwordResetToken.UsedAt = &now
+		err = repos.PasswordResetTokens.Update(ctx, passwordResetToken)
+		if err != nil {
+			return fmt.Errorf("error updating password reset token: %w", err)
+		}
+
+		userAccount, err := repos.UserAccounts.GetByID(ctx, passwordResetToken.UserAccountID)
+		if err != nil {
+			return fmt.Errorf("error getting user account: %w", err)
+		}
+		userAccount.PasswordHash = passwordHash
+		err = repos.UserAccounts.Update(ctx, userAccount)
+		if err != nil {
+			return fmt.Errorf("error updating user account: %w", err)
+		}
+
+		return repos.Sessions.RevokeAllByUserAccountID(ctx, userAccount.ID, now)
+	})
+}
+
+func passwordMeetsRequirements(password string) bool {
+	return len(password) >= 12
 }
 
 func getPasswordHash(password string) (string, error) {
@@ -0,0 +1,264 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulwrubel/moneybags-server/constants"
+	mockrepositories "github.com/paulwrubel/moneybags-server/mocks/repositories"
+	"github.com/paulwrubel/moneybags-server/models"
+	"github.com/paulwrubel/moneybags-server/repositories"
+	"github.com/paulwrubel/moneybags-server/services"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserAccountsChangePassword(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("old password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		mockSetupFunc   func(muow *mockrepositories.MockIUnitOfWork, mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions)
+		expectedErr     error
+	}{
+		{
+			name:            "success - signs out other sessions",
+			currentPassword: "old password",
+			newPassword:     "a much better password",
+			mockSetupFunc: func(muow *mockrepositories.MockIUnitOfWork, mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions) {
+				muow.EXPECT().
+					Do(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, fn func(repos *repositories.Repositories) error) error {
+						return fn(&repositories.Repositories{
+							UnitOfWork:   muow,
+							UserAccounts: mua,
+							Sessions:     ms,
+						})
+					})
+
+				updateCall := mua.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, userAccount *models.UserAccount) error {
+						assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(userAccount.PasswordHash), []byte("a much better password")))
+						return nil
+					})
+
+				ms.EXPECT().
+					RevokeAllOthersByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__"), gomock.Eq("__sid_1__"), gomock.Any()).
+					After(updateCall).
+					Times(1).
+					Return(nil)
+				ms.EXPECT().
+					RevokeAllByUserAccountID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:            "incorrect current password",
+			currentPassword: "wrong password",
+			newPassword:     "a much better password",
+			mockSetupFunc: func(muow *mockrepositories.MockIUnitOfWork, mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions) {
+				muow.EXPECT().
+					Do(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectedErr: constants.ErrIncorrectPassword,
+		},
+		{
+			name:            "invalid new password",
+			currentPassword: "old password",
+			newPassword:     "short",
+			mockSetupFunc: func(muow *mockrepositories.MockIUnitOfWork, mua *mockrepositories.MockIUserAccounts, ms *mockrepositories.MockISessions) {
+				muow.EXPECT().
+					Do(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			expectedErr: constants.ErrInvalidPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUnitOfWork := mockrepositories.NewMockIUnitOfWork(mockCtrl)
+			mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+			mockSessionsRepository := mockrepositories.NewMockISessions(mockCtrl)
+
+			mockUserAccountsRepository.EXPECT().
+				GetByUsername(gomock.Any(), gomock.Eq("user_1")).
+				Times(1).
+				Return(&models.UserAccount{
+					ID:           "__uaid_1__",
+					Username:     "user_1",
+					PasswordHash: string(passwordHash),
+				}, nil)
+			tt.mockSetupFunc(mockUnitOfWork, mockUserAccountsRepository, mockSessionsRepository)
+
+			ua := &services.UserAccounts{
+				UnitOfWork: mockUnitOfWork,
+				Repository: mockUserAccountsRepository,
+			}
+
+			err := ua.ChangePassword(context.Background(), "user_1", "__sid_1__", tt.currentPassword, tt.newPassword)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestUserAccountsDelete(t *testing.T) {
+	tests := []struct {
+		name          string
+		members       []*models.BudgetMembership
+		mockSetupFunc func(mb *mockrepositories.MockIBudgets, mbm *mockrepositories.MockIBudgetMemberships)
+	}{
+		{
+			name: "only member - budget deleted",
+			members: []*models.BudgetMembership{
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_1__", Role: models.BudgetRoleOwner},
+			},
+			mockSetupFunc: func(mb *mockrepositories.MockIBudgets, mbm *mockrepositories.MockIBudgetMemberships) {
+				mb.EXPECT().
+					DeleteByID(gomock.Any(), gomock.Eq("__bid_1__")).
+					Times(1).
+					Return(nil)
+				mbm.EXPECT().
+					DeleteByBudgetIDAndUserAccountID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name: "shared with another owner - membership removed",
+			members: []*models.BudgetMembership{
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_1__", Role: models.BudgetRoleOwner},
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_2__", Role: models.BudgetRoleOwner},
+			},
+			mockSetupFunc: func(mb *mockrepositories.MockIBudgets, mbm *mockrepositories.MockIBudgetMemberships) {
+				mb.EXPECT().
+					DeleteByID(gomock.Any(), gomock.Any()).
+					Times(0)
+				mbm.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Times(0)
+				mbm.EXPECT().
+					DeleteByBudgetIDAndUserAccountID(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__uaid_1__")).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name: "last owner - editor promoted over viewer",
+			members: []*models.BudgetMembership{
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_2__", Role: models.BudgetRoleViewer},
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_3__", Role: models.BudgetRoleEditor},
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_1__", Role: models.BudgetRoleOwner},
+			},
+			mockSetupFunc: func(mb *mockrepositories.MockIBudgets, mbm *mockrepositories.MockIBudgetMemberships) {
+				mb.EXPECT().
+					DeleteByID(gomock.Any(), gomock.Any()).
+					Times(0)
+				promoteCall := mbm.EXPECT().
+					Update(gomock.Any(), gomock.Eq(&models.BudgetMembership{
+						BudgetID:      "__bid_1__",
+						UserAccountID: "__uaid_3__",
+						Role:          models.BudgetRoleOwner,
+					})).
+					Times(1).
+					Return(nil)
+				mbm.EXPECT().
+					DeleteByBudgetIDAndUserAccountID(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__uaid_1__")).
+					After(promoteCall).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name: "editor of a shared budget - membership removed",
+			members: []*models.BudgetMembership{
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_1__", Role: models.BudgetRoleEditor},
+				{BudgetID: "__bid_1__", UserAccountID: "__uaid_2__", Role: models.BudgetRoleOwner},
+			},
+			mockSetupFunc: func(mb *mockrepositories.MockIBudgets, mbm *mockrepositories.MockIBudgetMemberships) {
+				mb.EXPECT().
+					DeleteByID(gomock.Any(), gomock.Any()).
+					Times(0)
+				mbm.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Times(0)
+				mbm.EXPECT().
+					DeleteByBudgetIDAndUserAccountID(gomock.Any(), gomock.Eq("__bid_1__"), gomock.Eq("__uaid_1__")).
+					Times(1).
+					Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockUnitOfWork := mockrepositories.NewMockIUnitOfWork(mockCtrl)
+			mockUserAccountsRepository := mockrepositories.NewMockIUserAccounts(mockCtrl)
+			mockBudgetsRepository := mockrepositories.NewMockIBudgets(mockCtrl)
+			mockBudgetMembershipsRepository := mockrepositories.NewMockIBudgetMemberships(mockCtrl)
+
+			mockUnitOfWork.EXPECT().
+				Do(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, fn func(repos *repositories.Repositories) error) error {
+					return fn(&repositories.Repositories{
+						UnitOfWork:        mockUnitOfWork,
+						UserAccounts:      mockUserAccountsRepository,
+						Budgets:           mockBudgetsRepository,
+						BudgetMemberships: mockBudgetMembershipsRepository,
+					})
+				})
+			mockUserAccountsRepository.EXPECT().
+				GetByUsername(gomock.Any(), gomock.Eq("user_1")).
+				Times(1).
+				Return(&models.UserAccount{ID: "__uaid_1__", Username: "user_1"}, nil)
+			var ownMembership *models.BudgetMembership
+			for _, member := range tt.members {
+				if member.UserAccountID == "__uaid_1__" {
+					ownMembership = member
+				}
+			}
+			mockBudgetMembershipsRepository.EXPECT().
+				GetAllByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__")).
+				Times(1).
+				Return([]*models.BudgetMembership{ownMembership}, nil)
+			mockBudgetMembershipsRepository.EXPECT().
+				GetAllByBudgetID(gomock.Any(), gomock.Eq("__bid_1__")).
+				Times(1).
+				Return(tt.members, nil)
+			tt.mockSetupFunc(mockBudgetsRepository, mockBudgetMembershipsRepository)
+			mockBudgetsRepository.EXPECT().
+				HandOverByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__")).
+				Times(1).
+				Return(nil)
+			mockBudgetsRepository.EXPECT().
+				DeleteAllByUserAccountID(gomock.Any(), gomock.Eq("__uaid_1__")).
+				Times(1).
+				Return(nil)
+			mockUserAccountsRepository.EXPECT().
+				DeleteByID(gomock.Any(), gomock.Eq("__uaid_1__")).
+				Times(1).
+				Return(nil)
+
+			ua := &services.UserAccounts{
+				UnitOfWork: mockUnitOfWork,
+				Repository: mockUserAccountsRepository,
+			}
+
+			err := ua.Delete(context.Background(), "user_1")
+			assert.NoError(t, err)
+		})
+	}
+}
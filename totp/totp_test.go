@@ -0,0 +1,224 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/paulwrubel/moneybags-server/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA-1 secret from the RFC 6238 test vectors,
+// "12345678901234567890", base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	assert.NoError(t, err)
+	assert.Len(t, key, 20)
+
+	other, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestURI(t *testing.T) {
+	uri := totp.URI("Moneybags", "paul wrubel@example.com", rfcSecret)
+	assert.Equal(t, "otpauth://totp/Moneybags:paul%20wrubel@example.com?algorithm=SHA1&digits=6&issuer=Moneybags&period=30&secret="+rfcSecret, uri)
+
+	parsed, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/Moneybags:paul wrubel@example.com", parsed.Path)
+	assert.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	assert.Equal(t, "Moneybags", parsed.Query().Get("issuer"))
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name         string
+		time         time.Time
+		expectedStep int64
+	}{
+		{
+			name:         "epoch",
+			time:         time.Unix(0, 0),
+			expectedStep: 0,
+		},
+		{
+			name:         "end of the first step",
+			time:         time.Unix(29, 0),
+			expectedStep: 0,
+		},
+		{
+			name:         "start of the second step",
+			time:         time.Unix(30, 0),
+			expectedStep: 1,
+		},
+		{
+			name:         "rfc vector",
+			time:         time.Unix(1111111109, 0),
+			expectedStep: 0x23523EC,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStep, totp.Step(tt.time))
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	// the RFC 6238 SHA-1 vectors are eight digits long. Six digit codes are
+	// their last six digits.
+	tests := []struct {
+		name         string
+		secret       string
+		time         time.Time
+		expectedCode string
+		expectedErr  bool
+	}{
+		{
+			name:         "T=59",
+			secret:       rfcSecret,
+			time:         time.Unix(59, 0),
+			expectedCode: "287082", // 94287082
+		},
+		{
+			name:         "T=1111111109",
+			secret:       rfcSecret,
+			time:         time.Unix(1111111109, 0),
+			expectedCode: "081804", // 07081804
+		},
+		{
+			name:         "T=1111111111",
+			secret:       rfcSecret,
+			time:         time.Unix(1111111111, 0),
+			expectedCode: "050471", // 14050471
+		},
+		{
+			name:         "T=1234567890",
+			secret:       rfcSecret,
+			time:         time.Unix(1234567890, 0),
+			expectedCode: "005924", // 89005924
+		},
+		{
+			name:         "T=2000000000",
+			secret:       rfcSecret,
+			time:         time.Unix(2000000000, 0),
+			expectedCode: "279037", // 69279037
+		},
+		{
+			name:         "lowercase secret",
+			secret:       "gezdgnbvgy3tqojqgezdgnbvgy3tqojq",
+			time:         time.Unix(59, 0),
+			expectedCode: "287082",
+		},
+		{
+			name:        "invalid secret",
+			secret:      "not base32!",
+			time:        time.Unix(59, 0),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totp.Code(tt.secret, totp.Step(tt.time))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := totp.Step(now)
+	codeAt := func(step int64) string {
+		code, err := totp.Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name          string
+		code          string
+		skew          int64
+		expectedStep  int64
+		expectedValid bool
+	}{
+		{
+			name:          "current step",
+			code:          "081804",
+			skew:          1,
+			expectedStep:  current,
+			expectedValid: true,
+		},
+		{
+			name:          "previous step",
+			code:          codeAt(current - 1),
+			skew:          1,
+			expectedStep:  current - 1,
+			expectedValid: true,
+		},
+		{
+			name:          "next step",
+			code:          codeAt(current + 1),
+			skew:          1,
+			expectedStep:  current + 1,
+			expectedValid: true,
+		},
+		{
+			name:          "two steps behind",
+			code:          codeAt(current - 2),
+			skew:          1,
+			expectedValid: false,
+		},
+		{
+			name:          "two steps ahead",
+			code:          codeAt(current + 2),
+			skew:          1,
+			expectedValid: false,
+		},
+		{
+			name:          "previous step without skew",
+			code:          codeAt(current - 1),
+			skew:          0,
+			expectedValid: false,
+		},
+		{
+			name:          "code from another time",
+			code:          "287082",
+			skew:          1,
+			expectedValid: false,
+		},
+		{
+			name:          "wrong length",
+			code:          "07081804",
+			skew:          1,
+			expectedValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, valid, err := totp.Validate(rfcSecret, tt.code, now, tt.skew)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValid, valid)
+			assert.Equal(t, tt.expectedStep, step)
+		})
+	}
+}